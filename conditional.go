@@ -0,0 +1,162 @@
+package pgxspecial
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrNotInScript is returned by the conditional commands \if, \elif, \else
+// and \endif when they are executed on their own rather than as part of a
+// script run by ExecuteScript, ExecuteFile or \i.
+var ErrNotInScript = errors.New("only available in scripts")
+
+// ErrUnterminatedIf is reported for the outermost \if of a script that ends
+// without closing it.
+var ErrUnterminatedIf = errors.New(`reached EOF without finding closing \endif(s)`)
+
+// ifState is the state of an \if block, following psql's conditional stack.
+type ifState int
+
+const (
+	ifTrue      ifState = iota // in the taken branch
+	ifFalse                    // no branch taken yet
+	ifIgnored                  // a branch was taken, or the whole block is inactive
+	ifElseTrue                 // in a taken \else branch
+	ifElseFalse                // in an \else branch that is not taken
+)
+
+// conditionalStack tracks the \if blocks open in one script. Statements are
+// executed only while every enclosing block is in a taken branch, which is
+// the case if the innermost one is.
+type conditionalStack struct {
+	states []ifState
+	ifs    []Statement // the \if statements opening the blocks
+}
+
+// conditionalCommands are the meta-commands handled by the script engine
+// itself, since they must be seen even in skipped branches.
+var conditionalCommands = map[string]bool{
+	`\if`:    true,
+	`\elif`:  true,
+	`\else`:  true,
+	`\endif`: true,
+}
+
+// splitConditional returns the command name and arguments of the
+// meta-command text and whether it is a conditional command.
+func splitConditional(text string) (name, args string, ok bool) {
+	name = strings.Fields(text)[0]
+	args = strings.TrimSpace(strings.TrimPrefix(text, name))
+	return name, args, conditionalCommands[name]
+}
+
+func (c *conditionalStack) active() bool {
+	if len(c.states) == 0 {
+		return true
+	}
+	top := c.states[len(c.states)-1]
+	return top == ifTrue || top == ifElseTrue
+}
+
+// apply executes the conditional command name of stmt. eval evaluates the
+// expression of \if and \elif; it is only called when the result matters.
+// On an error the stack is left as psql leaves it, so a failing \if still
+// opens a block, in which no branch is taken.
+func (c *conditionalStack) apply(name string, stmt Statement, eval func() (bool, error)) error {
+	n := len(c.states)
+	switch name {
+	case `\if`:
+		if !c.active() {
+			c.push(ifIgnored, stmt)
+			return nil
+		}
+		b, err := eval()
+		if err != nil || !b {
+			c.push(ifFalse, stmt)
+			return err
+		}
+		c.push(ifTrue, stmt)
+
+	case `\elif`:
+		if n == 0 {
+			return fmt.Errorf(`\elif: no matching \if`)
+		}
+		switch c.states[n-1] {
+		case ifTrue:
+			c.states[n-1] = ifIgnored
+		case ifFalse:
+			b, err := eval()
+			if err != nil {
+				return err
+			}
+			if b {
+				c.states[n-1] = ifTrue
+			}
+		case ifElseTrue, ifElseFalse:
+			return fmt.Errorf(`\elif: cannot occur after \else`)
+		}
+
+	case `\else`:
+		if n == 0 {
+			return fmt.Errorf(`\else: no matching \if`)
+		}
+		switch c.states[n-1] {
+		case ifTrue, ifIgnored:
+			c.states[n-1] = ifElseFalse
+		case ifFalse:
+			c.states[n-1] = ifElseTrue
+		case ifElseTrue, ifElseFalse:
+			return fmt.Errorf(`\else: cannot occur after \else`)
+		}
+
+	case `\endif`:
+		if n == 0 {
+			return fmt.Errorf(`\endif: no matching \if`)
+		}
+		c.states = c.states[:n-1]
+		c.ifs = c.ifs[:n-1]
+	}
+	return nil
+}
+
+func (c *conditionalStack) push(state ifState, stmt Statement) {
+	c.states = append(c.states, state)
+	c.ifs = append(c.ifs, stmt)
+}
+
+// evalCondition evaluates the expression of \if or \elif: the first argument
+// after interpolation, which must be a psql boolean. A single-quoted argument
+// is unquoted first.
+func evalCondition(session *Session, name, args string) (bool, error) {
+	args = session.interpolate(args, false)
+
+	var value string
+	if rest, quoted := strings.CutPrefix(args, "'"); quoted {
+		var sb strings.Builder
+		for {
+			i := strings.IndexByte(rest, '\'')
+			if i < 0 {
+				return false, fmt.Errorf("%s: unterminated quoted string", name)
+			}
+			sb.WriteString(rest[:i])
+			rest = rest[i+1:]
+			if !strings.HasPrefix(rest, "'") {
+				break
+			}
+			sb.WriteByte('\'')
+			rest = rest[1:]
+		}
+		value = sb.String()
+	} else if fields := strings.Fields(args); len(fields) > 0 {
+		value = fields[0]
+	} else {
+		return false, fmt.Errorf("%s: missing required argument", name)
+	}
+
+	b, ok := ParseBool(value)
+	if !ok {
+		return false, fmt.Errorf(`unrecognized value %q for "%s expression": Boolean expected`, value, name)
+	}
+	return b, nil
+}
@@ -0,0 +1,151 @@
+package pgxspecial_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/balaji01-4d/pgxspecial"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecuteScriptConditionals(t *testing.T) {
+	tests := []struct {
+		name    string
+		script  string
+		queries []string
+	}{
+		{
+			name:    "if true",
+			script:  "\\if true\nselect 1;\n\\else\nselect 2;\n\\endif\nselect 3;",
+			queries: []string{"select 1", "select 3"},
+		},
+		{
+			name:    "if false",
+			script:  "\\if off\nselect 1;\n\\else\nselect 2;\n\\endif",
+			queries: []string{"select 2"},
+		},
+		{
+			name:    "elif chain",
+			script:  "\\if 0\nselect 1;\n\\elif no\nselect 2;\n\\elif yes\nselect 3;\n\\elif on\nselect 4;\n\\else\nselect 5;\n\\endif",
+			queries: []string{"select 3"},
+		},
+		{
+			name: "nested",
+			script: "\\if t\n  \\if f\n    select 1;\n  \\else\n    select 2;\n    \\if 1\n      select 3;\n    \\endif\n  \\endif\n" +
+				"\\else\n  \\if t\n    select 4;\n  \\endif\n\\endif",
+			queries: []string{"select 2", "select 3"},
+		},
+		{
+			name:    "skipped branches are not evaluated",
+			script:  "\\if false\n  \\if bogus\n  \\endif\n\\elif true\nselect 1;\n\\elif bogus\n\\endif",
+			queries: []string{"select 1"},
+		},
+		{
+			name:    "interpolation",
+			script:  "\\set flag on\n\\if :flag\nselect 1;\n\\endif\n\\if :'flag'\nselect 2;\n\\endif\n\\if 'off'\nselect 3;\n\\endif",
+			queries: []string{"select 1", "select 2"},
+		},
+		{
+			name:    "unterminated SQL in a skipped branch",
+			script:  "\\if false\nselect 1\n\\endif\nselect 2;",
+			queries: []string{"select 2"},
+		},
+		{
+			name:    "meta-commands in a skipped branch",
+			script:  "\\set x 1\n\\if false\n\\set x 2\n\\nope\n\\endif\nselect :x;",
+			queries: []string{"select 1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &fakeQueryer{}
+			results, err := pgxspecial.ExecuteScript(context.Background(), db, strings.NewReader(tt.script))
+			require.NoError(t, err)
+			for _, res := range results {
+				assert.NoError(t, res.Err, res.Statement.Text)
+			}
+			assert.Equal(t, tt.queries, db.queries)
+		})
+	}
+}
+
+func TestExecuteScriptConditionalErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		script  string
+		err     string
+		queries []string
+	}{
+		{
+			// like psql, a block whose expression is invalid is false
+			name:    "invalid expression",
+			script:  "\\if maybe\nselect 1;\n\\else\nselect 2;\n\\endif",
+			err:     `<input>:1: unrecognized value "maybe" for "\if expression": Boolean expected`,
+			queries: []string{"select 2"},
+		},
+		{
+			name:    "missing expression",
+			script:  "\\if false\n\\elif\n\\endif",
+			err:     `<input>:2: \elif: missing required argument`,
+			queries: nil,
+		},
+		{
+			name:    "else without if",
+			script:  "select 1;\n\\else\nselect 2;",
+			err:     `<input>:2: \else: no matching \if`,
+			queries: []string{"select 1", "select 2"},
+		},
+		{
+			name:    "endif without if",
+			script:  "\\if true\n\\endif\n\\endif",
+			err:     `<input>:3: \endif: no matching \if`,
+			queries: nil,
+		},
+		{
+			name:    "elif after else",
+			script:  "\\if false\n\\else\nselect 1;\n\\elif true\nselect 2;\n\\endif",
+			err:     `<input>:4: \elif: cannot occur after \else`,
+			queries: []string{"select 1", "select 2"},
+		},
+		{
+			name:    "unterminated if",
+			script:  "\\if true\nselect 1;\n  \\if false\n  \\endif",
+			err:     `<input>:1: reached EOF without finding closing \endif(s)`,
+			queries: []string{"select 1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &fakeQueryer{}
+			results, err := pgxspecial.ExecuteScript(context.Background(), db, strings.NewReader(tt.script))
+			require.NoError(t, err)
+
+			var errs []string
+			for _, res := range results {
+				if res.Err != nil {
+					errs = append(errs, res.Err.Error())
+				}
+			}
+			assert.Equal(t, []string{tt.err}, errs)
+			assert.Equal(t, tt.queries, db.queries)
+		})
+	}
+}
+
+func TestExecuteScriptConditionalOnErrorStop(t *testing.T) {
+	db := &fakeQueryer{}
+	ctx := pgxspecial.WithSession(context.Background(), pgxspecial.NewSession())
+
+	results, err := pgxspecial.ExecuteScript(ctx, db, strings.NewReader("\\set ON_ERROR_STOP on\n\\if true\nselect 1;\n"))
+	assert.ErrorIs(t, err, pgxspecial.ErrUnterminatedIf)
+	require.Len(t, results, 4)
+	assert.Equal(t, `\if true`, results[3].Statement.Text)
+
+	_, err = pgxspecial.ExecuteScript(ctx, db, strings.NewReader("\\if 2\nselect 2;\n\\endif\nselect 3;"))
+	assert.EqualError(t, err, `<input>:1: unrecognized value "2" for "\if expression": Boolean expected`)
+	assert.Equal(t, []string{"select 1"}, db.queries)
+}
@@ -3,8 +3,11 @@ package database
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 // Queryer is an interface that defines methods for querying a database.
@@ -15,3 +18,27 @@ type Queryer interface {
 	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
 	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
 }
+
+// Preparer is implemented by Queryers that can prepare statements, such as
+// pgx.Conn and pgx.Tx. Commands describing a statement without executing
+// it, like \gdesc, need it.
+type Preparer interface {
+	Prepare(ctx context.Context, name, sql string) (*pgconn.StatementDescription, error)
+}
+
+// Prepare prepares sql on db, which must implement Preparer or be a
+// *pgxpool.Pool, whose connection is acquired for the call.
+func Prepare(ctx context.Context, db Queryer, name, sql string) (*pgconn.StatementDescription, error) {
+	switch db := db.(type) {
+	case Preparer:
+		return db.Prepare(ctx, name, sql)
+	case *pgxpool.Pool:
+		conn, err := db.Acquire(ctx)
+		if err != nil {
+			return nil, err
+		}
+		defer conn.Release()
+		return conn.Conn().Prepare(ctx, name, sql)
+	}
+	return nil, fmt.Errorf("%T cannot prepare statements", db)
+}
@@ -0,0 +1,39 @@
+package dbcommands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/balaji01-4d/pgxspecial"
+	"github.com/balaji01-4d/pgxspecial/database"
+)
+
+func init() {
+	conditionals := []struct{ cmd, syntax, description string }{
+		{"\\if", "\\if EXPR", "Begin conditional block."},
+		{"\\elif", "\\elif EXPR", "Alternative within current conditional block."},
+		{"\\else", "\\else", "Final alternative within current conditional block."},
+		{"\\endif", "\\endif", "End conditional block."},
+	}
+	for _, c := range conditionals {
+		pgxspecial.MustRegisterCommand(pgxspecial.SpecialCommandRegistry{
+			Cmd:           c.cmd,
+			Description:   c.description,
+			Group:         pgxspecial.GroupConditional,
+			Syntax:        c.syntax,
+			Handler:       conditionalHandler(c.cmd),
+			CaseSensitive: true,
+			RawArgs:       true,
+		})
+	}
+}
+
+// conditionalHandler returns the handler of a conditional command. The
+// commands are registered so that they are listed by \?, but they are
+// executed by the script engine, which evaluates them even in skipped
+// branches; on their own they only report pgxspecial.ErrNotInScript.
+func conditionalHandler(cmd string) pgxspecial.SpecialHandler {
+	return func(ctx context.Context, db database.Queryer, args string, opts pgxspecial.CommandOptions) (pgxspecial.SpecialCommandResult, error) {
+		return nil, fmt.Errorf("%s: %w", cmd, pgxspecial.ErrNotInScript)
+	}
+}
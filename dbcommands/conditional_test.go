@@ -0,0 +1,42 @@
+package dbcommands_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/balaji01-4d/pgxspecial"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConditionalOutsideScript(t *testing.T) {
+	for _, cmd := range []string{"\\if true", "\\elif false", "\\else", "\\endif"} {
+		_, _, err := pgxspecial.ExecuteSpecialCommand(context.Background(), nil, cmd)
+		assert.ErrorIs(t, err, pgxspecial.ErrNotInScript, cmd)
+	}
+}
+
+func TestConditionalInIncludedScript(t *testing.T) {
+	dir := writeScripts(t, map[string]string{
+		"main.sql": "\\set which main\n\\if :use_lib\n\\ir lib.sql\n\\else\n\\set which skipped\n\\endif",
+		"lib.sql":  "\\if true\n\\set which lib\n",
+	})
+	session := pgxspecial.NewSession()
+	assert.NoError(t, session.Set("use_lib", "yes"))
+	ctx := pgxspecial.WithSession(context.Background(), session)
+
+	res, _, err := pgxspecial.ExecuteSpecialCommand(ctx, nil, "\\i "+dir+"/main.sql")
+	assert.NoError(t, err)
+	which, _ := session.Get("which")
+	assert.Equal(t, "lib", which)
+
+	// blocks do not span scripts: the one left open by lib.sql is reported
+	var errs []error
+	for _, r := range res.(pgxspecial.ScriptResults).Results {
+		if r.Err != nil {
+			errs = append(errs, r.Err)
+		}
+	}
+	if assert.Len(t, errs, 1) {
+		assert.ErrorIs(t, errs[0], pgxspecial.ErrUnterminatedIf)
+	}
+}
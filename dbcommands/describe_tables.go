@@ -3,7 +3,6 @@ package dbcommands
 import (
 	"context"
 	"fmt"
-	"strconv"
 	"strings"
 
 	"github.com/balaji01-4d/pgxspecial"
@@ -11,32 +10,31 @@ import (
 )
 
 func init() {
-	pgxspecial.RegisterCommand(pgxspecial.SpecialCommandRegistry{
+	pgxspecial.MustRegisterCommand(pgxspecial.SpecialCommandRegistry{
 		Cmd:           "\\d",
 		Description:   "List or describe tables, views and sequences.",
-		Syntax:        "\\d[+] [pattern]",
+		Group:         pgxspecial.GroupInformational,
+		Syntax:        "\\d[S+] [pattern]",
 		Handler:       DescribeTableDetails,
 		CaseSensitive: true,
 	})
 
-	pgxspecial.RegisterCommand(pgxspecial.SpecialCommandRegistry{
+	pgxspecial.MustRegisterCommand(pgxspecial.SpecialCommandRegistry{
 		Cmd:           "DESCRIBE",
 		Description:   "List or describe tables, views and sequences.",
+		Group:         pgxspecial.GroupInformational,
 		Syntax:        "DESCRIBE [pattern]",
 		Handler:       DescribeTableDetails,
 		CaseSensitive: false,
 	})
 }
 
-func DescribeTableDetails(ctx context.Context, db database.Queryer, pattern string, verbose bool) (pgxspecial.SpecialCommandResult, error) {
+func DescribeTableDetails(ctx context.Context, db database.Queryer, pattern string, opts pgxspecial.CommandOptions) (pgxspecial.SpecialCommandResult, error) {
 	if pattern == "" {
-		return ListObjects(ctx, db, "", verbose, []string{"r", "p", "v", "m", "S", "f", ""})
+		return ListObjects(ctx, db, "", opts, []string{"r", "p", "v", "m", "S", "f", ""})
 	}
 
-	schema, relname := sqlNamePattern(pattern)
 	var sb strings.Builder
-	args := []any{}
-	argIndex := 1
 
 	sb.WriteString(`
 		SELECT c.oid, n.nspname, c.relname
@@ -45,18 +43,13 @@ func DescribeTableDetails(ctx context.Context, db database.Queryer, pattern stri
 		WHERE 1=1
 	`)
 
-	if schema != "" {
-		sb.WriteString(" AND n.nspname ~ $" + strconv.Itoa(argIndex))
-		args = append(args, schema)
-		argIndex++
-	} else {
-		sb.WriteString(" AND pg_catalog.pg_table_is_visible(c.oid)")
-	}
-
-	if relname != "" {
-		sb.WriteString(" AND c.relname OPERATOR(pg_catalog.~) $" + strconv.Itoa(argIndex))
-		args = append(args, relname)
+	patterns, err := parsePatterns(ctx, db, pattern, 3)
+	if err != nil {
+		return nil, err
 	}
+	filter, args := patternFilter(patterns, "n.nspname ~ %[1]s",
+		"c.relname OPERATOR(pg_catalog.~) %[1]s", "pg_catalog.pg_table_is_visible(c.oid)", nil)
+	sb.WriteString(" AND " + filter)
 
 	sb.WriteString(" ORDER BY 2, 3")
 
@@ -94,7 +87,7 @@ func DescribeTableDetails(ctx context.Context, db database.Queryer, pattern stri
 	}
 
 	for _, t := range targets {
-		res, err := DescribeOneTableDetails(ctx, db, t.schema, t.relname, t.oid, verbose)
+		res, err := DescribeOneTableDetails(ctx, db, t.schema, t.relname, t.oid, opts.Verbose)
 		if err != nil {
 			return nil, err
 		}
@@ -117,27 +110,25 @@ type tableInfo struct {
 	Persistence   string
 	IsPartition   bool
 	RelToastRelId uint32
+	AccessMethod  *string
+
+	// ServerVersion is the server_version_num of the server the table was
+	// read from, selecting the catalog columns the queries use.
+	ServerVersion int
 }
 
 func DescribeOneTableDetails(ctx context.Context, db database.Queryer, schema, name string, oid uint32, verbose bool) (pgxspecial.DescribeTableResult, error) {
-	var ti tableInfo
-	// Assuming PG >= 12
-	sql := `SELECT c.relchecks, c.relkind::text, c.relhasindex,
-		c.relhasrules, c.relhastriggers, false as relhasoids,
-		pg_catalog.array_to_string(c.reloptions || array(select 'toast.' || x from pg_catalog.unnest(tc.reloptions) x), ', '),
-		c.reltablespace::text,
-		CASE WHEN c.reloftype = 0 THEN '' ELSE c.reloftype::pg_catalog.regtype::pg_catalog.text END,
-		c.relpersistence::text,
-		c.relispartition
-		FROM pg_catalog.pg_class c
-		LEFT JOIN pg_catalog.pg_class tc ON (c.reltoastrelid = tc.oid)
-		WHERE c.oid = $1`
+	version, err := pgxspecial.ServerVersion(ctx, db)
+	if err != nil {
+		return pgxspecial.DescribeTableResult{}, err
+	}
 
-	err := db.QueryRow(ctx, sql, oid).Scan(
+	ti := tableInfo{ServerVersion: version}
+	err = db.QueryRow(ctx, tableInfoQuery(version), oid).Scan(
 		&ti.RelChecks, &ti.RelKind, &ti.HasIndex,
 		&ti.HasRules, &ti.HasTriggers, &ti.HasOids,
 		&ti.RelOptions, &ti.Tablespace, &ti.RelOfType,
-		&ti.Persistence, &ti.IsPartition,
+		&ti.Persistence, &ti.IsPartition, &ti.AccessMethod,
 	)
 	if err != nil {
 		return pgxspecial.DescribeTableResult{}, err
@@ -156,13 +147,94 @@ func DescribeOneTableDetails(ctx context.Context, db database.Queryer, schema, n
 	}
 
 	return pgxspecial.DescribeTableResult{
+		Title:         describeTitle(ti, schema, name),
+		RelKind:       ti.RelKind,
 		Columns:       headers,
 		Data:          data,
 		TableMetaData: meta,
 	}, nil
 }
 
-func getTableColumns(ctx context.Context, db database.Queryer, oid uint32, ti tableInfo, verbose bool, schema, name string) ([]string, [][]string, error) {
+// describeTitle returns the caption of the description of a relation, as
+// psql words it for each relkind.
+func describeTitle(ti tableInfo, schema, name string) string {
+	var kind string
+	switch ti.RelKind {
+	case "r":
+		kind = "Table"
+	case "v":
+		kind = "View"
+	case "m":
+		kind = "Materialized view"
+	case "S":
+		kind = "Sequence"
+	case "i":
+		kind = "Index"
+	case "I":
+		kind = "Partitioned index"
+	case "t":
+		kind = "TOAST table"
+	case "c":
+		kind = "Composite type"
+	case "f":
+		kind = "Foreign table"
+	case "p":
+		kind = "Partitioned table"
+	default:
+		kind = "?" + ti.RelKind + "?"
+	}
+	if ti.Persistence == "u" && strings.Contains("rmSiIp", ti.RelKind) {
+		kind = "Unlogged " + strings.ToLower(kind[:1]) + kind[1:]
+	}
+	return fmt.Sprintf(`%s "%s.%s"`, kind, schema, name)
+}
+
+// tableInfoQuery returns the query reading the tableInfo of a relation.
+// Tables lost their OIDs in PG 12, which added table access methods.
+func tableInfoQuery(version int) string {
+	var sb strings.Builder
+	sb.WriteString(`SELECT c.relchecks, c.relkind::text, c.relhasindex,
+		c.relhasrules, c.relhastriggers, `)
+	if version >= 120000 {
+		sb.WriteString(`false as relhasoids,`)
+	} else {
+		sb.WriteString(`c.relhasoids,`)
+	}
+	sb.WriteString(`
+		pg_catalog.array_to_string(c.reloptions || array(select 'toast.' || x from pg_catalog.unnest(tc.reloptions) x), ', '),
+		c.reltablespace::text,
+		CASE WHEN c.reloftype = 0 THEN '' ELSE c.reloftype::pg_catalog.regtype::pg_catalog.text END,
+		c.relpersistence::text,`)
+	if version >= 100000 {
+		sb.WriteString(`
+		c.relispartition,`)
+	} else {
+		sb.WriteString(`
+		false as relispartition,`)
+	}
+	if version >= 120000 {
+		sb.WriteString(`
+		am.amname
+		FROM pg_catalog.pg_class c
+		LEFT JOIN pg_catalog.pg_class tc ON (c.reltoastrelid = tc.oid)
+		LEFT JOIN pg_catalog.pg_am am ON (c.relam = am.oid)`)
+	} else {
+		sb.WriteString(`
+		NULL::pg_catalog.name as amname
+		FROM pg_catalog.pg_class c
+		LEFT JOIN pg_catalog.pg_class tc ON (c.reltoastrelid = tc.oid)`)
+	}
+	sb.WriteString(`
+		WHERE c.oid = $1`)
+	return sb.String()
+}
+
+// tableColumnsQuery returns the query listing the columns of a relation,
+// one row per column in the order getTableColumns scans them. Generated
+// columns are PG 12, INCLUDE columns of indexes PG 11 and column
+// compression PG 14.
+func tableColumnsQuery(oid uint32, ti tableInfo, verbose bool) string {
+	version := ti.ServerVersion
 	var sb strings.Builder
 	sb.WriteString(`SELECT a.attname,
     pg_catalog.format_type(a.atttypid, a.atttypmod),
@@ -173,13 +245,25 @@ func getTableColumns(ctx context.Context, db database.Queryer, oid uint32, ti ta
     (SELECT c.collname FROM pg_catalog.pg_collation c, pg_catalog.pg_type t
                     WHERE c.oid = a.attcollation
                     AND t.oid = a.atttypid AND a.attcollation <> t.typcollation) AS attcollation,
-    a.attidentity::text,
+    a.attidentity::text,`)
+	if version >= 120000 {
+		sb.WriteString(`
     a.attgenerated::text`)
+	} else {
+		sb.WriteString(`
+    ''::text AS attgenerated`)
+	}
 
 	if ti.RelKind == "i" || ti.RelKind == "I" {
-		sb.WriteString(fmt.Sprintf(`
-		, CASE WHEN a.attnum <= (SELECT i.indnkeyatts FROM pg_catalog.pg_index i WHERE i.indexrelid = '%d') THEN 'yes' ELSE 'no' END AS is_key
-		, pg_catalog.pg_get_indexdef(a.attrelid, a.attnum, TRUE) AS indexdef`, oid))
+		if version >= 110000 {
+			sb.WriteString(fmt.Sprintf(`
+		, CASE WHEN a.attnum <= (SELECT i.indnkeyatts FROM pg_catalog.pg_index i WHERE i.indexrelid = '%d') THEN 'yes' ELSE 'no' END AS is_key`, oid))
+		} else {
+			sb.WriteString(`
+		, 'yes' AS is_key`)
+		}
+		sb.WriteString(`
+		, pg_catalog.pg_get_indexdef(a.attrelid, a.attnum, TRUE) AS indexdef`)
 	} else {
 		sb.WriteString(`, NULL AS is_key, NULL AS indexdef`)
 	}
@@ -195,6 +279,11 @@ func getTableColumns(ctx context.Context, db database.Queryer, oid uint32, ti ta
 
 	if verbose {
 		sb.WriteString(`, a.attstorage::text`)
+		if showCompression(ti, verbose) {
+			sb.WriteString(`, CASE a.attcompression WHEN 'p' THEN 'pglz' WHEN 'l' THEN 'lz4' END AS attcompression`)
+		} else {
+			sb.WriteString(`, NULL AS attcompression`)
+		}
 		if ti.RelKind == "r" || ti.RelKind == "i" || ti.RelKind == "I" || ti.RelKind == "m" || ti.RelKind == "f" || ti.RelKind == "p" {
 			sb.WriteString(`, CASE WHEN a.attstattarget=-1 THEN NULL ELSE a.attstattarget END AS attstattarget`)
 		} else {
@@ -206,13 +295,24 @@ func getTableColumns(ctx context.Context, db database.Queryer, oid uint32, ti ta
 			sb.WriteString(`, NULL AS attdescr`)
 		}
 	} else {
-		sb.WriteString(`, NULL AS attstorage, NULL AS attstattarget, NULL AS attdescr`)
+		sb.WriteString(`, NULL AS attstorage, NULL AS attcompression, NULL AS attstattarget, NULL AS attdescr`)
 	}
 
 	sb.WriteString(fmt.Sprintf(` FROM pg_catalog.pg_attribute a WHERE a.attrelid = '%d' AND
     a.attnum > 0 AND NOT a.attisdropped ORDER BY a.attnum;`, oid))
+	return sb.String()
+}
+
+// showCompression reports whether \d+ shows the compression method of the
+// columns, which PG 14 added for the relations storing data.
+func showCompression(ti tableInfo, verbose bool) bool {
+	return verbose && ti.ServerVersion >= 140000 &&
+		(ti.RelKind == "r" || ti.RelKind == "p" || ti.RelKind == "m")
+}
 
-	rows, err := db.Query(ctx, sb.String())
+func getTableColumns(ctx context.Context, db database.Queryer, oid uint32, ti tableInfo, verbose bool, schema, name string) ([]string, [][]string, error) {
+	query := tableColumnsQuery(oid, ti, verbose)
+	rows, err := db.Query(ctx, query)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -249,7 +349,7 @@ func getTableColumns(ctx context.Context, db database.Queryer, oid uint32, ti ta
 		seqRows.Close()
 
 		// Re-open rows query for the main iteration
-		rows, err = db.Query(ctx, sb.String())
+		rows, err = db.Query(ctx, query)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -266,6 +366,9 @@ func getTableColumns(ctx context.Context, db database.Queryer, oid uint32, ti ta
 
 	if verbose {
 		headers = append(headers, "Storage")
+		if showCompression(ti, verbose) {
+			headers = append(headers, "Compression")
+		}
 		if ti.RelKind == "r" || ti.RelKind == "m" || ti.RelKind == "f" {
 			headers = append(headers, "Stats target")
 		}
@@ -283,12 +386,12 @@ func getTableColumns(ctx context.Context, db database.Queryer, oid uint32, ti ta
 		var attcollation *string
 		var attidentity, attgenerated string
 		var isKey, indexdef, attfdwoptions *string
-		var attstorage *string
+		var attstorage, attcompression *string
 		var attstattarget *int32
 		var attdescr *string
 
 		err := rows.Scan(&attname, &atttype, &attrdef, &attnotnull, &attcollation, &attidentity, &attgenerated,
-			&isKey, &indexdef, &attfdwoptions, &attstorage, &attstattarget, &attdescr)
+			&isKey, &indexdef, &attfdwoptions, &attstorage, &attcompression, &attstattarget, &attdescr)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -315,6 +418,11 @@ func getTableColumns(ctx context.Context, db database.Queryer, oid uint32, ti ta
 				if attrdef != nil {
 					modifier += fmt.Sprintf(" generated always as (%s) stored", *attrdef)
 				}
+			} else if attgenerated == "v" {
+				// virtual generated columns are PG 18
+				if attrdef != nil {
+					modifier += fmt.Sprintf(" generated always as (%s)", *attrdef)
+				}
 			}
 			row = append(row, modifier)
 		}
@@ -361,6 +469,14 @@ func getTableColumns(ctx context.Context, db database.Queryer, oid uint32, ti ta
 				row = append(row, "")
 			}
 
+			if showCompression(ti, verbose) {
+				if attcompression != nil {
+					row = append(row, *attcompression)
+				} else {
+					row = append(row, "")
+				}
+			}
+
 			if ti.RelKind == "r" || ti.RelKind == "m" || ti.RelKind == "f" {
 				if attstattarget != nil {
 					row = append(row, fmt.Sprintf("%d", *attstattarget))
@@ -418,6 +534,13 @@ func getTableFooter(ctx context.Context, db database.Queryer, oid uint32, ti tab
 				return meta, err
 			}
 		}
+		// not-null constraints are named catalog entries since PG 18
+		if verbose && ti.ServerVersion >= 180000 && ti.RelKind != "m" {
+			meta.NotNullConstraints, err = getNotNullConstraints(ctx, db, oid)
+			if err != nil {
+				return meta, err
+			}
+		}
 		if ti.HasTriggers {
 			meta.ForeignKeys, err = getForeignKeys(ctx, db, oid)
 			if err != nil {
@@ -435,13 +558,17 @@ func getTableFooter(ctx context.Context, db database.Queryer, oid uint32, ti tab
 			}
 		}
 		if ti.IsPartition {
-			meta.PartitionOf, meta.PartitionConstraints, err = getPartitionInfo(ctx, db, oid)
+			meta.PartitionOf, meta.PartitionConstraints, err = getPartitionInfo(ctx, db, oid, ti.ServerVersion)
 			if err != nil {
 				return meta, err
 			}
+			// psql shows the partition constraint in verbose mode only
+			if !verbose {
+				meta.PartitionConstraints = nil
+			}
 		}
 		if ti.RelKind == "p" {
-			meta.PartitionKey, meta.Partitions, meta.PartitionsSummary, err = getPartitionDetails(ctx, db, oid, verbose)
+			meta.PartitionKey, meta.Partitions, meta.PartitionsSummary, err = getPartitionDetails(ctx, db, oid, verbose, ti.ServerVersion)
 			if err != nil {
 				return meta, err
 			}
@@ -449,7 +576,7 @@ func getTableFooter(ctx context.Context, db database.Queryer, oid uint32, ti tab
 	}
 
 	if ti.HasTriggers {
-		meta.TriggersEnabled, meta.TriggersDisabled, meta.TriggersAlways, meta.TriggersReplica, err = getTriggers(ctx, db, oid)
+		meta.TriggersEnabled, meta.TriggersDisabled, meta.TriggersAlways, meta.TriggersReplica, err = getTriggers(ctx, db, oid, ti.ServerVersion)
 		if err != nil {
 			return meta, err
 		}
@@ -480,6 +607,10 @@ func getTableFooter(ctx context.Context, db database.Queryer, oid uint32, ti tab
 		}
 	}
 
+	if verbose && ti.AccessMethod != nil && (ti.RelKind == "r" || ti.RelKind == "p" || ti.RelKind == "m") {
+		meta.AccessMethod = ti.AccessMethod
+	}
+
 	if verbose && ti.RelOptions != nil && *ti.RelOptions != "" {
 		meta.Options = ti.RelOptions
 	}
@@ -641,6 +772,39 @@ func getCheckConstraints(ctx context.Context, db database.Queryer, oid uint32) (
 	return constraints, nil
 }
 
+func getNotNullConstraints(ctx context.Context, db database.Queryer, oid uint32) ([]string, error) {
+	sql := `SELECT c.conname, a.attname, c.connoinherit, c.conislocal, c.coninhcount <> 0
+		FROM pg_catalog.pg_constraint c
+		JOIN pg_catalog.pg_attribute a ON (a.attrelid = c.conrelid AND a.attnum = c.conkey[1])
+		WHERE c.contype = 'n' AND c.conrelid = $1
+		ORDER BY a.attnum`
+	rows, err := db.Query(ctx, sql, oid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var constraints []string
+	for rows.Next() {
+		var conname, attname string
+		var noInherit, local, inherited bool
+		if err := rows.Scan(&conname, &attname, &noInherit, &local, &inherited); err != nil {
+			return nil, err
+		}
+		entry := fmt.Sprintf(`"%s" NOT NULL "%s"`, conname, attname)
+		switch {
+		case noInherit:
+			entry += " NO INHERIT"
+		case local && inherited:
+			entry += " (local, inherited)"
+		case inherited:
+			entry += " (inherited)"
+		}
+		constraints = append(constraints, entry)
+	}
+	return constraints, rows.Err()
+}
+
 func getForeignKeys(ctx context.Context, db database.Queryer, oid uint32) ([]string, error) {
 	sql := `SELECT conname, pg_catalog.pg_get_constraintdef(r.oid, true)
 		FROM pg_catalog.pg_constraint r
@@ -707,11 +871,42 @@ func getRules(ctx context.Context, db database.Queryer, oid uint32) (enabled, di
 	return
 }
 
-func getTriggers(ctx context.Context, db database.Queryer, oid uint32) (enabled, disabled, always, replica []string, err error) {
-	sql := `SELECT t.tgname, pg_catalog.pg_get_triggerdef(t.oid, true), t.tgenabled::text
+// triggersQuery returns the query listing the triggers of a table with
+// the table they were cloned from, if any. Internal triggers are hidden
+// unless they are disabled, except the triggers PG 11 to 14 clone onto
+// partitions, which are internal there. PG 13 records the parent trigger.
+func triggersQuery(version int) string {
+	var sb strings.Builder
+	sb.WriteString(`SELECT t.tgname, pg_catalog.pg_get_triggerdef(t.oid, true), t.tgenabled::text, `)
+	if version >= 130000 {
+		sb.WriteString(`
+		CASE WHEN t.tgparentid != 0 THEN
+		  (SELECT u.tgrelid::pg_catalog.regclass::pg_catalog.text
+		   FROM pg_catalog.pg_trigger AS u,
+		        pg_catalog.pg_partition_ancestors(t.tgrelid) WITH ORDINALITY AS a(relid, depth)
+		   WHERE u.tgname = t.tgname AND u.tgrelid = a.relid
+		         AND u.tgparentid = 0
+		   ORDER BY a.depth LIMIT 1)
+		END AS parent`)
+	} else {
+		sb.WriteString(`NULL AS parent`)
+	}
+	sb.WriteString(`
 		FROM pg_catalog.pg_trigger t
-		WHERE t.tgrelid = $1 AND NOT t.tgisinternal ORDER BY 1`
-	rows, err := db.Query(ctx, sql, oid)
+		WHERE t.tgrelid = $1 AND `)
+	if version >= 110000 && version < 150000 {
+		sb.WriteString(`(NOT t.tgisinternal OR (t.tgisinternal AND t.tgenabled = 'D')
+		  OR EXISTS (SELECT 1 FROM pg_catalog.pg_depend WHERE objid = t.oid
+		      AND refclassid = 'pg_catalog.pg_trigger'::pg_catalog.regclass))`)
+	} else {
+		sb.WriteString(`(NOT t.tgisinternal OR (t.tgisinternal AND t.tgenabled = 'D'))`)
+	}
+	sb.WriteString(` ORDER BY 1`)
+	return sb.String()
+}
+
+func getTriggers(ctx context.Context, db database.Queryer, oid uint32, version int) (enabled, disabled, always, replica []string, err error) {
+	rows, err := db.Query(ctx, triggersQuery(version), oid)
 	if err != nil {
 		return nil, nil, nil, nil, err
 	}
@@ -719,7 +914,8 @@ func getTriggers(ctx context.Context, db database.Queryer, oid uint32) (enabled,
 
 	for rows.Next() {
 		var tgname, tgdef, tgenabled string
-		if err := rows.Scan(&tgname, &tgdef, &tgenabled); err != nil {
+		var parent *string
+		if err := rows.Scan(&tgname, &tgdef, &tgenabled, &parent); err != nil {
 			return nil, nil, nil, nil, err
 		}
 
@@ -727,6 +923,9 @@ func getTriggers(ctx context.Context, db database.Queryer, oid uint32) (enabled,
 		if triggerPos >= 0 {
 			tgdef = tgdef[triggerPos+9:]
 		}
+		if parent != nil {
+			tgdef += ", ON TABLE " + *parent
+		}
 
 		switch tgenabled {
 		case "O":
@@ -742,9 +941,18 @@ func getTriggers(ctx context.Context, db database.Queryer, oid uint32) (enabled,
 	return
 }
 
-func getPartitionInfo(ctx context.Context, db database.Queryer, oid uint32) (partOf, partConstraints []string, err error) {
+// detachPendingColumn returns the pg_inherits column telling the partitions
+// being detached concurrently, which PG 14 added.
+func detachPendingColumn(version int) string {
+	if version >= 140000 {
+		return "i.inhdetachpending"
+	}
+	return "false"
+}
+
+func getPartitionInfo(ctx context.Context, db database.Queryer, oid uint32, version int) (partOf, partConstraints []string, err error) {
 	sql := `select quote_ident(np.nspname) || '.' || quote_ident(cp.relname) || ' ' || pg_get_expr(cc.relpartbound, cc.oid, true),
-		pg_get_partition_constraintdef(cc.oid)
+		pg_get_partition_constraintdef(cc.oid), ` + detachPendingColumn(version) + `
 		from pg_inherits i
 		inner join pg_class cp on cp.oid = i.inhparent
 		inner join pg_namespace np on np.oid = cp.relnamespace
@@ -758,14 +966,18 @@ func getPartitionInfo(ctx context.Context, db database.Queryer, oid uint32) (par
 
 	for rows.Next() {
 		var po, pc string
-		rows.Scan(&po, &pc)
+		var detachPending bool
+		rows.Scan(&po, &pc, &detachPending)
+		if detachPending {
+			po += " DETACH PENDING"
+		}
 		partOf = append(partOf, po)
 		partConstraints = append(partConstraints, pc)
 	}
 	return
 }
 
-func getPartitionDetails(ctx context.Context, db database.Queryer, oid uint32, verbose bool) (partKey *string, partitions []string, summary *string, err error) {
+func getPartitionDetails(ctx context.Context, db database.Queryer, oid uint32, verbose bool, version int) (partKey *string, partitions []string, summary *string, err error) {
 	// Partition key
 	sql := fmt.Sprintf("select pg_get_partkeydef(%d)", oid)
 	var pk string
@@ -774,11 +986,12 @@ func getPartitionDetails(ctx context.Context, db database.Queryer, oid uint32, v
 	}
 
 	// Partitions
-	sql = fmt.Sprintf(`select quote_ident(n.nspname) || '.' || quote_ident(c.relname) || ' ' || pg_get_expr(c.relpartbound, c.oid, true)
+	sql = fmt.Sprintf(`select quote_ident(n.nspname) || '.' || quote_ident(c.relname) || ' ' || pg_get_expr(c.relpartbound, c.oid, true),
+		%s
 		from pg_inherits i
 		inner join pg_class c on c.oid = i.inhrelid
 		inner join pg_namespace n on n.oid = c.relnamespace
-		where i.inhparent = %d order by 1`, oid)
+		where i.inhparent = %d order by 1`, detachPendingColumn(version), oid)
 	rows, err := db.Query(ctx, sql)
 	if err != nil {
 		return nil, nil, nil, err
@@ -787,12 +1000,16 @@ func getPartitionDetails(ctx context.Context, db database.Queryer, oid uint32, v
 
 	for rows.Next() {
 		var p string
-		rows.Scan(&p)
+		var detachPending bool
+		rows.Scan(&p, &detachPending)
+		if detachPending {
+			p += " (DETACH PENDING)"
+		}
 		partitions = append(partitions, p)
 	}
 
 	if !verbose {
-		s := fmt.Sprintf("Number of partitions %d: (Use \\d+ to list them.)", len(partitions))
+		s := fmt.Sprintf("Number of partitions: %d (Use \\d+ to list them.)", len(partitions))
 		summary = &s
 		partitions = nil
 	}
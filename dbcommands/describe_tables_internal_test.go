@@ -0,0 +1,66 @@
+package dbcommands
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDescribeQueriesByServerVersion documents which catalog columns \d
+// queries on which server versions, following psql.
+func TestDescribeQueriesByServerVersion(t *testing.T) {
+	table := func(version int) tableInfo {
+		return tableInfo{RelKind: "r", ServerVersion: version}
+	}
+	index := func(version int) tableInfo {
+		return tableInfo{RelKind: "i", ServerVersion: version}
+	}
+
+	tests := []struct {
+		name     string
+		query    func(version int) string
+		fragment string
+		since    int // first version querying fragment
+		until    int // first version no longer querying it, 0 if none
+	}{
+		{"table info", tableInfoQuery, "c.relhasoids", 0, 120000},
+		{"table info", tableInfoQuery, "c.relispartition", 100000, 0},
+		{"table info", tableInfoQuery, "pg_catalog.pg_am", 120000, 0},
+		{"columns", func(v int) string { return tableColumnsQuery(1, table(v), false) }, "a.attgenerated", 120000, 0},
+		{"columns+", func(v int) string { return tableColumnsQuery(1, table(v), true) }, "a.attcompression", 140000, 0},
+		{"index columns", func(v int) string { return tableColumnsQuery(1, index(v), false) }, "i.indnkeyatts", 110000, 0},
+		{"index columns", func(v int) string { return tableColumnsQuery(1, index(v), false) }, "pg_get_indexdef", 0, 0},
+		{"triggers", triggersQuery, "t.tgisinternal AND t.tgenabled = 'D'", 0, 0},
+		{"triggers", triggersQuery, "pg_catalog.pg_depend", 110000, 150000},
+		{"triggers", triggersQuery, "t.tgparentid", 130000, 0},
+		{"partitions", detachPendingColumn, "i.inhdetachpending", 140000, 0},
+	}
+
+	for _, version := range []int{100000, 110000, 120000, 130000, 140000, 150000, 160000, 170000, 180000} {
+		for _, tt := range tests {
+			t.Run(fmt.Sprintf("%d/%s/%s", version/10000, tt.name, tt.fragment), func(t *testing.T) {
+				sql := tt.query(version)
+				if version >= tt.since && (tt.until == 0 || version < tt.until) {
+					assert.Contains(t, sql, tt.fragment)
+				} else {
+					assert.NotContains(t, sql, tt.fragment)
+				}
+			})
+		}
+	}
+}
+
+func TestShowCompression(t *testing.T) {
+	for _, relkind := range []string{"r", "p", "m"} {
+		ti := tableInfo{RelKind: relkind, ServerVersion: 140000}
+		assert.True(t, showCompression(ti, true), relkind)
+		assert.False(t, showCompression(ti, false), relkind)
+
+		ti.ServerVersion = 130000
+		assert.False(t, showCompression(ti, true), relkind)
+	}
+	for _, relkind := range []string{"v", "i", "S", "f", "c"} {
+		assert.False(t, showCompression(tableInfo{RelKind: relkind, ServerVersion: 180000}, true), relkind)
+	}
+}
@@ -111,17 +111,13 @@ func TestDescribeOneTableDetailsVerbose(t *testing.T) {
 			t.Fatalf("DescribeTables failed: %v", err)
 		}
 
-		columnsExpected := []string{
-			"Column",
-			"Type",
-			"Modifiers",
-			"Storage",
-			"Stats target",
-			"Description",
+		columnsExpected := []string{"Column", "Type", "Modifiers", "Storage"}
+		// column compression is PG 14
+		if serverVersion(t, db) >= 140000 {
+			columnsExpected = append(columnsExpected, "Compression")
 		}
+		columnsExpected = append(columnsExpected, "Stats target", "Description")
 		assert.Equal(t, columnsExpected, result.Columns, "Column names do not match expected")
-		// expecting 6 columns
-		assert.Len(t, result.Columns, 6)
 
 		// Check for columns from both tables
 		for col_name := range table.columns {
@@ -221,6 +217,55 @@ func TestDescribeTableMetadata(t *testing.T) {
 	assert.True(t, foundDesc, "Expected column comment not found")
 }
 
+func TestDescribePartitionFooters(t *testing.T) {
+	db := connectTestDB(t)
+	defer db.(*pgxpool.Pool).Close()
+
+	ctx := context.Background()
+	_, err := db.(*pgxpool.Pool).Exec(ctx, `
+		CREATE TABLE test_describe_parts (id int) PARTITION BY RANGE (id);
+		CREATE TABLE test_describe_parts_1 PARTITION OF test_describe_parts FOR VALUES FROM (0) TO (10);
+	`)
+	if err != nil {
+		t.Fatalf("Failed to setup tables: %v", err)
+	}
+	defer func() {
+		_, _ = db.(*pgxpool.Pool).Exec(ctx, "DROP TABLE IF EXISTS test_describe_parts CASCADE")
+	}()
+
+	describe := func(name string, verbose bool) pgxspecial.TableFooterMeta {
+		t.Helper()
+		var oid uint32
+		err := db.(*pgxpool.Pool).QueryRow(ctx, "SELECT oid FROM pg_class WHERE relname = $1", name).Scan(&oid)
+		if err != nil {
+			t.Fatalf("Failed to get OID: %v", err)
+		}
+		result, err := dbcommands.DescribeOneTableDetails(ctx, db, "public", name, oid, verbose)
+		if err != nil {
+			t.Fatalf("DescribeOneTableDetails failed: %v", err)
+		}
+		return result.TableMetaData
+	}
+
+	// the partitions are counted, as psql does, unless listed by \d+
+	meta := describe("test_describe_parts", false)
+	if assert.NotNil(t, meta.PartitionsSummary) {
+		assert.Equal(t, "Number of partitions: 1 (Use \\d+ to list them.)", *meta.PartitionsSummary)
+	}
+	assert.Empty(t, meta.Partitions)
+	meta = describe("test_describe_parts", true)
+	assert.Nil(t, meta.PartitionsSummary)
+	assert.Len(t, meta.Partitions, 1)
+
+	// the partition constraint is shown by \d+ only
+	meta = describe("test_describe_parts_1", false)
+	assert.NotEmpty(t, meta.PartitionOf)
+	assert.Empty(t, meta.PartitionConstraints)
+	meta = describe("test_describe_parts_1", true)
+	assert.NotEmpty(t, meta.PartitionOf)
+	assert.NotEmpty(t, meta.PartitionConstraints)
+}
+
 func TestDescribeTableDetails_Patterns(t *testing.T) {
 	db := connectTestDB(t)
 	defer db.(*pgxpool.Pool).Close()
@@ -234,7 +279,7 @@ func TestDescribeTableDetails_Patterns(t *testing.T) {
 	}
 
 	t.Run("Multiple Matches", func(t *testing.T) {
-		res, err := dbcommands.DescribeTableDetails(ctx, db, "pattern_test*", false)
+		res, err := dbcommands.DescribeTableDetails(ctx, db, "pattern_test*", pgxspecial.CommandOptions{})
 		assert.NoError(t, err)
 
 		descRes, ok := res.(pgxspecial.DescribeTableListResult)
@@ -243,7 +288,7 @@ func TestDescribeTableDetails_Patterns(t *testing.T) {
 	})
 
 	t.Run("No Pattern", func(t *testing.T) {
-		res, err := dbcommands.DescribeTableDetails(ctx, db, "", false)
+		res, err := dbcommands.DescribeTableDetails(ctx, db, "", pgxspecial.CommandOptions{})
 		assert.NoError(t, err)
 
 		rowRes, ok := res.(pgxspecial.RowResult)
@@ -254,7 +299,7 @@ func TestDescribeTableDetails_Patterns(t *testing.T) {
 	})
 
 	t.Run("Verbose", func(t *testing.T) {
-		res, err := dbcommands.DescribeTableDetails(ctx, db, "other_table", true)
+		res, err := dbcommands.DescribeTableDetails(ctx, db, "other_table", pgxspecial.CommandOptions{Verbose: true})
 		assert.NoError(t, err)
 
 		descRes, ok := res.(pgxspecial.DescribeTableListResult)
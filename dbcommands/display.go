@@ -0,0 +1,193 @@
+package dbcommands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/balaji01-4d/pgxspecial"
+	"github.com/balaji01-4d/pgxspecial/database"
+)
+
+func init() {
+	pgxspecial.MustRegisterCommand(pgxspecial.SpecialCommandRegistry{
+		Cmd:           "\\pset",
+		Description:   "Set table output option, or list all if no parameters.",
+		Group:         pgxspecial.GroupFormatting,
+		Syntax:        "\\pset [NAME [VALUE]]",
+		Handler:       SetPrintOption,
+		CaseSensitive: true,
+		RawArgs:       true,
+	})
+
+	pgxspecial.MustRegisterCommand(pgxspecial.SpecialCommandRegistry{
+		Cmd:           "\\x",
+		Description:   "Toggle expanded output.",
+		Group:         pgxspecial.GroupFormatting,
+		Syntax:        "\\x [on|off|auto]",
+		Handler:       SetExpanded,
+		CaseSensitive: true,
+		RawArgs:       true,
+	})
+
+	pgxspecial.MustRegisterCommand(pgxspecial.SpecialCommandRegistry{
+		Cmd:           "\\a",
+		Description:   "Toggle between unaligned and aligned output mode.",
+		Group:         pgxspecial.GroupFormatting,
+		Syntax:        "\\a",
+		Handler:       ToggleAligned,
+		CaseSensitive: true,
+	})
+
+	pgxspecial.MustRegisterCommand(pgxspecial.SpecialCommandRegistry{
+		Cmd:           "\\t",
+		Description:   "Show only rows.",
+		Group:         pgxspecial.GroupFormatting,
+		Syntax:        "\\t [on|off]",
+		Handler:       SetTuplesOnly,
+		CaseSensitive: true,
+		RawArgs:       true,
+	})
+
+	pgxspecial.MustRegisterCommand(pgxspecial.SpecialCommandRegistry{
+		Cmd:           "\\f",
+		Description:   "Show or set field separator for unaligned query output.",
+		Group:         pgxspecial.GroupFormatting,
+		Syntax:        "\\f [STRING]",
+		Handler:       SetFieldSeparator,
+		CaseSensitive: true,
+		RawArgs:       true,
+	})
+
+	pgxspecial.MustRegisterCommand(pgxspecial.SpecialCommandRegistry{
+		Cmd:           "\\C",
+		Description:   "Set table title, or unset if none.",
+		Group:         pgxspecial.GroupFormatting,
+		Syntax:        "\\C [STRING]",
+		Handler:       SetTitle,
+		CaseSensitive: true,
+		RawArgs:       true,
+	})
+
+	pgxspecial.MustRegisterCommand(pgxspecial.SpecialCommandRegistry{
+		Cmd:           "\\H",
+		Description:   "Toggle HTML output mode.",
+		Group:         pgxspecial.GroupFormatting,
+		Syntax:        "\\H",
+		Handler:       ToggleHTML,
+		CaseSensitive: true,
+	})
+}
+
+// SetPrintOption sets a print option of the session carried by ctx, as in
+// \pset border 2, and confirms its new value. Without a value it toggles
+// boolean options. Without arguments it lists every option.
+func SetPrintOption(ctx context.Context, db database.Queryer, args string, opts pgxspecial.CommandOptions) (pgxspecial.SpecialCommandResult, error) {
+	session := pgxspecial.SessionFromContext(ctx)
+	if session == nil {
+		return nil, pgxspecial.ErrNoSession
+	}
+
+	words, err := splitArgs(args, session)
+	if err != nil {
+		return nil, err
+	}
+	if len(words) == 0 {
+		var r pgxspecial.TextResult
+		for _, v := range session.PrintOptions().Values() {
+			r.Lines = append(r.Lines, pgxspecial.TextLine{Text: fmt.Sprintf("%-24s %s", v.Name, v.Value)})
+		}
+		return r, nil
+	}
+	return setPrintOption(session, words[0], words[1:])
+}
+
+// setPrintOption sets the print option name to the first of values, or
+// toggles it if there is none, and confirms its new value.
+func setPrintOption(session *pgxspecial.Session, name string, values []string) (pgxspecial.SpecialCommandResult, error) {
+	o := session.PrintOptions()
+	var err error
+	if len(values) == 0 {
+		err = o.Toggle(name)
+	} else {
+		err = o.Set(name, values[0])
+	}
+	if err != nil {
+		return nil, err
+	}
+	session.SetPrintOptions(o)
+
+	status, err := o.Status(name)
+	if err != nil {
+		return nil, err
+	}
+	return pgxspecial.StatusResult{Message: status}, nil
+}
+
+// printOptionCommand returns a handler setting the print option name to
+// its argument, or toggling it without one, as \x, \t, \f and \C do.
+func printOptionCommand(name string) pgxspecial.SpecialHandler {
+	return func(ctx context.Context, db database.Queryer, args string, opts pgxspecial.CommandOptions) (pgxspecial.SpecialCommandResult, error) {
+		session := pgxspecial.SessionFromContext(ctx)
+		if session == nil {
+			return nil, pgxspecial.ErrNoSession
+		}
+
+		words, err := splitArgs(args, session)
+		if err != nil {
+			return nil, err
+		}
+		return setPrintOption(session, name, words)
+	}
+}
+
+// SetExpanded sets the expanded display mode of the session carried by ctx.
+// Without arguments it toggles expanded display, turning auto mode off.
+func SetExpanded(ctx context.Context, db database.Queryer, args string, opts pgxspecial.CommandOptions) (pgxspecial.SpecialCommandResult, error) {
+	return printOptionCommand("expanded")(ctx, db, args, opts)
+}
+
+// SetTuplesOnly sets or toggles the tuples_only print option, which leaves
+// out titles, headers and footers.
+func SetTuplesOnly(ctx context.Context, db database.Queryer, args string, opts pgxspecial.CommandOptions) (pgxspecial.SpecialCommandResult, error) {
+	return printOptionCommand("tuples_only")(ctx, db, args, opts)
+}
+
+// SetFieldSeparator sets the field separator of the unaligned format, or
+// shows it without arguments.
+func SetFieldSeparator(ctx context.Context, db database.Queryer, args string, opts pgxspecial.CommandOptions) (pgxspecial.SpecialCommandResult, error) {
+	return printOptionCommand("fieldsep")(ctx, db, args, opts)
+}
+
+// SetTitle sets the title printed above query results, or unsets it
+// without arguments.
+func SetTitle(ctx context.Context, db database.Queryer, args string, opts pgxspecial.CommandOptions) (pgxspecial.SpecialCommandResult, error) {
+	return printOptionCommand("title")(ctx, db, args, opts)
+}
+
+// ToggleAligned switches the output format between aligned and unaligned.
+// Any other format is switched to aligned.
+func ToggleAligned(ctx context.Context, db database.Queryer, args string, opts pgxspecial.CommandOptions) (pgxspecial.SpecialCommandResult, error) {
+	return toggleFormat(ctx, pgxspecial.FormatUnaligned)
+}
+
+// ToggleHTML switches the output format between HTML and aligned.
+func ToggleHTML(ctx context.Context, db database.Queryer, args string, opts pgxspecial.CommandOptions) (pgxspecial.SpecialCommandResult, error) {
+	return toggleFormat(ctx, pgxspecial.FormatHTML)
+}
+
+// toggleFormat switches the output format to format, or back to aligned if
+// it is already in use.
+func toggleFormat(ctx context.Context, format string) (pgxspecial.SpecialCommandResult, error) {
+	session := pgxspecial.SessionFromContext(ctx)
+	if session == nil {
+		return nil, pgxspecial.ErrNoSession
+	}
+
+	// \a turns every format but aligned to aligned
+	current := session.PrintOptions().Format
+	if (format == pgxspecial.FormatUnaligned && current != pgxspecial.FormatAligned) ||
+		(format == pgxspecial.FormatHTML && current == pgxspecial.FormatHTML) {
+		format = pgxspecial.FormatAligned
+	}
+	return setPrintOption(session, "format", []string{format})
+}
@@ -0,0 +1,123 @@
+package dbcommands_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/balaji01-4d/pgxspecial"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetExpanded(t *testing.T) {
+	session := pgxspecial.NewSession()
+	ctx := pgxspecial.WithSession(context.Background(), session)
+
+	tests := []struct {
+		cmd      string
+		expanded pgxspecial.ExpandedMode
+		message  string
+	}{
+		{"\\x", pgxspecial.ExpandedOn, "Expanded display is on."},
+		{"\\x", pgxspecial.ExpandedOff, "Expanded display is off."},
+		{"\\x auto", pgxspecial.ExpandedAuto, "Expanded display is used automatically."},
+		{"\\x", pgxspecial.ExpandedOff, "Expanded display is off."},
+		{"\\x ON", pgxspecial.ExpandedOn, "Expanded display is on."},
+		{"\\x 0", pgxspecial.ExpandedOff, "Expanded display is off."},
+	}
+	for _, tt := range tests {
+		res, _, err := pgxspecial.ExecuteSpecialCommand(ctx, nil, tt.cmd)
+		require.NoError(t, err, tt.cmd)
+		assert.Equal(t, pgxspecial.StatusResult{Message: tt.message}, res, tt.cmd)
+		assert.Equal(t, tt.expanded, session.PrintOptions().Expanded, tt.cmd)
+	}
+
+	_, _, err := pgxspecial.ExecuteSpecialCommand(ctx, nil, "\\x sometimes")
+	assert.ErrorContains(t, err, `unrecognized value "sometimes" for "expanded"`)
+	assert.Equal(t, pgxspecial.ExpandedOff, session.PrintOptions().Expanded)
+
+	_, _, err = pgxspecial.ExecuteSpecialCommand(context.Background(), nil, "\\x")
+	assert.ErrorIs(t, err, pgxspecial.ErrNoSession)
+}
+
+func TestSetPrintOption(t *testing.T) {
+	session := pgxspecial.NewSession()
+	ctx := pgxspecial.WithSession(context.Background(), session)
+
+	tests := []struct {
+		cmd     string
+		message string
+	}{
+		{"\\pset border 2", "Border style is 2."},
+		{"\\pset format u", "Output format is unaligned."},
+		{"\\pset null '(null)'", `Null display is "(null)".`},
+		{"\\pset footer", "Default footer is off."},
+		{"\\pset linestyle unicode", "Line style is unicode."},
+		{"\\pset x", "Expanded display is on."},
+		{"\\pset csv_fieldsep ';'", `Field separator for CSV is ";".`},
+	}
+	for _, tt := range tests {
+		res, _, err := pgxspecial.ExecuteSpecialCommand(ctx, nil, tt.cmd)
+		require.NoError(t, err, tt.cmd)
+		assert.Equal(t, pgxspecial.StatusResult{Message: tt.message}, res, tt.cmd)
+	}
+
+	o := session.PrintOptions()
+	assert.Equal(t, 2, o.Border)
+	assert.Equal(t, pgxspecial.FormatUnaligned, o.Format)
+	assert.Equal(t, "(null)", o.Null)
+	assert.False(t, o.Footer)
+	assert.Equal(t, pgxspecial.LineStyleUnicode, o.LineStyle)
+	assert.Equal(t, ";", o.CSVFieldSep)
+
+	res, _, err := pgxspecial.ExecuteSpecialCommand(ctx, nil, "\\pset")
+	require.NoError(t, err)
+	list := res.(pgxspecial.TextResult).String()
+	assert.Contains(t, list, "border                   2\n")
+	assert.Contains(t, list, "null                     '(null)'\n")
+
+	_, _, err = pgxspecial.ExecuteSpecialCommand(ctx, nil, "\\pset border thick")
+	assert.ErrorContains(t, err, `invalid value "thick" for "border"`)
+	_, _, err = pgxspecial.ExecuteSpecialCommand(ctx, nil, "\\pset pager off")
+	assert.EqualError(t, err, "unknown option: pager")
+	assert.Equal(t, 2, session.PrintOptions().Border)
+
+	_, _, err = pgxspecial.ExecuteSpecialCommand(context.Background(), nil, "\\pset border 0")
+	assert.ErrorIs(t, err, pgxspecial.ErrNoSession)
+}
+
+func TestPrintOptionShortcuts(t *testing.T) {
+	session := pgxspecial.NewSession()
+	ctx := pgxspecial.WithSession(context.Background(), session)
+
+	tests := []struct {
+		cmd     string
+		message string
+	}{
+		{"\\a", "Output format is unaligned."},
+		{"\\a", "Output format is aligned."},
+		{"\\H", "Output format is html."},
+		{"\\a", "Output format is aligned."},
+		{"\\H", "Output format is html."},
+		{"\\H", "Output format is aligned."},
+		{"\\t", "Tuples only is on."},
+		{"\\t off", "Tuples only is off."},
+		{"\\f ,", `Field separator is ",".`},
+		{"\\f", `Field separator is ",".`},
+		{"\\C 'My results'", `Title is "My results".`},
+	}
+	for _, tt := range tests {
+		res, _, err := pgxspecial.ExecuteSpecialCommand(ctx, nil, tt.cmd)
+		require.NoError(t, err, tt.cmd)
+		assert.Equal(t, pgxspecial.StatusResult{Message: tt.message}, res, tt.cmd)
+	}
+
+	o := session.PrintOptions()
+	assert.Equal(t, ",", o.FieldSep)
+	assert.Equal(t, "My results", o.Title)
+
+	res, _, err := pgxspecial.ExecuteSpecialCommand(ctx, nil, "\\C")
+	require.NoError(t, err)
+	assert.Equal(t, pgxspecial.StatusResult{Message: "Title is unset."}, res)
+	assert.Empty(t, session.PrintOptions().Title)
+}
@@ -0,0 +1,220 @@
+package dbcommands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/balaji01-4d/pgxspecial"
+	"github.com/balaji01-4d/pgxspecial/database"
+)
+
+func init() {
+	pgxspecial.MustRegisterCommand(pgxspecial.SpecialCommandRegistry{
+		Cmd:           "\\p",
+		Alias:         []string{"\\print"},
+		Description:   "Show the contents of the query buffer.",
+		Group:         pgxspecial.GroupQueryBuffer,
+		Syntax:        "\\p",
+		Handler:       PrintQueryBuffer,
+		CaseSensitive: true,
+	})
+
+	pgxspecial.MustRegisterCommand(pgxspecial.SpecialCommandRegistry{
+		Cmd:           "\\r",
+		Alias:         []string{"\\reset"},
+		Description:   "Reset (clear) the query buffer.",
+		Group:         pgxspecial.GroupQueryBuffer,
+		Syntax:        "\\r",
+		Handler:       ResetQueryBuffer,
+		CaseSensitive: true,
+	})
+
+	pgxspecial.MustRegisterCommand(pgxspecial.SpecialCommandRegistry{
+		Cmd:           "\\w",
+		Alias:         []string{"\\write"},
+		Description:   "Write query buffer to file.",
+		Group:         pgxspecial.GroupQueryBuffer,
+		Syntax:        "\\w FILE",
+		Handler:       WriteQueryBuffer,
+		Categories:    []string{pgxspecial.CategoryFilesystem},
+		CaseSensitive: true,
+	})
+
+	pgxspecial.MustRegisterCommand(pgxspecial.SpecialCommandRegistry{
+		Cmd:           "\\e",
+		Alias:         []string{"\\edit"},
+		Description:   "Edit the query buffer (or file) with external editor.",
+		Group:         pgxspecial.GroupQueryBuffer,
+		Syntax:        "\\e [FILE] [LINE]",
+		Handler:       EditQueryBuffer,
+		Categories:    []string{pgxspecial.CategoryShell, pgxspecial.CategoryFilesystem},
+		CaseSensitive: true,
+	})
+}
+
+// PrintQueryBuffer returns the query buffer of the session carried by ctx,
+// or the previous query if the buffer is empty.
+func PrintQueryBuffer(ctx context.Context, db database.Queryer, args string, opts pgxspecial.CommandOptions) (pgxspecial.SpecialCommandResult, error) {
+	session := pgxspecial.SessionFromContext(ctx)
+	if session == nil {
+		return nil, pgxspecial.ErrNoSession
+	}
+	return pgxspecial.QueryBufferResult{Text: currentQuery(session.QueryBuffer())}, nil
+}
+
+// ResetQueryBuffer empties the query buffer of the session carried by ctx
+// and confirms it with a pgxspecial.StatusResult, as psql does.
+func ResetQueryBuffer(ctx context.Context, db database.Queryer, args string, opts pgxspecial.CommandOptions) (pgxspecial.SpecialCommandResult, error) {
+	session := pgxspecial.SessionFromContext(ctx)
+	if session == nil {
+		return nil, pgxspecial.ErrNoSession
+	}
+	session.QueryBuffer().Reset()
+	return pgxspecial.StatusResult{Message: "Query buffer reset (cleared)."}, nil
+}
+
+// WriteQueryBuffer writes the query buffer, or the previous query if the
+// buffer is empty, to a file or, given "|command", to a shell command.
+func WriteQueryBuffer(ctx context.Context, db database.Queryer, args string, opts pgxspecial.CommandOptions) (pgxspecial.SpecialCommandResult, error) {
+	session := pgxspecial.SessionFromContext(ctx)
+	if session == nil {
+		return nil, pgxspecial.ErrNoSession
+	}
+
+	target := args
+	if !strings.HasPrefix(args, "|") {
+		words, err := splitArgs(args, nil)
+		if err != nil {
+			return nil, err
+		}
+		if len(words) == 0 {
+			return nil, fmt.Errorf("missing required argument")
+		}
+		target = words[0]
+	}
+	if err := checkOutputTarget(ctx, "\\w", target); err != nil {
+		return nil, err
+	}
+
+	w, err := pgxspecial.OpenOutput(target)
+	if err != nil {
+		return nil, err
+	}
+	text := currentQuery(session.QueryBuffer())
+	if text != "" {
+		text += "\n"
+	}
+	_, err = io.WriteString(w, text)
+	if cerr := w.Close(); err == nil {
+		err = cerr
+	}
+	return nil, err
+}
+
+// EditQueryBuffer opens the query buffer, or the previous query if the
+// buffer is empty, in an editor and loads the edited text back into the
+// buffer. Given a file, the file is edited and loaded instead. A line
+// number positions the editor. The editor is launched with the
+// pgxspecial.EditorRunner carried by ctx. In a script the edited text is
+// read again as input, as in psql.
+func EditQueryBuffer(ctx context.Context, db database.Queryer, args string, opts pgxspecial.CommandOptions) (pgxspecial.SpecialCommandResult, error) {
+	session := pgxspecial.SessionFromContext(ctx)
+	if session == nil {
+		return nil, pgxspecial.ErrNoSession
+	}
+
+	words, err := splitArgs(args, nil)
+	if err != nil {
+		return nil, err
+	}
+	var file, lineArg string
+	switch len(words) {
+	case 0:
+	case 1:
+		if strings.Trim(words[0], "0123456789") == "" {
+			lineArg = words[0]
+		} else {
+			file = words[0]
+		}
+	default:
+		file, lineArg = words[0], words[1]
+	}
+	line, err := parseLineNumber(lineArg)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := session.QueryBuffer()
+	var text string
+	if file == "" {
+		text, err = editText(ctx, currentQuery(buf), line)
+	} else {
+		text, err = editFile(ctx, file, line)
+	}
+	if err != nil {
+		return nil, err
+	}
+	buf.Set(text)
+	return pgxspecial.QueryBufferResult{Text: text}, nil
+}
+
+// currentQuery returns the text of buf, or the previous query if buf is
+// empty.
+func currentQuery(buf *pgxspecial.QueryBuffer) string {
+	if text := buf.String(); strings.TrimSpace(text) != "" {
+		return text
+	}
+	return buf.Previous()
+}
+
+// parseLineNumber parses the optional line number argument of the editing
+// commands, returning 0 if arg is empty.
+func parseLineNumber(arg string) (int, error) {
+	if arg == "" {
+		return 0, nil
+	}
+	line, err := strconv.Atoi(arg)
+	if err != nil || line < 1 {
+		return 0, fmt.Errorf("invalid line number: %s", arg)
+	}
+	return line, nil
+}
+
+// editText lets the user edit text in a temporary file and returns the
+// result without the trailing newlines.
+func editText(ctx context.Context, text string, line int) (string, error) {
+	f, err := os.CreateTemp("", "psql.edit.*.sql")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(f.Name())
+
+	if text != "" && !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	_, err = f.WriteString(text)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return "", err
+	}
+	return editFile(ctx, f.Name(), line)
+}
+
+// editFile lets the user edit file and returns its contents without the
+// trailing newlines.
+func editFile(ctx context.Context, file string, line int) (string, error) {
+	if err := pgxspecial.EditorRunnerFromContext(ctx)(ctx, file, line); err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\n"), nil
+}
@@ -0,0 +1,191 @@
+package dbcommands
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/balaji01-4d/pgxspecial"
+	"github.com/balaji01-4d/pgxspecial/database"
+)
+
+func init() {
+	pgxspecial.MustRegisterCommand(pgxspecial.SpecialCommandRegistry{
+		Cmd:           "\\ef",
+		Description:   "Edit function definition with external editor.",
+		Group:         pgxspecial.GroupQueryBuffer,
+		Syntax:        "\\ef [FUNCNAME [LINE]]",
+		Handler:       EditFunctionDefinition,
+		Categories:    []string{pgxspecial.CategoryShell},
+		RawArgs:       true,
+		CaseSensitive: true,
+	})
+
+	pgxspecial.MustRegisterCommand(pgxspecial.SpecialCommandRegistry{
+		Cmd:           "\\ev",
+		Description:   "Edit view definition with external editor.",
+		Group:         pgxspecial.GroupQueryBuffer,
+		Syntax:        "\\ev [VIEWNAME [LINE]]",
+		Handler:       EditViewDefinition,
+		Categories:    []string{pgxspecial.CategoryShell},
+		RawArgs:       true,
+		CaseSensitive: true,
+	})
+}
+
+// functionTemplate and viewTemplate are edited by \ef and \ev without an
+// argument, as in psql.
+const (
+	functionTemplate = "CREATE FUNCTION ( )\n" +
+		" RETURNS \n" +
+		" LANGUAGE \n" +
+		" -- common options:  IMMUTABLE  STABLE  STRICT  SECURITY DEFINER\n" +
+		"AS $function$\n" +
+		"\n$function$\n"
+
+	viewTemplate = "CREATE VIEW  AS\n" +
+		" SELECT \n" +
+		"  -- something...\n"
+)
+
+// EditFunctionDefinition opens the CREATE OR REPLACE FUNCTION statement of
+// a function in an editor, or a blank CREATE FUNCTION template without an
+// argument. The edited text is returned as a pgxspecial.QueryBufferResult
+// and, if ctx carries a Session, loaded into its query buffer for the caller
+// to execute. A line number after the name positions the editor, line 1
+// being the first line of the function body.
+func EditFunctionDefinition(ctx context.Context, db database.Queryer, args string, opts pgxspecial.CommandOptions) (pgxspecial.SpecialCommandResult, error) {
+	name, line, err := splitLineNumber(args)
+	if err != nil {
+		return nil, err
+	}
+
+	text := functionTemplate
+	if name != "" {
+		foid, err := functionOID(ctx, db, name)
+		if err != nil {
+			return nil, err
+		}
+		err = db.QueryRow(ctx, "SELECT pg_catalog.pg_get_functiondef($1)", foid).Scan(&text)
+		if err != nil {
+			return nil, err
+		}
+		if line > 0 {
+			line += functionBodyLine(text)
+		}
+	}
+	return editDefinition(ctx, text, line)
+}
+
+// EditViewDefinition opens the CREATE OR REPLACE VIEW statement of a view in
+// an editor, or a blank CREATE VIEW template without an argument, like
+// EditFunctionDefinition. Lines are numbered from the start of the
+// statement, as \sv+ shows them.
+func EditViewDefinition(ctx context.Context, db database.Queryer, args string, opts pgxspecial.CommandOptions) (pgxspecial.SpecialCommandResult, error) {
+	name, line, err := splitLineNumber(args)
+	if err != nil {
+		return nil, err
+	}
+
+	text := viewTemplate
+	if name != "" {
+		text, err = viewDefinition(ctx, db, name)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return editDefinition(ctx, text, line)
+}
+
+// editDefinition lets the user edit the statement text and loads the result
+// into the query buffer.
+func editDefinition(ctx context.Context, text string, line int) (pgxspecial.SpecialCommandResult, error) {
+	text, err := editText(ctx, text, line)
+	if err != nil {
+		return nil, err
+	}
+	if session := pgxspecial.SessionFromContext(ctx); session != nil {
+		session.QueryBuffer().Set(text)
+	}
+	return pgxspecial.QueryBufferResult{Text: text}, nil
+}
+
+// splitLineNumber splits a trailing line number off the object name given
+// to \ef, \ev and their siblings, returning 0 if there is none. As in psql,
+// the number must follow whitespace, so a lone number is the name.
+func splitLineNumber(args string) (string, int, error) {
+	args = strings.TrimSpace(args)
+	i := strings.LastIndexAny(args, " \t\n")
+	if i < 0 {
+		return args, 0, nil
+	}
+	last := args[i+1:]
+	if strings.Trim(last, "0123456789") != "" {
+		return args, 0, nil
+	}
+
+	line, err := strconv.Atoi(last)
+	if err != nil || line < 1 {
+		return "", 0, fmt.Errorf("invalid line number: %s", last)
+	}
+	return strings.TrimSpace(args[:i]), line, nil
+}
+
+// viewDefinition returns the statement recreating the view or materialized
+// view name: CREATE OR REPLACE VIEW (CREATE MATERIALIZED VIEW) with its
+// options and check option, as psql's \sv and \ev build it.
+func viewDefinition(ctx context.Context, db database.Queryer, name string) (string, error) {
+	var void uint32
+	err := db.QueryRow(ctx, "SELECT $1::pg_catalog.regclass::pg_catalog.oid", name).Scan(&void)
+	if err != nil {
+		return "", err
+	}
+
+	var qualifiedName, kind, query, options string
+	var checkOption *string
+	err = db.QueryRow(ctx, `
+		SELECT pg_catalog.quote_ident(n.nspname) || '.' || pg_catalog.quote_ident(c.relname),
+		       c.relkind::pg_catalog.text,
+		       pg_catalog.pg_get_viewdef(c.oid, true),
+		       pg_catalog.array_to_string(ARRAY(
+		           SELECT pg_catalog.quote_ident(o.name) || '=' ||
+		                  CASE WHEN pg_catalog.quote_ident(o.value) = o.value THEN o.value
+		                       ELSE pg_catalog.quote_literal(o.value) END
+		           FROM pg_catalog.unnest(c.reloptions) WITH ORDINALITY AS r(opt, n),
+		                LATERAL (SELECT pg_catalog.split_part(r.opt, '=', 1) AS name,
+		                                pg_catalog.substr(r.opt, pg_catalog.strpos(r.opt, '=') + 1) AS value) o
+		           WHERE o.name <> 'check_option'
+		           ORDER BY r.n), ', '),
+		       CASE WHEN 'check_option=local' = ANY (c.reloptions) THEN 'LOCAL'
+		            WHEN 'check_option=cascaded' = ANY (c.reloptions) THEN 'CASCADED'
+		       END
+		FROM pg_catalog.pg_class c
+		     JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+		WHERE c.oid = $1`, void).Scan(&qualifiedName, &kind, &query, &options, &checkOption)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	switch kind {
+	case "v":
+		sb.WriteString("CREATE OR REPLACE VIEW ")
+	case "m":
+		sb.WriteString("CREATE MATERIALIZED VIEW ")
+	default:
+		return "", fmt.Errorf("%q is not a view", name)
+	}
+	sb.WriteString(qualifiedName)
+	if options != "" {
+		fmt.Fprintf(&sb, "\n WITH (%s)", options)
+	}
+	sb.WriteString(" AS\n")
+
+	// pg_get_viewdef ends the query with a semicolon
+	sb.WriteString(strings.TrimSuffix(strings.TrimRight(query, "\n"), ";"))
+	if checkOption != nil {
+		fmt.Fprintf(&sb, "\n WITH %s CHECK OPTION", *checkOption)
+	}
+	return sb.String(), nil
+}
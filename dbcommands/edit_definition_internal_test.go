@@ -0,0 +1,55 @@
+package dbcommands
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitLineNumber(t *testing.T) {
+	tests := []struct {
+		args string
+		name string
+		line int
+		err  string
+	}{
+		{args: "", name: "", line: 0},
+		{args: "f", name: "f", line: 0},
+		{args: "12", name: "12", line: 0},
+		{args: "12 3", name: "12", line: 3},
+		{args: "  f  12 ", name: "f", line: 12},
+		{args: "f(integer, integer) 3", name: "f(integer, integer)", line: 3},
+		{args: "f(integer, integer)", name: "f(integer, integer)", line: 0},
+		{args: "f2", name: "f2", line: 0},
+		{args: "f 0", err: "invalid line number: 0"},
+	}
+
+	for _, tt := range tests {
+		name, line, err := splitLineNumber(tt.args)
+		if tt.err != "" {
+			assert.EqualError(t, err, tt.err, tt.args)
+			continue
+		}
+		assert.NoError(t, err, tt.args)
+		assert.Equal(t, tt.name, name, tt.args)
+		assert.Equal(t, tt.line, line, tt.args)
+	}
+}
+
+func TestFunctionBodyLine(t *testing.T) {
+	plpgsql := "CREATE OR REPLACE FUNCTION public.f(a integer)\n" +
+		" RETURNS integer\n" +
+		" LANGUAGE plpgsql\n" +
+		"AS $function$\n" +
+		"BEGIN\n" +
+		"  RETURN a;\n" +
+		"END;\n" +
+		"$function$\n"
+	assert.Equal(t, 3, functionBodyLine(plpgsql))
+
+	sqlBody := "CREATE OR REPLACE FUNCTION public.g()\n" +
+		" RETURNS integer\n" +
+		" LANGUAGE sql\n" +
+		"RETURN 42\n"
+	assert.Equal(t, 3, functionBodyLine(sqlBody))
+}
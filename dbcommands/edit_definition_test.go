@@ -0,0 +1,117 @@
+package dbcommands_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/balaji01-4d/pgxspecial"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingEditor returns an EditorRunner recording the file contents and
+// line it is called with, and replacing the contents with edited if it is
+// not empty.
+func recordingEditor(content *string, line *int, edited string) pgxspecial.EditorRunner {
+	return func(ctx context.Context, file string, l int) error {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		*content, *line = string(data), l
+		if edited == "" {
+			return nil
+		}
+		return os.WriteFile(file, []byte(edited), 0o644)
+	}
+}
+
+func TestEditDefinitionTemplates(t *testing.T) {
+	var content string
+	var line int
+	session := pgxspecial.NewSession()
+	ctx := pgxspecial.WithEditorRunner(pgxspecial.WithSession(context.Background(), session), recordingEditor(&content, &line, ""))
+
+	res, _, err := pgxspecial.ExecuteSpecialCommand(ctx, nil, "\\ef")
+	require.NoError(t, err)
+	assert.Contains(t, content, "CREATE FUNCTION ( )\n RETURNS \n LANGUAGE \n")
+	assert.Equal(t, 0, line)
+	assert.Equal(t, pgxspecial.QueryBufferResult{Text: "CREATE FUNCTION ( )\n RETURNS \n LANGUAGE \n" +
+		" -- common options:  IMMUTABLE  STABLE  STRICT  SECURITY DEFINER\n" +
+		"AS $function$\n\n$function$"}, res)
+
+	res, _, err = pgxspecial.ExecuteSpecialCommand(ctx, nil, "\\ev")
+	require.NoError(t, err)
+	assert.Equal(t, "CREATE VIEW  AS\n SELECT \n  -- something...\n", content)
+	assert.Equal(t, "CREATE VIEW  AS\n SELECT \n  -- something...", session.QueryBuffer().String())
+
+	// without a session the edited text is only returned
+	ctx = pgxspecial.WithEditorRunner(context.Background(), recordingEditor(&content, &line, "CREATE VIEW v AS SELECT 1;\n"))
+	res, _, err = pgxspecial.ExecuteSpecialCommand(ctx, nil, "\\ev")
+	require.NoError(t, err)
+	assert.Equal(t, pgxspecial.QueryBufferResult{Text: "CREATE VIEW v AS SELECT 1;"}, res)
+
+	_, _, err = pgxspecial.ExecuteSpecialCommand(ctx, nil, "\\ef f 0")
+	assert.EqualError(t, err, "invalid line number: 0")
+}
+
+func TestEditFunctionDefinition(t *testing.T) {
+	db := connectTestDB(t)
+	defer db.(*pgxpool.Pool).Close()
+
+	ctx := context.Background()
+	setupFunction(t, ctx, db.(*pgxpool.Pool), "edit_me", "a integer", "RETURN a;")
+	defer teardownFunction(t, ctx, db.(*pgxpool.Pool), "edit_me", "integer")
+
+	var content string
+	var line int
+	session := pgxspecial.NewSession()
+	ctx = pgxspecial.WithEditorRunner(pgxspecial.WithSession(ctx, session), recordingEditor(&content, &line, ""))
+
+	res, _, err := pgxspecial.ExecuteSpecialCommand(ctx, db, "\\ef edit_me(integer) 2")
+	require.NoError(t, err)
+	assert.Contains(t, content, "CREATE OR REPLACE FUNCTION public.edit_me(a integer)\n")
+	// line 2 of the body, after the header lines
+	assert.Equal(t, 5, line)
+	assert.Equal(t, session.QueryBuffer().String(), res.(pgxspecial.QueryBufferResult).Text)
+}
+
+func TestEditViewDefinition(t *testing.T) {
+	db := connectTestDB(t)
+	defer db.(*pgxpool.Pool).Close()
+
+	ctx := context.Background()
+	_, err := db.(*pgxpool.Pool).Exec(ctx, `CREATE OR REPLACE VIEW edit_view WITH (security_barrier) AS
+		SELECT 1 AS one WHERE true WITH LOCAL CHECK OPTION`)
+	require.NoError(t, err)
+	defer db.(*pgxpool.Pool).Exec(ctx, "DROP VIEW IF EXISTS edit_view")
+
+	var content string
+	var line int
+	ctx = pgxspecial.WithEditorRunner(ctx, recordingEditor(&content, &line, ""))
+
+	_, _, err = pgxspecial.ExecuteSpecialCommand(ctx, db, "\\ev edit_view 3")
+	require.NoError(t, err)
+	assert.Equal(t, "CREATE OR REPLACE VIEW public.edit_view\n"+
+		" WITH (security_barrier='true') AS\n"+
+		" SELECT 1 AS one\n"+
+		"  WHERE true\n"+
+		" WITH LOCAL CHECK OPTION\n", content)
+	// lines are numbered from the CREATE line, as \sv+ shows them
+	assert.Equal(t, 3, line)
+
+	// psql adjusts the line number of \ef only; line 1 of \ev is the
+	// CREATE line even when the query starts on the next one
+	_, err = db.(*pgxpool.Pool).Exec(ctx, "CREATE OR REPLACE VIEW edit_plain_view AS SELECT 1 AS one")
+	require.NoError(t, err)
+	defer db.(*pgxpool.Pool).Exec(ctx, "DROP VIEW IF EXISTS edit_plain_view")
+	_, _, err = pgxspecial.ExecuteSpecialCommand(ctx, db, "\\ev edit_plain_view 1")
+	require.NoError(t, err)
+	assert.Equal(t, "CREATE OR REPLACE VIEW public.edit_plain_view AS\n SELECT 1 AS one\n", content)
+	assert.Equal(t, 1, line)
+
+	_, _, err = pgxspecial.ExecuteSpecialCommand(ctx, db, "\\ev pg_catalog.pg_class")
+	assert.EqualError(t, err, `"pg_catalog.pg_class" is not a view`)
+}
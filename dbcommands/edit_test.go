@@ -0,0 +1,147 @@
+package dbcommands_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/balaji01-4d/pgxspecial"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrintAndResetQueryBuffer(t *testing.T) {
+	ctx, session := withQueryBuffer("select 1")
+
+	res, _, err := pgxspecial.ExecuteSpecialCommand(ctx, nil, "\\p")
+	require.NoError(t, err)
+	assert.Equal(t, pgxspecial.QueryBufferResult{Text: "select 1"}, res)
+
+	res, _, err = pgxspecial.ExecuteSpecialCommand(ctx, nil, "\\r")
+	require.NoError(t, err)
+	assert.Equal(t, pgxspecial.StatusResult{Message: "Query buffer reset (cleared)."}, res)
+	assert.Empty(t, session.QueryBuffer().String())
+
+	res, _, err = pgxspecial.ExecuteSpecialCommand(ctx, nil, "\\p")
+	require.NoError(t, err)
+	assert.Equal(t, pgxspecial.QueryBufferResult{}, res)
+
+	// an empty buffer shows the previous query
+	session.QueryBuffer().Set("select 2")
+	session.QueryBuffer().Take()
+	res, _, err = pgxspecial.ExecuteSpecialCommand(ctx, nil, "\\print")
+	require.NoError(t, err)
+	assert.Equal(t, pgxspecial.QueryBufferResult{Text: "select 2"}, res)
+
+	_, _, err = pgxspecial.ExecuteSpecialCommand(context.Background(), nil, "\\r")
+	assert.ErrorIs(t, err, pgxspecial.ErrNoSession)
+}
+
+func TestWriteQueryBuffer(t *testing.T) {
+	dir := t.TempDir()
+	ctx, _ := withQueryBuffer("select 1")
+
+	file := filepath.Join(dir, "query file.sql")
+	_, _, err := pgxspecial.ExecuteSpecialCommand(ctx, nil, "\\w '"+file+"'")
+	require.NoError(t, err)
+	data, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, "select 1\n", string(data))
+
+	piped := filepath.Join(dir, "piped.sql")
+	_, _, err = pgxspecial.ExecuteSpecialCommand(ctx, nil, "\\w |tr a-z A-Z > "+piped)
+	require.NoError(t, err)
+	data, err = os.ReadFile(piped)
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT 1\n", string(data))
+
+	_, _, err = pgxspecial.ExecuteSpecialCommand(ctx, nil, "\\w")
+	assert.EqualError(t, err, "missing required argument")
+}
+
+func TestEditQueryBuffer(t *testing.T) {
+	type call struct {
+		content string
+		line    int
+	}
+	var calls []call
+	editor := func(ctx context.Context, file string, line int) error {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		calls = append(calls, call{string(data), line})
+		return os.WriteFile(file, []byte(strings.ToUpper(string(data))+"\n"), 0o644)
+	}
+
+	ctx, session := withQueryBuffer("select 1\nfrom t")
+	ctx = pgxspecial.WithEditorRunner(ctx, editor)
+
+	res, _, err := pgxspecial.ExecuteSpecialCommand(ctx, nil, "\\e 2")
+	require.NoError(t, err)
+	assert.Equal(t, pgxspecial.QueryBufferResult{Text: "SELECT 1\nFROM T"}, res)
+	assert.Equal(t, "SELECT 1\nFROM T", session.QueryBuffer().String())
+	assert.Equal(t, []call{{"select 1\nfrom t\n", 2}}, calls)
+
+	file := filepath.Join(t.TempDir(), "f.sql")
+	require.NoError(t, os.WriteFile(file, []byte("select 2\n"), 0o644))
+	res, _, err = pgxspecial.ExecuteSpecialCommand(ctx, nil, "\\edit "+file+" 3")
+	require.NoError(t, err)
+	assert.Equal(t, pgxspecial.QueryBufferResult{Text: "SELECT 2"}, res)
+	assert.Equal(t, call{"select 2\n", 3}, calls[1])
+
+	_, _, err = pgxspecial.ExecuteSpecialCommand(ctx, nil, "\\e "+file+" x")
+	assert.EqualError(t, err, "invalid line number: x")
+}
+
+func TestEditQueryBufferEnvironment(t *testing.T) {
+	dir := t.TempDir()
+	log := filepath.Join(dir, "args.log")
+	editor := filepath.Join(dir, "editor.sh")
+	// the fake editor records its arguments and replaces the file contents
+	script := "#!/bin/sh\necho \"$@\" > " + log + "\nfor f; do :; done\necho 'select 42;' > \"$f\"\n"
+	require.NoError(t, os.WriteFile(editor, []byte(script), 0o755))
+
+	t.Setenv("PSQL_EDITOR", editor)
+	t.Setenv("PSQL_EDITOR_LINENUMBER_ARG", "--line=")
+
+	ctx, session := withQueryBuffer("select 1")
+	res, _, err := pgxspecial.ExecuteSpecialCommand(ctx, nil, "\\e 7")
+	require.NoError(t, err)
+	assert.Equal(t, pgxspecial.QueryBufferResult{Text: "select 42;"}, res)
+	assert.Equal(t, "select 42;", session.QueryBuffer().String())
+
+	data, err := os.ReadFile(log)
+	require.NoError(t, err)
+	assert.Regexp(t, `^--line=7 .*psql\.edit\..*\.sql\n$`, string(data))
+
+	t.Setenv("PSQL_EDITOR", "exit 1;")
+	_, _, err = pgxspecial.ExecuteSpecialCommand(ctx, nil, "\\e")
+	assert.Error(t, err)
+	assert.Equal(t, "select 42;", session.QueryBuffer().String())
+}
+
+func TestEditQueryBufferInScript(t *testing.T) {
+	db := &fakeConn{results: map[string]*fakeRows{
+		"select 2": {columns: []string{"n"}, values: [][]*string{{text("2")}}, tag: "SELECT 1"},
+		"select 3": {columns: []string{"n"}, values: [][]*string{{text("3")}}, tag: "SELECT 1"},
+		"select 4": {columns: []string{"n"}, values: [][]*string{{text("4")}}, tag: "SELECT 1"},
+	}}
+	editor := func(ctx context.Context, file string, line int) error {
+		return os.WriteFile(file, []byte("select 2;\nselect 3"), 0o644)
+	}
+	ctx := pgxspecial.WithEditorRunner(pgxspecial.WithSession(context.Background(), pgxspecial.NewSession()), editor)
+
+	// the edited buffer is read again as input, so its complete statement
+	// runs and the rest continues with the following line
+	results, err := pgxspecial.ExecuteScript(ctx, db, strings.NewReader("select 1 \\e\n;\nselect 4;"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"select 2", "select 3", "select 4"}, db.queries)
+	require.Len(t, results, 4)
+	for _, res := range results {
+		assert.NoError(t, res.Err)
+	}
+	assert.Equal(t, 3, results[3].Statement.Line)
+}
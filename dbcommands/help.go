@@ -0,0 +1,31 @@
+package dbcommands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/balaji01-4d/pgxspecial"
+	"github.com/balaji01-4d/pgxspecial/database"
+)
+
+func init() {
+	pgxspecial.MustRegisterCommand(pgxspecial.SpecialCommandRegistry{
+		Cmd:           "\\?",
+		Description:   "Show help on backslash commands.",
+		Syntax:        "\\? [commands]",
+		Group:         pgxspecial.GroupHelp,
+		Handler:       ShowHelp,
+		CaseSensitive: true,
+	})
+}
+
+// ShowHelp lists the commands of the registry executing the command, grouped
+// by help section. Only the "commands" topic is supported.
+func ShowHelp(ctx context.Context, db database.Queryer, topic string, opts pgxspecial.CommandOptions) (pgxspecial.SpecialCommandResult, error) {
+	if topic != "" && topic != "commands" {
+		return nil, fmt.Errorf("unrecognized help topic: %s", topic)
+	}
+
+	groups := pgxspecial.RegistryFromContext(ctx).ListCommands()
+	return pgxspecial.HelpResult{Groups: groups}, nil
+}
@@ -0,0 +1,73 @@
+package dbcommands_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/balaji01-4d/pgxspecial"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func findCommand(groups []pgxspecial.CommandGroup, group, cmd string) (pgxspecial.SpecialCommand, bool) {
+	for _, g := range groups {
+		if g.Name != group {
+			continue
+		}
+		for _, c := range g.Commands {
+			if c.Cmd == cmd {
+				return c, true
+			}
+		}
+	}
+	return pgxspecial.SpecialCommand{}, false
+}
+
+func TestShowHelp(t *testing.T) {
+	res, isSpecial, err := pgxspecial.ExecuteSpecialCommand(context.Background(), nil, "\\?")
+	require.NoError(t, err)
+	assert.True(t, isSpecial)
+	require.Equal(t, pgxspecial.ResultKindHelp, res.ResultKind())
+
+	groups := res.(pgxspecial.HelpResult).Groups
+	require.NotEmpty(t, groups)
+	assert.Equal(t, pgxspecial.GroupGeneral, groups[0].Name)
+	assert.Equal(t, pgxspecial.GroupHelp, groups[1].Name)
+
+	gx, ok := findCommand(groups, pgxspecial.GroupGeneral, "\\gx")
+	assert.True(t, ok)
+	assert.Equal(t, "\\gx [FILE]", gx.Syntax)
+
+	l, ok := findCommand(groups, pgxspecial.GroupInformational, "\\l")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"\\list"}, l.Alias)
+	assert.Equal(t, "\\l[+] [pattern]", l.Syntax)
+
+	dp, ok := findCommand(groups, pgxspecial.GroupInformational, "\\dp")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"\\z"}, dp.Alias)
+
+	_, ok = findCommand(groups, pgxspecial.GroupOperatingSystem, "\\!")
+	assert.True(t, ok)
+
+	// aliases are reported on their command, not as separate entries
+	_, ok = findCommand(groups, pgxspecial.GroupInformational, "\\list")
+	assert.False(t, ok)
+}
+
+func TestShowHelpUsesExecutingRegistry(t *testing.T) {
+	reg := pgxspecial.DefaultRegistry().Clone()
+	assert.True(t, reg.Unregister("\\!"))
+
+	res, _, err := reg.Execute(context.Background(), nil, "\\?")
+	require.NoError(t, err)
+
+	groups := res.(pgxspecial.HelpResult).Groups
+	_, ok := findCommand(groups, pgxspecial.GroupOperatingSystem, "\\!")
+	assert.False(t, ok)
+}
+
+func TestShowHelpUnknownTopic(t *testing.T) {
+	_, _, err := pgxspecial.ExecuteSpecialCommand(context.Background(), nil, "\\? nonsense")
+	assert.Error(t, err)
+}
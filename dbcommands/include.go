@@ -0,0 +1,78 @@
+package dbcommands
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/balaji01-4d/pgxspecial"
+	"github.com/balaji01-4d/pgxspecial/database"
+)
+
+func init() {
+	pgxspecial.MustRegisterCommand(pgxspecial.SpecialCommandRegistry{
+		Cmd:           "\\i",
+		Alias:         []string{"\\include"},
+		Description:   "Execute commands from file.",
+		Group:         pgxspecial.GroupInputOutput,
+		Syntax:        "\\i FILE",
+		Handler:       IncludeFile,
+		Categories:    []string{pgxspecial.CategoryFilesystem},
+		CaseSensitive: true,
+	})
+
+	pgxspecial.MustRegisterCommand(pgxspecial.SpecialCommandRegistry{
+		Cmd:           "\\ir",
+		Alias:         []string{"\\include_relative"},
+		Description:   "As \\i, but relative to location of current script.",
+		Group:         pgxspecial.GroupInputOutput,
+		Syntax:        "\\ir FILE",
+		Handler:       IncludeRelativeFile,
+		Categories:    []string{pgxspecial.CategoryFilesystem},
+		CaseSensitive: true,
+	})
+}
+
+// IncludeFile executes the script in the named file with the registry
+// executing the command. Relative paths are resolved against the working
+// directory.
+func IncludeFile(ctx context.Context, db database.Queryer, args string, opts pgxspecial.CommandOptions) (pgxspecial.SpecialCommandResult, error) {
+	path, err := includePath(args)
+	if err != nil {
+		return nil, err
+	}
+	return includeScript(ctx, db, path)
+}
+
+// IncludeRelativeFile is like IncludeFile, but resolves relative paths
+// against the directory of the script containing the command. Outside of a
+// script file it behaves like IncludeFile.
+func IncludeRelativeFile(ctx context.Context, db database.Queryer, args string, opts pgxspecial.CommandOptions) (pgxspecial.SpecialCommandResult, error) {
+	path, err := includePath(args)
+	if err != nil {
+		return nil, err
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(pgxspecial.ScriptDir(ctx), path)
+	}
+	return includeScript(ctx, db, path)
+}
+
+func includePath(args string) (string, error) {
+	words, err := splitArgs(args, nil)
+	if err != nil {
+		return "", err
+	}
+	if len(words) == 0 {
+		return "", fmt.Errorf("missing required argument")
+	}
+	return words[0], nil
+}
+
+func includeScript(ctx context.Context, db database.Queryer, path string) (pgxspecial.SpecialCommandResult, error) {
+	results, err := pgxspecial.RegistryFromContext(ctx).ExecuteFile(ctx, db, path)
+	if results == nil && err != nil {
+		return nil, err
+	}
+	return pgxspecial.ScriptResults{Results: results}, err
+}
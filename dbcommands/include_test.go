@@ -0,0 +1,87 @@
+package dbcommands_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/balaji01-4d/pgxspecial"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeScripts(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+	return dir
+}
+
+func TestIncludeRelativeFile(t *testing.T) {
+	dir := writeScripts(t, map[string]string{
+		"main.sql":          "\\set order main\n\\ir lib/first.sql\n\\set order :order,main",
+		"lib/first.sql":     "\\set order :order,first\n\\ir second.sql",
+		"lib/second.sql":    "\\set order :order,second",
+		"lib/unrelated.sql": "\\set order wrong",
+	})
+
+	session := pgxspecial.NewSession()
+	ctx := pgxspecial.WithSession(context.Background(), session)
+
+	res, _, err := pgxspecial.ExecuteSpecialCommand(ctx, nil, "\\i "+filepath.Join(dir, "main.sql"))
+	require.NoError(t, err)
+	require.Equal(t, pgxspecial.ResultKindScript, res.ResultKind())
+
+	order, _ := session.Get("order")
+	assert.Equal(t, "main,first,second,main", order)
+
+	// included statements are reported individually with their file
+	results := res.(pgxspecial.ScriptResults).Results
+	require.Len(t, results, 4)
+	assert.Equal(t, filepath.Join(dir, "lib", "second.sql"), results[2].File)
+	assert.Equal(t, filepath.Join(dir, "main.sql"), results[3].File)
+	assert.Equal(t, 3, results[3].Statement.Line)
+}
+
+func TestIncludeFileErrors(t *testing.T) {
+	dir := writeScripts(t, map[string]string{
+		"a.sql":   "\\set x 1\n\\ir b.sql",
+		"b.sql":   "\\ir a.sql",
+		"bad.sql": "\\set ON_ERROR_STOP on\n\\set x 1\n\\unknown\n\\set x 2",
+		"top.sql": "\\ir bad.sql\n\\set x 3",
+	})
+	ctx := pgxspecial.WithSession(context.Background(), pgxspecial.NewSession())
+
+	res, _, err := pgxspecial.ExecuteSpecialCommand(ctx, nil, "\\i "+filepath.Join(dir, "a.sql"))
+	require.NoError(t, err)
+	results := res.(pgxspecial.ScriptResults).Results
+	require.Len(t, results, 2)
+	assert.ErrorIs(t, results[1].Err, pgxspecial.ErrIncludeCycle)
+
+	var scriptErr *pgxspecial.ScriptError
+	require.ErrorAs(t, results[1].Err, &scriptErr)
+	assert.Equal(t, filepath.Join(dir, "b.sql"), scriptErr.File)
+	assert.Equal(t, 1, scriptErr.Line)
+
+	// ON_ERROR_STOP aborts the including script too and reports the
+	// position of the failing statement
+	session := pgxspecial.NewSession()
+	ctx = pgxspecial.WithSession(context.Background(), session)
+	_, _, err = pgxspecial.ExecuteSpecialCommand(ctx, nil, "\\i "+filepath.Join(dir, "top.sql"))
+	require.ErrorAs(t, err, &scriptErr)
+	assert.Equal(t, filepath.Join(dir, "bad.sql"), scriptErr.File)
+	assert.Equal(t, 3, scriptErr.Line)
+	x, _ := session.Get("x")
+	assert.Equal(t, "1", x)
+
+	_, _, err = pgxspecial.ExecuteSpecialCommand(ctx, nil, "\\i "+filepath.Join(dir, "missing.sql"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	_, _, err = pgxspecial.ExecuteSpecialCommand(ctx, nil, "\\i")
+	assert.EqualError(t, err, "missing required argument")
+}
@@ -2,7 +2,6 @@ package dbcommands
 
 import (
 	"context"
-	"strconv"
 	"strings"
 
 	"github.com/balaji01-4d/pgxspecial"
@@ -10,31 +9,66 @@ import (
 )
 
 func init() {
-	pgxspecial.RegisterCommand(pgxspecial.SpecialCommandRegistry{
+	pgxspecial.MustRegisterCommand(pgxspecial.SpecialCommandRegistry{
 		Cmd:           "\\l",
 		Alias:         []string{"\\list"},
 		Description:   "List Databases",
+		Group:         pgxspecial.GroupInformational,
 		Syntax:        "\\l[+] [pattern]",
 		Handler:       ListDatabases,
 		CaseSensitive: true,
 	})
 }
 
-func ListDatabases(ctx context.Context, db database.Queryer, pattern string, verbose bool) (pgxspecial.SpecialCommandResult, error) {
+func ListDatabases(ctx context.Context, db database.Queryer, pattern string, opts pgxspecial.CommandOptions) (pgxspecial.SpecialCommandResult, error) {
+	patterns, err := parsePatterns(ctx, db, pattern, 1)
+	if err != nil {
+		return nil, err
+	}
+	version, err := pgxspecial.ServerVersion(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
 	var sb strings.Builder
 	args := []any{}
-	argIndex := 1
 
 	sb.WriteString(
 		`SELECT d.datname as name,
         pg_catalog.pg_get_userbyid(d.datdba) as owner,
         pg_catalog.pg_encoding_to_char(d.encoding) as encoding,
+		`)
+
+	// the locale provider is PG 15, the ICU locale became the locale of
+	// any provider in PG 17 and ICU rules are PG 16
+	if version >= 150000 {
+		sb.WriteString(`
+        CASE d.datlocprovider WHEN 'b' THEN 'builtin' WHEN 'c' THEN 'libc' WHEN 'i' THEN 'icu' END as locale_provider,
+		`)
+	}
+	sb.WriteString(`
         d.datcollate as collate,
         d.datctype as ctype,
+	`)
+	if version >= 170000 {
+		sb.WriteString(`
+        d.datlocale as locale,
+		`)
+	} else if version >= 150000 {
+		sb.WriteString(`
+        d.daticulocale as icu_locale,
+		`)
+	}
+	if version >= 160000 {
+		sb.WriteString(`
+        d.daticurules as icu_rules,
+		`)
+	}
+	sb.WriteString(`
         pg_catalog.array_to_string(d.datacl, E'\n') AS access_privileges
 		`)
 
-	if verbose {
+	if opts.Verbose {
 		sb.WriteString(
 			`, 
 			CASE WHEN pg_catalog.has_database_privilege(d.datname, 'CONNECT')
@@ -50,17 +84,13 @@ func ListDatabases(ctx context.Context, db database.Queryer, pattern string, ver
 	FROM pg_catalog.pg_database d
 	`)
 
-	if verbose {
+	if opts.Verbose {
 		sb.WriteString(`JOIN pg_catalog.pg_tablespace t on d.dattablespace = t.oid`)
 	}
 
-	if pattern != "" {
-		_, tablePattern := sqlNamePattern(pattern)
-
-		if tablePattern != "" {
-			args = append(args, tablePattern)
-		}
-		sb.WriteString("\nWHERE d.datname ~ $" + strconv.Itoa(argIndex) + " ")
+	filter, args := patternFilter(patterns, "", "d.datname ~ %[1]s", "", args)
+	if filter != "" {
+		sb.WriteString("\nWHERE " + filter + " ")
 	}
 
 	sb.WriteString("\nORDER BY 1;")
@@ -69,5 +99,5 @@ func ListDatabases(ctx context.Context, db database.Queryer, pattern string, ver
 		return nil, err
 	}
 
-	return pgxspecial.RowResult{Rows: res}, nil
+	return pgxspecial.RowResult{Rows: res, Title: "List of databases"}, nil
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"testing"
 
+	"github.com/balaji01-4d/pgxspecial"
 	"github.com/balaji01-4d/pgxspecial/dbcommands"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/stretchr/testify/assert"
@@ -16,7 +17,7 @@ func TestListDatabases(t *testing.T) {
 	pattern := ""
 	verbose := false
 
-	res, err := dbcommands.ListDatabases(context.Background(), db, pattern, verbose)
+	res, err := dbcommands.ListDatabases(context.Background(), db, pattern, pgxspecial.CommandOptions{Verbose: verbose})
 	if err != nil {
 		t.Fatalf("ListDatabases failed: %v", err)
 	}
@@ -28,17 +29,8 @@ func TestListDatabases(t *testing.T) {
 	fds := result.Rows.FieldDescriptions()
 	assert.NotNil(t, fds)
 
-	columnsExpected := []string{
-		"name",
-		"owner",
-		"encoding",
-		"collate",
-		"ctype",
-		"access_privileges",
-	}
+	columnsExpected := databaseColumns(serverVersion(t, db), false)
 	assert.Equal(t, columnsExpected, getColumnNames(fds), "Column names do not match expected")
-	// expecting 6 columns: Name Owner Encoding Collate Ctype Access privileges
-	assert.Len(t, fds, 6)
 
 	var allRows []map[string]interface{}
 	allRows, err = RowsToMaps(result.Rows)
@@ -57,7 +49,7 @@ func TestListDatabasesVerbose(t *testing.T) {
 	pattern := ""
 	verbose := true
 
-	res, err := dbcommands.ListDatabases(context.Background(), db, pattern, verbose)
+	res, err := dbcommands.ListDatabases(context.Background(), db, pattern, pgxspecial.CommandOptions{Verbose: verbose})
 	if err != nil {
 		t.Fatalf("ListDatabases failed: %v", err)
 	}
@@ -68,20 +60,8 @@ func TestListDatabasesVerbose(t *testing.T) {
 	fds := result.Rows.FieldDescriptions()
 	assert.NotNil(t, fds)
 
-	columnsExpected := []string{
-		"name",
-		"owner",
-		"encoding",
-		"collate",
-		"ctype",
-		"access_privileges",
-		"size",
-		"Tablespace",
-		"description",
-	}
+	columnsExpected := databaseColumns(serverVersion(t, db), true)
 	assert.Equal(t, columnsExpected, getColumnNames(fds), "Column names do not match expected")
-	// expecting 9 columns
-	assert.Len(t, fds, 9)
 
 	var allRows []map[string]interface{}
 	allRows, err = RowsToMaps(result.Rows)
@@ -100,7 +80,7 @@ func TestListDatabaseWithExactPattern(t *testing.T) {
 	pattern := "postgres"
 	verbose := false
 
-	res, err := dbcommands.ListDatabases(context.Background(), db, pattern, verbose)
+	res, err := dbcommands.ListDatabases(context.Background(), db, pattern, pgxspecial.CommandOptions{Verbose: verbose})
 	if err != nil {
 		t.Fatalf("ListDatabases failed: %v", err)
 	}
@@ -110,17 +90,8 @@ func TestListDatabaseWithExactPattern(t *testing.T) {
 	fds := result.Rows.FieldDescriptions()
 	assert.NotNil(t, fds)
 
-	columnsExpected := []string{
-		"name",
-		"owner",
-		"encoding",
-		"collate",
-		"ctype",
-		"access_privileges",
-	}
+	columnsExpected := databaseColumns(serverVersion(t, db), false)
 	assert.Equal(t, columnsExpected, getColumnNames(fds), "Column names do not match expected")
-	// expecting 6 columns: Name Owner Encoding Collate Ctype Access privileges
-	assert.Len(t, fds, 6)
 
 	var allRows []map[string]interface{}
 	allRows, err = RowsToMaps(result.Rows)
@@ -140,7 +111,7 @@ func TestListDatabaseWithPattern(t *testing.T) {
 	pattern := "templ*"
 	verbose := false
 
-	res, err := dbcommands.ListDatabases(context.Background(), db, pattern, verbose)
+	res, err := dbcommands.ListDatabases(context.Background(), db, pattern, pgxspecial.CommandOptions{Verbose: verbose})
 	if err != nil {
 		t.Fatalf("ListDatabases failed: %v", err)
 	}
@@ -151,17 +122,8 @@ func TestListDatabaseWithPattern(t *testing.T) {
 	fds := result.Rows.FieldDescriptions()
 	assert.NotNil(t, fds)
 
-	columnsExpected := []string{
-		"name",
-		"owner",
-		"encoding",
-		"collate",
-		"ctype",
-		"access_privileges",
-	}
+	columnsExpected := databaseColumns(serverVersion(t, db), false)
 	assert.Equal(t, columnsExpected, getColumnNames(fds), "Column names do not match expected")
-	// expecting 6 columns: Name Owner Encoding Collate Ctype Access privileges
-	assert.Len(t, fds, 6)
 
 	var allRows []map[string]interface{}
 	allRows, err = RowsToMaps(result.Rows)
@@ -181,7 +143,7 @@ func TestListDatabaseWithNoMatchingPattern(t *testing.T) {
 	pattern := "pastgres" // typo intentional
 	verbose := false
 
-	res, err := dbcommands.ListDatabases(context.Background(), db, pattern, verbose)
+	res, err := dbcommands.ListDatabases(context.Background(), db, pattern, pgxspecial.CommandOptions{Verbose: verbose})
 	if err != nil {
 		t.Fatalf("ListDatabases failed: %v", err)
 	}
@@ -193,17 +155,8 @@ func TestListDatabaseWithNoMatchingPattern(t *testing.T) {
 	fds := result.Rows.FieldDescriptions()
 	assert.NotNil(t, fds)
 
-	columnsExpected := []string{
-		"name",
-		"owner",
-		"encoding",
-		"collate",
-		"ctype",
-		"access_privileges",
-	}
+	columnsExpected := databaseColumns(serverVersion(t, db), false)
 	assert.Equal(t, columnsExpected, getColumnNames(fds), "Column names do not match expected")
-	// expecting 6 columns: Name Owner Encoding Collate Ctype Access privileges
-	assert.Len(t, fds, 6)
 
 	var allRows []map[string]interface{}
 	allRows, err = RowsToMaps(result.Rows)
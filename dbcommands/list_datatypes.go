@@ -2,7 +2,6 @@ package dbcommands
 
 import (
 	"context"
-	"strconv"
 	"strings"
 
 	"github.com/balaji01-4d/pgxspecial"
@@ -10,26 +9,26 @@ import (
 )
 
 func init() {
-	pgxspecial.RegisterCommand(pgxspecial.SpecialCommandRegistry{
+	pgxspecial.MustRegisterCommand(pgxspecial.SpecialCommandRegistry{
 		Cmd:           "\\dT",
 		Description:   "List data types",
-		Syntax:        "\\dT[+] [pattern]",
+		Group:         pgxspecial.GroupInformational,
+		Syntax:        "\\dT[S+] [pattern]",
 		Handler:       ListDatatypes,
 		CaseSensitive: true,
 	})
 }
 
-func ListDatatypes(ctx context.Context, db database.Queryer, pattern string, verbose bool) (pgxspecial.SpecialCommandResult, error) {
+func ListDatatypes(ctx context.Context, db database.Queryer, pattern string, opts pgxspecial.CommandOptions) (pgxspecial.SpecialCommandResult, error) {
 	var sb strings.Builder
 	args := []any{}
-	argIndex := 1
 
 	sb.WriteString(`
 SELECT n.nspname AS schema,
        pg_catalog.format_type(t.oid, NULL) AS name,
 `)
 
-	if verbose {
+	if opts.Verbose {
 		sb.WriteString(`
        t.typname AS internal_name,
        CASE
@@ -64,23 +63,16 @@ WHERE (t.typrelid = 0 OR
   )
 `)
 
-	schemaPattern, typePattern := sqlNamePattern(pattern)
-
-	if schemaPattern != "" {
-		sb.WriteString("  AND n.nspname ~ $" + strconv.Itoa(argIndex) + "\n")
-		args = append(args, schemaPattern)
-		argIndex++
-	} else {
-		sb.WriteString("  AND pg_catalog.pg_type_is_visible(t.oid)\n")
-	}
-
-	if typePattern != "" {
-		sb.WriteString("  AND (t.typname ~ $" + strconv.Itoa(argIndex) +
-			" OR pg_catalog.format_type(t.oid, NULL) ~ $" + strconv.Itoa(argIndex) + ")\n")
-		args = append(args, typePattern)
+	patterns, err := parsePatterns(ctx, db, pattern, 3)
+	if err != nil {
+		return nil, err
 	}
+	filter, args := patternFilter(patterns, "n.nspname ~ %[1]s",
+		"(t.typname ~ %[1]s OR pg_catalog.format_type(t.oid, NULL) ~ %[1]s)",
+		"pg_catalog.pg_type_is_visible(t.oid)", args)
+	sb.WriteString("  AND " + filter + "\n")
 
-	if schemaPattern == "" && typePattern == "" {
+	if !opts.System && len(patterns) == 0 {
 		sb.WriteString(`
   AND n.nspname <> 'pg_catalog'
   AND n.nspname <> 'information_schema'
@@ -91,5 +83,5 @@ WHERE (t.typrelid = 0 OR
 
 	rows, err := db.Query(ctx, sb.String(), args...)
 
-	return pgxspecial.RowResult{Rows: rows}, err
+	return pgxspecial.RowResult{Rows: rows, Title: "List of data types"}, err
 }
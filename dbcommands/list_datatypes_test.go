@@ -4,6 +4,7 @@ import (
 	"context"
 	"testing"
 
+	"github.com/balaji01-4d/pgxspecial"
 	"github.com/balaji01-4d/pgxspecial/dbcommands"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/stretchr/testify/assert"
@@ -24,7 +25,7 @@ func TestListDatatypes(t *testing.T) {
 	pattern := ""
 	verbose := false
 
-	res, err := dbcommands.ListDatatypes(context.Background(), db, pattern, verbose)
+	res, err := dbcommands.ListDatatypes(context.Background(), db, pattern, pgxspecial.CommandOptions{Verbose: verbose})
 	if err != nil {
 		t.Fatalf("ListDatatypes failed: %v", err)
 	}
@@ -68,7 +69,7 @@ func TestListDatatypesWithPattern(t *testing.T) {
 	pattern := "*_enum"
 	verbose := false
 
-	res, err := dbcommands.ListDatatypes(context.Background(), db, pattern, verbose)
+	res, err := dbcommands.ListDatatypes(context.Background(), db, pattern, pgxspecial.CommandOptions{Verbose: verbose})
 	if err != nil {
 		t.Fatalf("ListDatatypes failed: %v", err)
 	}
@@ -112,7 +113,7 @@ func TestListDatatypesWithNoMatchingPattern(t *testing.T) {
 	pattern := "type_xenum"
 	verbose := false
 
-	res, err := dbcommands.ListDatatypes(context.Background(), db, pattern, verbose)
+	res, err := dbcommands.ListDatatypes(context.Background(), db, pattern, pgxspecial.CommandOptions{Verbose: verbose})
 	if err != nil {
 		t.Fatalf("ListDatatypes failed: %v", err)
 	}
@@ -154,7 +155,7 @@ func TestListDatatypesVerbose(t *testing.T) {
 	pattern := ""
 	verbose := true
 
-	res, err := dbcommands.ListDatatypes(context.Background(), db, pattern, verbose)
+	res, err := dbcommands.ListDatatypes(context.Background(), db, pattern, pgxspecial.CommandOptions{Verbose: verbose})
 	if err != nil {
 		t.Fatalf("ListDatatypes failed: %v", err)
 	}
@@ -202,7 +203,7 @@ func TestListDatatypesVerboseWithPattern(t *testing.T) {
 	pattern := "*_enum"
 	verbose := true
 
-	res, err := dbcommands.ListDatatypes(context.Background(), db, pattern, verbose)
+	res, err := dbcommands.ListDatatypes(context.Background(), db, pattern, pgxspecial.CommandOptions{Verbose: verbose})
 	if err != nil {
 		t.Fatalf("ListDatatypes failed: %v", err)
 	}
@@ -247,7 +248,7 @@ func TestListDatatypesVerboseWithNoMatchingPattern(t *testing.T) {
 
 	pattern := "type_xenum"
 	verbose := true
-	res, err := dbcommands.ListDatatypes(context.Background(), db, pattern, verbose)
+	res, err := dbcommands.ListDatatypes(context.Background(), db, pattern, pgxspecial.CommandOptions{Verbose: verbose})
 	if err != nil {
 		t.Fatalf("ListDatatypes failed: %v", err)
 	}
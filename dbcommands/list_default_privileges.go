@@ -2,7 +2,6 @@ package dbcommands
 
 import (
 	"context"
-	"fmt"
 	"strings"
 
 	"github.com/balaji01-4d/pgxspecial"
@@ -10,16 +9,26 @@ import (
 )
 
 func init() {
-	pgxspecial.RegisterCommand(pgxspecial.SpecialCommandRegistry{
+	pgxspecial.MustRegisterCommand(pgxspecial.SpecialCommandRegistry{
 		Cmd:           "\\ddp",
 		Description:   "Lists default access privilege settings.",
+		Group:         pgxspecial.GroupInformational,
 		Syntax:        "\\ddp [pattern]",
 		Handler:       ListDefaultPrivileges,
 		CaseSensitive: true,
 	})
 }
 
-func ListDefaultPrivileges(ctx context.Context, db database.Queryer, pattern string, verbose bool) (pgxspecial.SpecialCommandResult, error) {
+func ListDefaultPrivileges(ctx context.Context, db database.Queryer, pattern string, opts pgxspecial.CommandOptions) (pgxspecial.SpecialCommandResult, error) {
+	patterns, err := parsePatterns(ctx, db, pattern, 1)
+	if err != nil {
+		return nil, err
+	}
+	version, err := pgxspecial.ServerVersion(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
 	var sb strings.Builder
 	args := []any{}
 
@@ -30,19 +39,26 @@ func ListDefaultPrivileges(ctx context.Context, db database.Queryer, pattern str
                          WHEN 'S' THEN 'sequence'
                          WHEN 'f' THEN 'function'
                          WHEN 'T' THEN 'type'
-                         WHEN 'n' THEN 'schema' END as type,
+                         WHEN 'n' THEN 'schema'
+	`)
+	// default privileges on large objects are PG 18
+	if version >= 180000 {
+		sb.WriteString(`
+                         WHEN 'L' THEN 'large object'
+		`)
+	}
+	sb.WriteString(`
+                         END as type,
     pg_catalog.array_to_string(d.defaclacl, E'\n') AS access_privileges
     FROM pg_catalog.pg_default_acl d
         LEFT JOIN pg_catalog.pg_namespace n ON n.oid = d.defaclnamespace
 	`)
-	if pattern != "" {
-		sb.WriteString(`
-		 WHERE (n.nspname OPERATOR(pg_catalog.~) $1 COLLATE pg_catalog.default
-            OR pg_catalog.pg_get_userbyid(d.defaclrole) OPERATOR(pg_catalog.~) $1 COLLATE pg_catalog.default)
-		`)
-		args = append(args, fmt.Sprintf("^(%s)$", pattern))
+	filter, args := patternFilter(patterns, "", `(n.nspname OPERATOR(pg_catalog.~) %[1]s COLLATE pg_catalog.default
+            OR pg_catalog.pg_get_userbyid(d.defaclrole) OPERATOR(pg_catalog.~) %[1]s COLLATE pg_catalog.default)`, "", args)
+	if filter != "" {
+		sb.WriteString(" WHERE " + filter + "\n")
 	}
 	sb.WriteString("ORDER BY 1, 2, 3;")
 	rows, err := db.Query(ctx, sb.String(), args...)
-	return pgxspecial.RowResult{Rows: rows}, err
+	return pgxspecial.RowResult{Rows: rows, Title: "Default access privileges"}, err
 }
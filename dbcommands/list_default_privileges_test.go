@@ -4,6 +4,7 @@ import (
 	"context"
 	"testing"
 
+	"github.com/balaji01-4d/pgxspecial"
 	"github.com/balaji01-4d/pgxspecial/dbcommands"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/stretchr/testify/assert"
@@ -25,7 +26,7 @@ func TestListDefaultPrivileges(t *testing.T) {
 
 	CreateDefaultPrivileges(t, context.Background(), db, "app_user")
 	defer DropDefaultPrivileges(t, context.Background(), db, "app_user")
-	res, err := dbcommands.ListDefaultPrivileges(context.Background(), db, pattern, false)
+	res, err := dbcommands.ListDefaultPrivileges(context.Background(), db, pattern, pgxspecial.CommandOptions{})
 	if err != nil {
 		t.Fatalf("ListDefaultPrivileges failed: %v", err)
 	}
@@ -2,7 +2,6 @@ package dbcommands
 
 import (
 	"context"
-	"strconv"
 	"strings"
 
 	"github.com/balaji01-4d/pgxspecial"
@@ -10,19 +9,28 @@ import (
 )
 
 func init() {
-	pgxspecial.RegisterCommand(pgxspecial.SpecialCommandRegistry{
-		Cmd: "\\dD",
-		Syntax: "\\dD[+] [pattern]",
-		Description: "List or describe domains.",
+	pgxspecial.MustRegisterCommand(pgxspecial.SpecialCommandRegistry{
+		Cmd:           "\\dD",
+		Syntax:        "\\dD[S+] [pattern]",
+		Description:   "List or describe domains.",
+		Group:         pgxspecial.GroupInformational,
 		CaseSensitive: true,
-		Handler: ListDomains,
+		Handler:       ListDomains,
 	})
 }
 
-func ListDomains(ctx context.Context, db database.Queryer, pattern string, verbose bool) (pgxspecial.SpecialCommandResult, error) {
+func ListDomains(ctx context.Context, db database.Queryer, pattern string, opts pgxspecial.CommandOptions) (pgxspecial.SpecialCommandResult, error) {
+	patterns, err := parsePatterns(ctx, db, pattern, 3)
+	if err != nil {
+		return nil, err
+	}
+	version, err := pgxspecial.ServerVersion(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
 	var sb strings.Builder
 	args := []any{}
-	argIndex := 1
 
 	sb.WriteString(`
 	        SELECT n.nspname AS schema,
@@ -46,10 +54,16 @@ func ListDomains(ctx context.Context, db database.Queryer, pattern string, verbo
                pg_catalog.array_to_string(ARRAY(
                  SELECT pg_catalog.pg_get_constraintdef(r.oid, TRUE)
                  FROM pg_catalog.pg_constraint AS r
-				WHERE t.oid = r.contypid), ' ') AS check 
+				WHERE t.oid = r.contypid`)
+	// domain NOT NULL constraints are catalogued since PG 17; they are
+	// shown as a modifier, not as a check
+	if version >= 170000 {
+		sb.WriteString(" AND r.contype = 'c'")
+	}
+	sb.WriteString(`), ' ') AS check 
 		`)
 
-	if verbose {
+	if opts.Verbose {
 		sb.WriteString(`,
 		pg_catalog.array_to_string(t.typacl, E'\n') AS access_privileges,
                d.description as description
@@ -59,7 +73,7 @@ func ListDomains(ctx context.Context, db database.Queryer, pattern string, verbo
 	        FROM pg_catalog.pg_type AS t
            LEFT JOIN pg_catalog.pg_namespace AS n ON n.oid = t.typnamespace`)
 
-	if verbose {
+	if opts.Verbose {
 		sb.WriteString(`
 		LEFT JOIN pg_catalog.pg_description d ON d.classoid = t.tableoid
                                                 AND d.objoid = t.oid AND d.objsubid = 0
@@ -67,26 +81,14 @@ func ListDomains(ctx context.Context, db database.Queryer, pattern string, verbo
 	}
 
 	sb.WriteString(` WHERE t.typtype = 'd' `)
-	if pattern != "" {
-		schemaRe, nameRe := sqlNamePattern(pattern)
-		if schemaRe != "" || nameRe != "" {
-			if schemaRe != "" {
-				sb.WriteString(" AND n.nspname ~ $" + strconv.Itoa(argIndex) + "\n")
-				args = append(args, schemaRe)
-				argIndex++
-			}
-			if nameRe != "" {
-				sb.WriteString(" AND t.typname ~ $" + strconv.Itoa(argIndex) + "\n")
-				args = append(args, nameRe)
-				argIndex++
-			}
-		} else {
-			sb.WriteString(`
+	filter, args := patternFilter(patterns, "n.nspname ~ %[1]s", "t.typname ~ %[1]s",
+		"pg_catalog.pg_type_is_visible(t.oid)", args)
+	sb.WriteString(" AND " + filter + "\n")
+	if !opts.System && len(patterns) == 0 {
+		sb.WriteString(`
 			AND n.nspname <> 'pg_catalog'
 			AND n.nspname <> 'information_schema'
-			AND pg_catalog.pg_type_is_visible(t.oid)
 			`)
-		}
 	}
 	sb.WriteString("ORDER BY 1, 2;")
 	rows, err := db.Query(ctx, sb.String(), args...)
@@ -94,5 +96,5 @@ func ListDomains(ctx context.Context, db database.Queryer, pattern string, verbo
 		return nil, err
 	}
 
-	return pgxspecial.RowResult{Rows: rows}, nil
+	return pgxspecial.RowResult{Rows: rows, Title: "List of domains"}, nil
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"testing"
 
+	"github.com/balaji01-4d/pgxspecial"
 	"github.com/balaji01-4d/pgxspecial/dbcommands"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/stretchr/testify/assert"
@@ -62,7 +63,7 @@ func TestListDomains(t *testing.T) {
 		CreateDomain(t, ctx, db.(*pgxpool.Pool), domain.name, domain.baseType)
 		defer DropDomain(t, ctx, db.(*pgxpool.Pool), domain.name)
 	}
-	res, err := dbcommands.ListDomains(context.Background(), db, pattern, verbose)
+	res, err := dbcommands.ListDomains(context.Background(), db, pattern, pgxspecial.CommandOptions{Verbose: verbose})
 	if err != nil {
 		t.Fatalf("ListDomains failed: %v", err)
 	}
@@ -115,7 +116,7 @@ func TestListDomainsWithPattern(t *testing.T) {
 		CreateDomain(t, ctx, db.(*pgxpool.Pool), domain.name, domain.baseType)
 		defer DropDomain(t, ctx, db.(*pgxpool.Pool), domain.name)
 	}
-	res, err := dbcommands.ListDomains(context.Background(), db, pattern, verbose)
+	res, err := dbcommands.ListDomains(context.Background(), db, pattern, pgxspecial.CommandOptions{Verbose: verbose})
 	if err != nil {
 		t.Fatalf("ListDomains failed: %v", err)
 	}
@@ -168,7 +169,7 @@ func TestListDomainsWithNoMatchingPattern(t *testing.T) {
 		CreateDomain(t, ctx, db.(*pgxpool.Pool), domain.name, domain.baseType)
 		defer DropDomain(t, ctx, db.(*pgxpool.Pool), domain.name)
 	}
-	res, err := dbcommands.ListDomains(context.Background(), db, pattern, verbose)
+	res, err := dbcommands.ListDomains(context.Background(), db, pattern, pgxspecial.CommandOptions{Verbose: verbose})
 	if err != nil {
 		t.Fatalf("ListDomains failed: %v", err)
 	}
@@ -218,7 +219,7 @@ func TestListDomainsVerbose(t *testing.T) {
 		CreateDomain(t, ctx, db.(*pgxpool.Pool), domain.name, domain.baseType)
 		defer DropDomain(t, ctx, db.(*pgxpool.Pool), domain.name)
 	}
-	res, err := dbcommands.ListDomains(context.Background(), db, pattern, verbose)
+	res, err := dbcommands.ListDomains(context.Background(), db, pattern, pgxspecial.CommandOptions{Verbose: verbose})
 	if err != nil {
 		t.Fatalf("ListDomains failed: %v", err)
 	}
@@ -273,7 +274,7 @@ func TestListDomainsVerboseWithPattern(t *testing.T) {
 		CreateDomain(t, ctx, db.(*pgxpool.Pool), domain.name, domain.baseType)
 		defer DropDomain(t, ctx, db.(*pgxpool.Pool), domain.name)
 	}
-	res, err := dbcommands.ListDomains(context.Background(), db, pattern, verbose)
+	res, err := dbcommands.ListDomains(context.Background(), db, pattern, pgxspecial.CommandOptions{Verbose: verbose})
 	if err != nil {
 		t.Fatalf("ListDomains failed: %v", err)
 	}
@@ -328,7 +329,7 @@ func TestListDomainsVerboseWithNoMatchingPattern(t *testing.T) {
 		CreateDomain(t, ctx, db.(*pgxpool.Pool), domain.name, domain.baseType)
 		defer DropDomain(t, ctx, db.(*pgxpool.Pool), domain.name)
 	}
-	res, err := dbcommands.ListDomains(context.Background(), db, pattern, verbose)
+	res, err := dbcommands.ListDomains(context.Background(), db, pattern, pgxspecial.CommandOptions{Verbose: verbose})
 	if err != nil {
 		t.Fatalf("ListDomains failed: %v", err)
 	}
@@ -385,7 +386,7 @@ func TestListDomainsWithSchema(t *testing.T) {
 		CreateDomain(t, ctx, db.(*pgxpool.Pool), domain.name, domain.baseType)
 		defer DropDomain(t, ctx, db.(*pgxpool.Pool), domain.name)
 	}
-	res, err := dbcommands.ListDomains(context.Background(), db, pattern, verbose)
+	res, err := dbcommands.ListDomains(context.Background(), db, pattern, pgxspecial.CommandOptions{Verbose: verbose})
 	if err != nil {
 		t.Fatalf("ListDomains failed: %v", err)
 	}
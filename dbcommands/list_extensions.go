@@ -2,7 +2,6 @@ package dbcommands
 
 import (
 	"context"
-	"strconv"
 	"strings"
 
 	"github.com/balaji01-4d/pgxspecial"
@@ -11,18 +10,19 @@ import (
 )
 
 func init() {
-	pgxspecial.RegisterCommand(pgxspecial.SpecialCommandRegistry{
+	pgxspecial.MustRegisterCommand(pgxspecial.SpecialCommandRegistry{
 		Cmd:           "\\dx",
 		Description:   "List extensions.",
+		Group:         pgxspecial.GroupInformational,
 		Syntax:        "\\dx[+] [pattern]",
 		Handler:       ListExtensions,
 		CaseSensitive: true,
 	})
 }
 
-func ListExtensions(ctx context.Context, db database.Queryer, pattern string, verbose bool) (pgxspecial.SpecialCommandResult, error) {
+func ListExtensions(ctx context.Context, db database.Queryer, pattern string, opts pgxspecial.CommandOptions) (pgxspecial.SpecialCommandResult, error) {
 
-	if verbose {
+	if opts.Verbose {
 		extensions, err := findExtension(ctx, db, pattern)
 		if err != nil {
 			return nil, err
@@ -67,13 +67,29 @@ func ListExtensions(ctx context.Context, db database.Queryer, pattern string, ve
 		return pgxspecial.ExtensionVerboseListResult{Results: extDescriptions}, nil
 	}
 
+	patterns, err := parsePatterns(ctx, db, pattern, 1)
+	if err != nil {
+		return nil, err
+	}
+	version, err := pgxspecial.ServerVersion(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
 	var sb strings.Builder
 	args := []any{}
-	argIndex := 1
 
 	sb.WriteString(`
 	 SELECT e.extname AS name,
              e.extversion AS version,
+	`)
+	// psql 18 shows the version CREATE EXTENSION would install
+	if version >= 180000 {
+		sb.WriteString(`
+             ae.default_version AS default_version,
+		`)
+	}
+	sb.WriteString(`
              n.nspname AS schema,
              c.description AS description
       FROM pg_catalog.pg_extension e
@@ -83,30 +99,37 @@ func ListExtensions(ctx context.Context, db database.Queryer, pattern string, ve
              ON c.objoid = e.oid
                 AND c.classoid = 'pg_catalog.pg_extension'::pg_catalog.regclass
 	`)
-
-	if pattern != "" {
-		_, tablePattern := sqlNamePattern(pattern)
-		sb.WriteString(" WHERE e.extname ~ $" + strconv.Itoa(argIndex) + " ")
-		args = append(args, tablePattern)
+	if version >= 180000 {
+		sb.WriteString(`
+           LEFT JOIN pg_catalog.pg_available_extensions() ae(name, default_version, comment)
+             ON ae.name = e.extname
+		`)
+	}
+	filter, args := patternFilter(patterns, "", "e.extname ~ %[1]s", "", args)
+	if filter != "" {
+		sb.WriteString(" WHERE " + filter + " ")
 	}
 
 	sb.WriteString(" ORDER BY 1, 2;")
 	rows, err := db.Query(ctx, sb.String(), args...)
-	return pgxspecial.RowResult{Rows: rows}, err
+	return pgxspecial.RowResult{Rows: rows, Title: "List of installed extensions"}, err
 }
 
-func findExtension(ctx context.Context, db database.Queryer, extName string) (pgx.Rows, error) {
+func findExtension(ctx context.Context, db database.Queryer, pattern string) (pgx.Rows, error) {
 	var sb strings.Builder
-	var args []any
 
 	sb.WriteString(`
 			SELECT e.extname, e.oid
             FROM pg_catalog.pg_extension e
 	`)
 
-	if extName != "" {
-		sb.WriteString(" WHERE e.extname = $1 ")
-		args = append(args, extName)
+	patterns, err := parsePatterns(ctx, db, pattern, 1)
+	if err != nil {
+		return nil, err
+	}
+	filter, args := patternFilter(patterns, "", "e.extname ~ %[1]s", "", nil)
+	if filter != "" {
+		sb.WriteString(" WHERE " + filter + " ")
 	}
 
 	sb.WriteString(" ORDER BY 1, 2;")
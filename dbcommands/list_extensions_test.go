@@ -17,7 +17,7 @@ func TestListExtensions(t *testing.T) {
 	pattern := ""
 	verbose := false
 
-	res, err := dbcommands.ListExtensions(context.Background(), db, pattern, verbose)
+	res, err := dbcommands.ListExtensions(context.Background(), db, pattern, pgxspecial.CommandOptions{Verbose: verbose})
 	if err != nil {
 		t.Fatalf("ListExtensions failed: %v", err)
 	}
@@ -30,14 +30,7 @@ func TestListExtensions(t *testing.T) {
 		t.Fatalf("FieldDescriptions is nil")
 	}
 
-	columnsExpected := []string{
-		"name",
-		"version",
-		"schema",
-		"description",
-	}
-	// expecting 4 columns
-	assert.Len(t, fds, 4, "Expected 4 columns")
+	columnsExpected := extensionColumns(serverVersion(t, db))
 	assert.Equal(t, columnsExpected, getColumnNames(fds), "Column names do not match expected")
 
 	var allRows []map[string]interface{}
@@ -56,7 +49,7 @@ func TestListExtensionsWithPattern(t *testing.T) {
 	pattern := "plpg*"
 	verbose := false
 
-	res, err := dbcommands.ListExtensions(context.Background(), db, pattern, verbose)
+	res, err := dbcommands.ListExtensions(context.Background(), db, pattern, pgxspecial.CommandOptions{Verbose: verbose})
 	if err != nil {
 		t.Fatalf("ListExtensions failed: %v", err)
 	}
@@ -69,14 +62,7 @@ func TestListExtensionsWithPattern(t *testing.T) {
 		t.Fatalf("FieldDescriptions is nil")
 	}
 
-	columnsExpected := []string{
-		"name",
-		"version",
-		"schema",
-		"description",
-	}
-	// expecting 4 columns
-	assert.Len(t, fds, 4, "Expected 4 columns")
+	columnsExpected := extensionColumns(serverVersion(t, db))
 	assert.Equal(t, columnsExpected, getColumnNames(fds), "Column names do not match expected")
 
 	var allRows []map[string]interface{}
@@ -95,7 +81,7 @@ func TestListExtensionsVerbose(t *testing.T) {
 	pattern := ""
 	verbose := true
 
-	res, err := dbcommands.ListExtensions(context.Background(), db, pattern, verbose)
+	res, err := dbcommands.ListExtensions(context.Background(), db, pattern, pgxspecial.CommandOptions{Verbose: verbose})
 	if err != nil {
 		t.Fatalf("ListExtensions failed: %v", err)
 	}
@@ -2,7 +2,6 @@ package dbcommands
 
 import (
 	"context"
-	"strconv"
 	"strings"
 
 	"github.com/balaji01-4d/pgxspecial"
@@ -10,19 +9,19 @@ import (
 )
 
 func init() {
-	pgxspecial.RegisterCommand(pgxspecial.SpecialCommandRegistry{
+	pgxspecial.MustRegisterCommand(pgxspecial.SpecialCommandRegistry{
 		Cmd:           "\\dE",
 		Description:   "List foreign tables.",
-		Syntax:        "\\dE[+] [pattern]",
+		Group:         pgxspecial.GroupInformational,
+		Syntax:        "\\dE[S+] [pattern]",
 		Handler:       ListForeignTables,
 		CaseSensitive: true,
 	})
 }
 
-func ListForeignTables(ctx context.Context, db database.Queryer, pattern string, verbose bool) (pgxspecial.SpecialCommandResult, error) {
+func ListForeignTables(ctx context.Context, db database.Queryer, pattern string, opts pgxspecial.CommandOptions) (pgxspecial.SpecialCommandResult, error) {
 	var sb strings.Builder
 	args := []any{}
-	argIndex := 1
 
 	sb.WriteString(`
 SELECT 
@@ -42,7 +41,7 @@ SELECT
     pg_catalog.pg_get_userbyid(c.relowner) AS owner
 `)
 
-	if verbose {
+	if opts.Verbose {
 		sb.WriteString(`
   , pg_catalog.pg_size_pretty(pg_catalog.pg_table_size(c.oid)) AS size
   , pg_catalog.obj_description(c.oid, 'pg_class') AS description
@@ -53,20 +52,29 @@ SELECT
 FROM pg_catalog.pg_class c
 LEFT JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
 WHERE c.relkind IN ('f','')
+  AND pg_catalog.pg_table_is_visible(c.oid)
+`)
+
+	if !opts.System {
+		sb.WriteString(`
   AND n.nspname <> 'pg_catalog'
   AND n.nspname <> 'information_schema'
   AND n.nspname !~ '^pg_toast'
-  AND pg_catalog.pg_table_is_visible(c.oid)
 `)
+	}
 
-	if pattern != "" {
-		_, tblPattern := sqlNamePattern(pattern)
-		sb.WriteString("  AND c.relname OPERATOR(pg_catalog.~) $" + strconv.Itoa(argIndex) + "\n")
-		args = append(args, tblPattern)
+	patterns, err := parsePatterns(ctx, db, pattern, 3)
+	if err != nil {
+		return nil, err
+	}
+	filter, args := patternFilter(patterns, "n.nspname OPERATOR(pg_catalog.~) %[1]s",
+		"c.relname OPERATOR(pg_catalog.~) %[1]s", "", args)
+	if filter != "" {
+		sb.WriteString("  AND " + filter + "\n")
 	}
 
 	sb.WriteString("ORDER BY 1,2;")
 
 	rows, err := db.Query(ctx, sb.String(), args...)
-	return pgxspecial.RowResult{Rows: rows}, err
+	return pgxspecial.RowResult{Rows: rows, Title: "List of relations"}, err
 }
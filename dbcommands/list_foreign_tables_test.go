@@ -4,6 +4,7 @@ import (
 	"context"
 	"testing"
 
+	"github.com/balaji01-4d/pgxspecial"
 	"github.com/balaji01-4d/pgxspecial/dbcommands"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/stretchr/testify/assert"
@@ -25,7 +26,7 @@ func TestListForeignTables(t *testing.T) {
 	pattern := ""
 	verbose := false
 
-	res, err := dbcommands.ListForeignTables(context.Background(), db, pattern, verbose)
+	res, err := dbcommands.ListForeignTables(context.Background(), db, pattern, pgxspecial.CommandOptions{Verbose: verbose})
 	if err != nil {
 		t.Fatalf("ListForeignTables failed: %v", err)
 	}
@@ -71,7 +72,7 @@ func TestListForeignTablesWithPattern(t *testing.T) {
 	pattern := "foreign_*"
 	verbose := false
 
-	res, err := dbcommands.ListForeignTables(context.Background(), db, pattern, verbose)
+	res, err := dbcommands.ListForeignTables(context.Background(), db, pattern, pgxspecial.CommandOptions{Verbose: verbose})
 	if err != nil {
 		t.Fatalf("ListForeignTables failed: %v", err)
 	}
@@ -114,7 +115,7 @@ func TestListForeignTablesWithNoMatchingPattern(t *testing.T) {
 	pattern := "foreign_x*"
 	verbose := false
 
-	res, err := dbcommands.ListForeignTables(context.Background(), db, pattern, verbose)
+	res, err := dbcommands.ListForeignTables(context.Background(), db, pattern, pgxspecial.CommandOptions{Verbose: verbose})
 	if err != nil {
 		t.Fatalf("ListForeignTables failed: %v", err)
 	}
@@ -157,7 +158,7 @@ func TestListForeignTablesVerbose(t *testing.T) {
 	pattern := ""
 	verbose := true
 
-	res, err := dbcommands.ListForeignTables(context.Background(), db, pattern, verbose)
+	res, err := dbcommands.ListForeignTables(context.Background(), db, pattern, pgxspecial.CommandOptions{Verbose: verbose})
 	if err != nil {
 		t.Fatalf("ListForeignTables failed: %v", err)
 	}
@@ -202,7 +203,7 @@ func TestListForeignTablesVerboseWithPattern(t *testing.T) {
 	pattern := "foreign_*"
 	verbose := true
 
-	res, err := dbcommands.ListForeignTables(context.Background(), db, pattern, verbose)
+	res, err := dbcommands.ListForeignTables(context.Background(), db, pattern, pgxspecial.CommandOptions{Verbose: verbose})
 	if err != nil {
 		t.Fatalf("ListForeignTables failed: %v", err)
 	}
@@ -247,7 +248,7 @@ func TestListForeignTablesVerboseWithNoMatchingPattern(t *testing.T) {
 	pattern := "foreign_x*"
 	verbose := true
 
-	res, err := dbcommands.ListForeignTables(context.Background(), db, pattern, verbose)
+	res, err := dbcommands.ListForeignTables(context.Background(), db, pattern, pgxspecial.CommandOptions{Verbose: verbose})
 	if err != nil {
 		t.Fatalf("ListForeignTables failed: %v", err)
 	}
@@ -2,7 +2,6 @@ package dbcommands
 
 import (
 	"context"
-	"strconv"
 	"strings"
 
 	"github.com/balaji01-4d/pgxspecial"
@@ -10,19 +9,28 @@ import (
 )
 
 func init() {
-	pgxspecial.RegisterCommand(pgxspecial.SpecialCommandRegistry{
+	pgxspecial.MustRegisterCommand(pgxspecial.SpecialCommandRegistry{
 		Cmd:           "\\df",
 		Description:   "List functions.",
-		Syntax:        "\\df[+] [pattern]",
+		Group:         pgxspecial.GroupInformational,
+		Syntax:        "\\df[S+] [pattern]",
 		Handler:       ListFunctions,
 		CaseSensitive: true,
 	})
 }
 
-func ListFunctions(ctx context.Context, db database.Queryer, pattern string, verbose bool) (pgxspecial.SpecialCommandResult, error) {
+func ListFunctions(ctx context.Context, db database.Queryer, pattern string, opts pgxspecial.CommandOptions) (pgxspecial.SpecialCommandResult, error) {
+	patterns, err := parsePatterns(ctx, db, pattern, 3)
+	if err != nil {
+		return nil, err
+	}
+	version, err := pgxspecial.ServerVersion(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
 	var sb strings.Builder
 	args := []any{}
-	argIndex := 1
 
 	sb.WriteString(`
 	            SELECT  n.nspname as schema,
@@ -32,15 +40,30 @@ func ListFunctions(ctx context.Context, db database.Queryer, pattern string, ver
                     pg_catalog.pg_get_function_arguments(p.oid)
                       as "Argument data types",
                      CASE
+	`)
+
+	// prokind replaced proisagg and proiswindow in PG 11, which added
+	// procedures
+	if version >= 110000 {
+		sb.WriteString(`
                         WHEN p.prokind = 'a' THEN 'agg'
                         WHEN p.prokind = 'w' THEN 'window'
+                        WHEN p.prokind = 'p' THEN 'proc'
+		`)
+	} else {
+		sb.WriteString(`
+                        WHEN p.proisagg THEN 'agg'
+                        WHEN p.proiswindow THEN 'window'
+		`)
+	}
+	sb.WriteString(`
                         WHEN p.prorettype = 'pg_catalog.trigger'::pg_catalog.regtype
                             THEN 'trigger'
                         ELSE 'normal'
                     END as type 
 	`)
 
-	if verbose {
+	if opts.Verbose {
 		sb.WriteString(`
 		 ,CASE
                  WHEN p.provolatile = 'i' THEN 'immutable'
@@ -49,7 +72,18 @@ func ListFunctions(ctx context.Context, db database.Queryer, pattern string, ver
             END as "Volatility",
             pg_catalog.pg_get_userbyid(p.proowner) as owner,
           l.lanname as "Language",
+		`)
+		// functions with an SQL-standard body have no prosrc since PG 14
+		if version >= 140000 {
+			sb.WriteString(`
+          COALESCE(pg_catalog.pg_get_function_sqlbody(p.oid), p.prosrc) as "Source code",
+			`)
+		} else {
+			sb.WriteString(`
           p.prosrc as "Source code",
+			`)
+		}
+		sb.WriteString(`
           pg_catalog.obj_description(p.oid, 'pg_proc') as description 
 		`)
 	}
@@ -59,7 +93,7 @@ func ListFunctions(ctx context.Context, db database.Queryer, pattern string, ver
             LEFT JOIN pg_catalog.pg_namespace n ON n.oid = p.pronamespace
 	`)
 
-	if verbose {
+	if opts.Verbose {
 		sb.WriteString(`
 		LEFT JOIN pg_catalog.pg_language l
 			ON l.oid = p.prolang
@@ -70,22 +104,11 @@ func ListFunctions(ctx context.Context, db database.Queryer, pattern string, ver
 	 WHERE  
 	`)
 
-	schemaPattern, funcPattern := sqlNamePattern(pattern)
-
-	if schemaPattern != "" {
-		sb.WriteString("  n.nspname ~ $" + strconv.Itoa(argIndex) + " ")
-		args = append(args, schemaPattern)
-		argIndex++
-	} else {
-		sb.WriteString(" pg_catalog.pg_function_is_visible(p.oid) ")
-	}
-
-	if funcPattern != "" {
-		sb.WriteString(" AND p.proname ~ $" + strconv.Itoa(argIndex) + " ")
-		args = append(args, funcPattern)
-	}
+	filter, args := patternFilter(patterns, "n.nspname ~ %[1]s", "p.proname ~ %[1]s",
+		"pg_catalog.pg_function_is_visible(p.oid)", args)
+	sb.WriteString(" " + filter + " ")
 
-	if !(schemaPattern != "" || funcPattern != "") {
+	if !opts.System && len(patterns) == 0 {
 		sb.WriteString(`
 		AND n.nspname <> 'pg_catalog'
 		AND n.nspname <> 'information_schema' 	
@@ -95,5 +118,5 @@ func ListFunctions(ctx context.Context, db database.Queryer, pattern string, ver
 	sb.WriteString(" ORDER BY 1, 2, 4;")
 
 	rows, err := db.Query(ctx, sb.String(), args...)
-	return pgxspecial.RowResult{Rows: rows}, err
+	return pgxspecial.RowResult{Rows: rows, Title: "List of functions"}, err
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"testing"
 
+	"github.com/balaji01-4d/pgxspecial"
 	"github.com/balaji01-4d/pgxspecial/dbcommands"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/stretchr/testify/assert"
@@ -24,7 +25,7 @@ func TestListFunctions(t *testing.T) {
 	pattern := ""
 	verbose := false
 
-	res, err := dbcommands.ListFunctions(context.Background(), db, pattern, verbose)
+	res, err := dbcommands.ListFunctions(context.Background(), db, pattern, pgxspecial.CommandOptions{Verbose: verbose})
 	if err != nil {
 		t.Fatalf("ListFunctions failed: %v", err)
 	}
@@ -70,7 +71,7 @@ func TestListFunctionsWithPattern(t *testing.T) {
 	pattern := "get_*"
 	verbose := false
 
-	res, err := dbcommands.ListFunctions(context.Background(), db, pattern, verbose)
+	res, err := dbcommands.ListFunctions(context.Background(), db, pattern, pgxspecial.CommandOptions{Verbose: verbose})
 	if err != nil {
 		t.Fatalf("ListFunctions failed: %v", err)
 	}
@@ -117,7 +118,7 @@ func TestListFunctionsWithNoMatchingPattern(t *testing.T) {
 	pattern := "fetch_*"
 	verbose := false
 
-	res, err := dbcommands.ListFunctions(context.Background(), db, pattern, verbose)
+	res, err := dbcommands.ListFunctions(context.Background(), db, pattern, pgxspecial.CommandOptions{Verbose: verbose})
 	if err != nil {
 		t.Fatalf("ListFunctions failed: %v", err)
 	}
@@ -162,7 +163,7 @@ func TestListFunctionsVerbose(t *testing.T) {
 	pattern := ""
 	verbose := true
 
-	res, err := dbcommands.ListFunctions(context.Background(), db, pattern, verbose)
+	res, err := dbcommands.ListFunctions(context.Background(), db, pattern, pgxspecial.CommandOptions{Verbose: verbose})
 	if err != nil {
 		t.Fatalf("ListFunctions failed: %v", err)
 	}
@@ -214,7 +215,7 @@ func TestListFunctionsVerboseWithPattern(t *testing.T) {
 	pattern := "get_*"
 	verbose := true
 
-	res, err := dbcommands.ListFunctions(context.Background(), db, pattern, verbose)
+	res, err := dbcommands.ListFunctions(context.Background(), db, pattern, pgxspecial.CommandOptions{Verbose: verbose})
 	if err != nil {
 		t.Fatalf("ListFunctions failed: %v", err)
 	}
@@ -266,7 +267,7 @@ func TestListFunctionsVerboseWithNoMatchingPattern(t *testing.T) {
 	pattern := "fetch_*"
 	verbose := true
 
-	res, err := dbcommands.ListFunctions(context.Background(), db, pattern, verbose)
+	res, err := dbcommands.ListFunctions(context.Background(), db, pattern, pgxspecial.CommandOptions{Verbose: verbose})
 	if err != nil {
 		t.Fatalf("ListFunctions failed: %v", err)
 	}
@@ -2,6 +2,7 @@ package dbcommands
 
 import (
 	"context"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -11,68 +12,85 @@ import (
 
 func init() {
 	// \dt
-	pgxspecial.RegisterCommand(pgxspecial.SpecialCommandRegistry{
+	pgxspecial.MustRegisterCommand(pgxspecial.SpecialCommandRegistry{
 		Cmd:         "\\dt",
 		Description: "List tables.",
-		Syntax:      "\\dt[+] [pattern]",
-		Handler: func(ctx context.Context, db database.Queryer, pattern string, verbose bool) (pgxspecial.SpecialCommandResult, error) {
-			return ListObjects(ctx, db, pattern, verbose, []string{"r", "p", ""})
+		Group:       pgxspecial.GroupInformational,
+		Syntax:      "\\dt[S+] [pattern]",
+		Handler: func(ctx context.Context, db database.Queryer, pattern string, opts pgxspecial.CommandOptions) (pgxspecial.SpecialCommandResult, error) {
+			return ListObjects(ctx, db, pattern, opts, []string{"r", "p", ""})
 		},
 		CaseSensitive: true,
 	})
 
 	// \dv
-	pgxspecial.RegisterCommand(pgxspecial.SpecialCommandRegistry{
+	pgxspecial.MustRegisterCommand(pgxspecial.SpecialCommandRegistry{
 		Cmd:         "\\dv",
 		Description: "List views.",
-		Syntax:      "\\dv[+] [pattern]",
-		Handler: func(ctx context.Context, db database.Queryer, pattern string, verbose bool) (pgxspecial.SpecialCommandResult, error) {
-			return ListObjects(ctx, db, pattern, verbose, []string{"v", "s", ""})
+		Group:       pgxspecial.GroupInformational,
+		Syntax:      "\\dv[S+] [pattern]",
+		Handler: func(ctx context.Context, db database.Queryer, pattern string, opts pgxspecial.CommandOptions) (pgxspecial.SpecialCommandResult, error) {
+			return ListObjects(ctx, db, pattern, opts, []string{"v", "s", ""})
 		},
 		CaseSensitive: true,
 	})
 
 	// \dm
-	pgxspecial.RegisterCommand(pgxspecial.SpecialCommandRegistry{
+	pgxspecial.MustRegisterCommand(pgxspecial.SpecialCommandRegistry{
 		Cmd:         "\\dm",
 		Description: "List materialized views.",
-		Syntax:      "\\dm[+] [pattern]",
-		Handler: func(ctx context.Context, db database.Queryer, pattern string, verbose bool) (pgxspecial.SpecialCommandResult, error) {
-			return ListObjects(ctx, db, pattern, verbose, []string{"m", "s", ""})
+		Group:       pgxspecial.GroupInformational,
+		Syntax:      "\\dm[S+] [pattern]",
+		Handler: func(ctx context.Context, db database.Queryer, pattern string, opts pgxspecial.CommandOptions) (pgxspecial.SpecialCommandResult, error) {
+			return ListObjects(ctx, db, pattern, opts, []string{"m", "s", ""})
 		},
 		CaseSensitive: true,
 	})
 
 	// \ds
-	pgxspecial.RegisterCommand(pgxspecial.SpecialCommandRegistry{
+	pgxspecial.MustRegisterCommand(pgxspecial.SpecialCommandRegistry{
 		Cmd:         "\\ds",
 		Description: "List sequences.",
-		Syntax:      "\\ds[+] [pattern]",
-		Handler: func(ctx context.Context, db database.Queryer, pattern string, verbose bool) (pgxspecial.SpecialCommandResult, error) {
-			return ListObjects(ctx, db, pattern, verbose, []string{"S", "s", ""})
+		Group:       pgxspecial.GroupInformational,
+		Syntax:      "\\ds[S+] [pattern]",
+		Handler: func(ctx context.Context, db database.Queryer, pattern string, opts pgxspecial.CommandOptions) (pgxspecial.SpecialCommandResult, error) {
+			return ListObjects(ctx, db, pattern, opts, []string{"S", "s", ""})
 		},
 		CaseSensitive: true,
 	})
 
 	// \di
-	pgxspecial.RegisterCommand(pgxspecial.SpecialCommandRegistry{
+	pgxspecial.MustRegisterCommand(pgxspecial.SpecialCommandRegistry{
 		Cmd:         "\\di",
 		Description: "List indexes.",
-		Syntax:      "\\di[+] [pattern]",
-		Handler: func(ctx context.Context, db database.Queryer, pattern string, verbose bool) (pgxspecial.SpecialCommandResult, error) {
-			return ListObjects(ctx, db, pattern, verbose, []string{"i", "s", ""})
+		Group:       pgxspecial.GroupInformational,
+		Syntax:      "\\di[S+] [pattern]",
+		Handler: func(ctx context.Context, db database.Queryer, pattern string, opts pgxspecial.CommandOptions) (pgxspecial.SpecialCommandResult, error) {
+			return ListObjects(ctx, db, pattern, opts, []string{"i", "s", ""})
 		},
 		CaseSensitive: true,
 	})
 }
 
-func ListObjects(ctx context.Context, db database.Queryer, pattern string, verbose bool, relkinds []string) (pgxspecial.SpecialCommandResult, error) {
+func ListObjects(ctx context.Context, db database.Queryer, pattern string, opts pgxspecial.CommandOptions, relkinds []string) (pgxspecial.SpecialCommandResult, error) {
+	patterns, err := parsePatterns(ctx, db, pattern, 3)
+	if err != nil {
+		return nil, err
+	}
+	version, err := pgxspecial.ServerVersion(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	// partitioned indexes are PG 11
+	if version >= 110000 && slices.Contains(relkinds, "i") {
+		relkinds = append(slices.Clone(relkinds), "I")
+	}
+
 	var sb strings.Builder
 	args := []any{}
 	argIndex := 1
 
-	schemaRe, tableRe := sqlNamePattern(pattern)
-
 	sb.WriteString(
 		`SELECT n.nspname as schema,
                     c.relname as name,
@@ -80,13 +98,23 @@ func ListObjects(ctx context.Context, db database.Queryer, pattern string, verbo
                       WHEN 'r' THEN 'table' WHEN 'v' THEN 'view'
                       WHEN 'p' THEN 'partitioned table'
                       WHEN 'm' THEN 'materialized view' WHEN 'i' THEN 'index'
+                      WHEN 'I' THEN 'partitioned index'
                       WHEN 'S' THEN 'sequence' WHEN 's' THEN 'special'
                       WHEN 'f' THEN 'foreign table' END
                     as type,
                     pg_catalog.pg_get_userbyid(c.relowner) as owner
 	`)
 
-	if verbose {
+	// table access methods are PG 12, shown for the relations having one
+	showAccessMethod := opts.Verbose && version >= 120000 &&
+		(slices.Contains(relkinds, "r") || slices.Contains(relkinds, "m") || slices.Contains(relkinds, "i"))
+	if showAccessMethod {
+		sb.WriteString(`
+		 ,am.amname as access_method
+	`)
+	}
+
+	if opts.Verbose {
 		sb.WriteString(`
 		 ,pg_catalog.pg_size_pretty(pg_catalog.pg_table_size(c.oid)) as size,
             pg_catalog.obj_description(c.oid, 'pg_class') as description 
@@ -97,31 +125,31 @@ func ListObjects(ctx context.Context, db database.Queryer, pattern string, verbo
 	FROM pg_catalog.pg_class c
 	LEFT JOIN pg_catalog.pg_namespace n
 	ON n.oid = c.relnamespace
+	`)
+	if showAccessMethod {
+		sb.WriteString(`
+	LEFT JOIN pg_catalog.pg_am am
+	ON am.oid = c.relam
+	`)
+	}
+	sb.WriteString(`
 	WHERE c.relkind = ANY($` + strconv.Itoa(argIndex) + `)
 	`)
 	args = append(args, relkinds)
-	argIndex++
 
-	if schemaRe != "" {
-		sb.WriteString("  AND n.nspname ~ $" + strconv.Itoa(argIndex) + "\n")
-		args = append(args, schemaRe)
-		argIndex++
-	} else {
-		sb.WriteString(`
-		AND n.nspname <> 'pg_catalog'
+	unqualified := "pg_catalog.pg_table_is_visible(c.oid)"
+	if !opts.System {
+		unqualified = `n.nspname <> 'pg_catalog'
 		AND n.nspname <> 'information_schema'
 		AND n.nspname !~ '^pg_toast'
-		AND pg_catalog.pg_table_is_visible(c.oid)
-		`)
+		AND ` + unqualified
 	}
 
-	if tableRe != "" {
-		sb.WriteString("  AND c.relname ~ $" + strconv.Itoa(argIndex) + "\n")
-		args = append(args, tableRe)
-	}
+	filter, args := patternFilter(patterns, "n.nspname ~ %[1]s", "c.relname ~ %[1]s", unqualified, args)
+	sb.WriteString("  AND " + filter + "\n")
 
 	sb.WriteString("ORDER BY 1, 2;")
 
 	rows, err := db.Query(ctx, sb.String(), args...)
-	return pgxspecial.RowResult{Rows: rows}, err
+	return pgxspecial.RowResult{Rows: rows, Title: "List of relations"}, err
 }
@@ -4,9 +4,11 @@ import (
 	"context"
 	"testing"
 
+	"github.com/balaji01-4d/pgxspecial"
 	"github.com/balaji01-4d/pgxspecial/dbcommands"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestListObjects(t *testing.T) {
@@ -27,7 +29,7 @@ func TestListObjects(t *testing.T) {
 	// "r" for ordinary table
 	relkinds := []string{"r"}
 
-	res, err := dbcommands.ListObjects(ctx, db, pattern, verbose, relkinds)
+	res, err := dbcommands.ListObjects(ctx, db, pattern, pgxspecial.CommandOptions{Verbose: verbose}, relkinds)
 	if err != nil {
 		t.Fatalf("ListObjects failed: %v", err)
 	}
@@ -43,12 +45,129 @@ func TestListObjects(t *testing.T) {
 	assert.True(t, containsByField(allRows, "name", "test_list_objects"))
 }
 
+func TestListObjectsMultiplePatterns(t *testing.T) {
+	db := connectTestDB(t).(*pgxpool.Pool)
+	defer db.Close()
+
+	ctx := context.Background()
+	for _, table := range []string{"multi_pattern_a", "multi_pattern_b", "multi_pattern_c"} {
+		if _, err := db.Exec(ctx, "CREATE TABLE IF NOT EXISTS "+table+" (id int)"); err != nil {
+			t.Fatal(err)
+		}
+		defer db.Exec(ctx, "DROP TABLE IF EXISTS "+table)
+	}
+
+	// overlapping patterns must not duplicate multi_pattern_a
+	res, _, err := pgxspecial.ExecuteSpecialCommand(ctx, db, "\\dt multi_pattern_a multi_pattern_[ab] public.multi_pattern_b")
+	if err != nil {
+		t.Fatalf("\\dt failed: %v", err)
+	}
+	allRows, err := RowsToMaps(RequiresRowResult(t, res).Rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []any
+	for _, row := range allRows {
+		names = append(names, row["name"])
+	}
+	assert.Equal(t, []any{"multi_pattern_a", "multi_pattern_b"}, names)
+}
+
+func TestListObjectsQualifiedPattern(t *testing.T) {
+	db := connectTestDB(t).(*pgxpool.Pool)
+	defer db.Close()
+
+	ctx := context.Background()
+	if _, err := db.Exec(ctx, "CREATE TABLE IF NOT EXISTS qualified_pattern (id int)"); err != nil {
+		t.Fatal(err)
+	}
+	defer db.Exec(ctx, "DROP TABLE IF EXISTS qualified_pattern")
+
+	var current string
+	if err := db.QueryRow(ctx, "SELECT current_database()").Scan(&current); err != nil {
+		t.Fatal(err)
+	}
+
+	res, _, err := pgxspecial.ExecuteSpecialCommand(ctx, db, "\\dt \""+current+"\".public.qualified_pattern")
+	if err != nil {
+		t.Fatalf("\\dt failed: %v", err)
+	}
+	allRows, err := RowsToMaps(RequiresRowResult(t, res).Rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, allRows, 1)
+
+	_, _, err = pgxspecial.ExecuteSpecialCommand(ctx, db, "\\dt no_such_db.public.qualified_pattern")
+	assert.ErrorIs(t, err, dbcommands.ErrCrossDatabaseReference)
+}
+
+func TestListObjectsQualifiedPatternNoExec(t *testing.T) {
+	db := &versionConn{version: 180000, database: "app"}
+	ctx := pgxspecial.WithEchoHidden(context.Background(), pgxspecial.EchoHiddenNoExec)
+
+	// checking the database qualifier is not the query shown
+	res, _, err := pgxspecial.ExecuteSpecialCommand(ctx, db, "\\dt app.public.users")
+	require.NoError(t, err)
+	echo := res.(pgxspecial.EchoHiddenResult)
+	require.Len(t, echo.Queries, 1)
+	assert.Contains(t, echo.Queries[0].SQL, "pg_catalog.pg_class")
+	assert.Empty(t, db.queries)
+
+	_, _, err = pgxspecial.ExecuteSpecialCommand(ctx, db, "\\dt other.public.users")
+	assert.ErrorIs(t, err, dbcommands.ErrCrossDatabaseReference)
+}
+
+func TestListObjectsInvalidPattern(t *testing.T) {
+	// malformed patterns are rejected before the database is queried
+	for _, cmd := range []string{"\\dt a.b.c.d", "\\df \"unterminated", "\\dn a.b.c", "\\du public.postgres", "\\l a.b"} {
+		_, _, err := pgxspecial.ExecuteSpecialCommand(context.Background(), nil, cmd)
+
+		var perr *dbcommands.PatternError
+		assert.ErrorAs(t, err, &perr, cmd)
+	}
+
+	_, _, err := pgxspecial.ExecuteSpecialCommand(context.Background(), nil, "\\dt a.b.c.d")
+	assert.ErrorIs(t, err, dbcommands.ErrTooManyDottedNames)
+	assert.EqualError(t, err, "improper qualified name (too many dotted names): a.b.c.d")
+}
+
+func TestListObjectsSystem(t *testing.T) {
+	db := connectTestDB(t).(*pgxpool.Pool)
+	defer db.Close()
+
+	ctx := context.Background()
+	relkinds := []string{"r", "p", ""}
+
+	res, err := dbcommands.ListObjects(ctx, db, "", pgxspecial.CommandOptions{}, relkinds)
+	if err != nil {
+		t.Fatalf("ListObjects failed: %v", err)
+	}
+	userRows, err := RowsToMaps(RequiresRowResult(t, res).Rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.False(t, containsByField(userRows, "schema", "pg_catalog"))
+
+	// \dtS includes the system catalogs
+	res, _, err = pgxspecial.ExecuteSpecialCommand(ctx, db, "\\dtS")
+	if err != nil {
+		t.Fatalf("\\dtS failed: %v", err)
+	}
+	systemRows, err := RowsToMaps(RequiresRowResult(t, res).Rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, containsByField(systemRows, "name", "pg_class"))
+}
+
 func TestListPrivilegesWithPattern(t *testing.T) {
 	db := connectTestDB(t)
 	defer db.(*pgxpool.Pool).Close()
 
 	pattern := "pg_catalog.pg_class" // A known system table
-	res, err := dbcommands.ListPrivileges(context.Background(), db, pattern, false)
+	res, err := dbcommands.ListPrivileges(context.Background(), db, pattern, pgxspecial.CommandOptions{})
 	if err != nil {
 		t.Fatalf("ListPrivileges with pattern failed: %v", err)
 	}
@@ -68,12 +187,11 @@ func TestListDefaultPrivilegesWithPattern(t *testing.T) {
 	// Setup a specific role/privilege to query against if needed,
 	// or just test the query generation logic with a pattern.
 	pattern := "public"
-	res, err := dbcommands.ListDefaultPrivileges(context.Background(), db, pattern, false)
-	result := RequiresRowResult(t, res)
-
+	res, err := dbcommands.ListDefaultPrivileges(context.Background(), db, pattern, pgxspecial.CommandOptions{})
 	if err != nil {
 		t.Fatalf("ListDefaultPrivileges with pattern failed: %v", err)
 	}
+	result := RequiresRowResult(t, res)
 	defer result.Rows.Close()
 
 	_, err = RowsToMaps(result.Rows)
@@ -2,7 +2,6 @@ package dbcommands
 
 import (
 	"context"
-	"strconv"
 	"strings"
 
 	"github.com/balaji01-4d/pgxspecial"
@@ -10,20 +9,20 @@ import (
 )
 
 func init() {
-	pgxspecial.RegisterCommand(pgxspecial.SpecialCommandRegistry{
+	pgxspecial.MustRegisterCommand(pgxspecial.SpecialCommandRegistry{
 		Cmd:           "\\dp",
 		Alias:         []string{"\\z"},
 		Description:   "List privileges.",
-		Syntax:        "\\dp [pattern]",
+		Group:         pgxspecial.GroupInformational,
+		Syntax:        "\\dp[S] [pattern]",
 		Handler:       ListPrivileges,
 		CaseSensitive: true,
 	})
 }
 
-func ListPrivileges(ctx context.Context, db database.Queryer, pattern string, verbose bool) (pgxspecial.SpecialCommandResult, error) {
+func ListPrivileges(ctx context.Context, db database.Queryer, pattern string, opts pgxspecial.CommandOptions) (pgxspecial.SpecialCommandResult, error) {
 	var sb strings.Builder
 	args := []any{}
-	argIndex := 1
 
 	sb.WriteString(`
 	        SELECT n.nspname as schema,
@@ -75,23 +74,24 @@ func ListPrivileges(ctx context.Context, db database.Queryer, pattern string, ve
 		  WHERE c.relkind IN ('r','v','m','S','f','p')
 	`)
 
-	if pattern != "" {
-		schema, table := sqlNamePattern(pattern)
-		if table != "" {
-			sb.WriteString(" AND c.relname OPERATOR(pg_catalog.~) $" + strconv.Itoa(argIndex) + " COLLATE pg_catalog.default ")
-			args = append(args, table)
-			argIndex++
-		}
-		if schema != "" {
-			sb.WriteString(" AND n.nspname OPERATOR(pg_catalog.~) $" + strconv.Itoa(argIndex) + " COLLATE pg_catalog.default ")
-			args = append(args, schema)
-		}
+	patterns, err := parsePatterns(ctx, db, pattern, 3)
+	if err != nil {
+		return nil, err
+	}
+	if len(patterns) > 0 {
+		var filter string
+		filter, args = patternFilter(patterns,
+			"n.nspname OPERATOR(pg_catalog.~) %[1]s COLLATE pg_catalog.default",
+			"c.relname OPERATOR(pg_catalog.~) %[1]s COLLATE pg_catalog.default", "", args)
+		sb.WriteString(" AND " + filter + " ")
 	} else {
 		sb.WriteString(" AND pg_catalog.pg_table_is_visible(c.oid) ")
 	}
 
-	sb.WriteString("  AND n.nspname !~ '^pg_'")
+	if !opts.System {
+		sb.WriteString("  AND n.nspname !~ '^pg_'")
+	}
 	sb.WriteString(" ORDER BY 1, 2")
 	rows, err := db.Query(ctx, sb.String(), args...)
-	return pgxspecial.RowResult{Rows: rows}, err
+	return pgxspecial.RowResult{Rows: rows, Title: "Access privileges"}, err
 }
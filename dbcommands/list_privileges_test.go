@@ -4,6 +4,7 @@ import (
 	"context"
 	"testing"
 
+	"github.com/balaji01-4d/pgxspecial"
 	"github.com/balaji01-4d/pgxspecial/dbcommands"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/stretchr/testify/assert"
@@ -23,7 +24,7 @@ func TestListPrivileges(t *testing.T) {
 	GrantPrivilege(t, ctx, pool, "SELECT", "test_tbl", "test_user")
 	defer RevokePrivilege(t, ctx, pool, "SELECT", "test_tbl", "test_user")
 
-	res, err := dbcommands.ListPrivileges(context.Background(), db, pattern, false)
+	res, err := dbcommands.ListPrivileges(context.Background(), db, pattern, pgxspecial.CommandOptions{})
 	if err != nil {
 		t.Fatalf("ListPrivileges failed: %v", err)
 	}
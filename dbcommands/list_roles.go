@@ -2,7 +2,6 @@ package dbcommands
 
 import (
 	"context"
-	"strconv"
 	"strings"
 
 	"github.com/balaji01-4d/pgxspecial"
@@ -10,19 +9,28 @@ import (
 )
 
 func init() {
-	pgxspecial.RegisterCommand(pgxspecial.SpecialCommandRegistry{
+	pgxspecial.MustRegisterCommand(pgxspecial.SpecialCommandRegistry{
 		Cmd:           "\\du",
 		Description:   "List roles.",
+		Group:         pgxspecial.GroupInformational,
 		Syntax:        "\\du[+] [pattern]",
 		Handler:       ListRoles,
 		CaseSensitive: true,
 	})
 }
 
-func ListRoles(ctx context.Context, db database.Queryer, pattern string, verbose bool) (pgxspecial.SpecialCommandResult, error) {
+func ListRoles(ctx context.Context, db database.Queryer, pattern string, opts pgxspecial.CommandOptions) (pgxspecial.SpecialCommandResult, error) {
+	patterns, err := parsePatterns(ctx, db, pattern, 1)
+	if err != nil {
+		return nil, err
+	}
+	version, err := pgxspecial.ServerVersion(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
 	var sb strings.Builder
 	args := []any{}
-	argIndex := 1
 
 	sb.WriteString(`
 	   SELECT r.rolname,
@@ -33,10 +41,17 @@ func ListRoles(ctx context.Context, db database.Queryer, pattern string, verbose
                 r.rolcanlogin,
                 r.rolconnlimit,
                 r.rolvaliduntil,
-                ARRAY(SELECT b.rolname FROM pg_catalog.pg_auth_members m JOIN pg_catalog.pg_roles b ON (m.roleid = b.oid) WHERE m.member = r.oid) as memberof,
 	`)
 
-	if verbose {
+	// psql 16 dropped the Member of column, as role memberships gained
+	// options that no longer fit in it
+	if version < 160000 {
+		sb.WriteString(`
+                ARRAY(SELECT b.rolname FROM pg_catalog.pg_auth_members m JOIN pg_catalog.pg_roles b ON (m.roleid = b.oid) WHERE m.member = r.oid) as memberof,
+		`)
+	}
+
+	if opts.Verbose {
 		sb.WriteString("pg_catalog.shobj_description(r.oid, 'pg_authid') AS description, ")
 	}
 	sb.WriteString(`
@@ -44,15 +59,12 @@ func ListRoles(ctx context.Context, db database.Queryer, pattern string, verbose
 			FROM pg_catalog.pg_roles r
 	`)
 
-	if pattern != "" {
-		_, tablePattern := sqlNamePattern(pattern)
-		if tablePattern != "" {
-			sb.WriteString(" WHERE r.rolname ~ $" + strconv.Itoa(argIndex) + " ")
-			args = append(args, tablePattern)
-		}
+	filter, args := patternFilter(patterns, "", "r.rolname ~ %[1]s", "", args)
+	if filter != "" {
+		sb.WriteString(" WHERE " + filter + " ")
 	}
 
 	sb.WriteString(" ORDER BY 1;")
 	rows, err := db.Query(ctx, sb.String(), args...)
-	return pgxspecial.RowResult{Rows: rows}, err
+	return pgxspecial.RowResult{Rows: rows, Title: "List of roles"}, err
 }
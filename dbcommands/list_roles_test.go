@@ -4,6 +4,7 @@ import (
 	"context"
 	"testing"
 
+	"github.com/balaji01-4d/pgxspecial"
 	"github.com/balaji01-4d/pgxspecial/dbcommands"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/stretchr/testify/assert"
@@ -16,7 +17,7 @@ func TestListRoles(t *testing.T) {
 	pattern := ""
 	verbose := false
 
-	res, err := dbcommands.ListRoles(context.Background(), db, pattern, verbose)
+	res, err := dbcommands.ListRoles(context.Background(), db, pattern, pgxspecial.CommandOptions{Verbose: verbose})
 	if err != nil {
 		t.Fatalf("ListRoles failed: %v", err)
 	}
@@ -27,21 +28,8 @@ func TestListRoles(t *testing.T) {
 	fds := result.Rows.FieldDescriptions()
 	assert.NotNil(t, fds)
 
-	columnsExpected := []string{
-		"rolname",
-		"rolsuper",
-		"rolinherit",
-		"rolcreaterole",
-		"rolcreatedb",
-		"rolcanlogin",
-		"rolconnlimit",
-		"rolvaliduntil",
-		"memberof",
-		"rolreplication",
-	}
+	columnsExpected := roleColumns(serverVersion(t, db), false)
 	assert.Equal(t, columnsExpected, getColumnNames(fds), "Column names do not match expected")
-	// expecting 10 columns
-	assert.Len(t, fds, 10)
 
 	var allRows []map[string]interface{}
 	allRows, err = RowsToMaps(result.Rows)
@@ -68,7 +56,7 @@ func TestListRolesWithPattern(t *testing.T) {
 	pattern := "pg_w*"
 	verbose := false
 
-	res, err := dbcommands.ListRoles(context.Background(), db, pattern, verbose)
+	res, err := dbcommands.ListRoles(context.Background(), db, pattern, pgxspecial.CommandOptions{Verbose: verbose})
 	if err != nil {
 		t.Fatalf("ListRoles failed: %v", err)
 	}
@@ -79,21 +67,8 @@ func TestListRolesWithPattern(t *testing.T) {
 	fds := result.Rows.FieldDescriptions()
 	assert.NotNil(t, fds)
 
-	columnsExpected := []string{
-		"rolname",
-		"rolsuper",
-		"rolinherit",
-		"rolcreaterole",
-		"rolcreatedb",
-		"rolcanlogin",
-		"rolconnlimit",
-		"rolvaliduntil",
-		"memberof",
-		"rolreplication",
-	}
+	columnsExpected := roleColumns(serverVersion(t, db), false)
 	assert.Equal(t, columnsExpected, getColumnNames(fds), "Column names do not match expected")
-	// expecting 10 columns
-	assert.Len(t, fds, 10)
 
 	var allRows []map[string]interface{}
 	allRows, err = RowsToMaps(result.Rows)
@@ -117,7 +92,7 @@ func TestListRolesWithNoMatchingPattern(t *testing.T) {
 	pattern := "pg_xwrite*" // intentional typo
 	verbose := false
 
-	res, err := dbcommands.ListRoles(context.Background(), db, pattern, verbose)
+	res, err := dbcommands.ListRoles(context.Background(), db, pattern, pgxspecial.CommandOptions{Verbose: verbose})
 	if err != nil {
 		t.Fatalf("ListRoles failed: %v", err)
 	}
@@ -128,21 +103,8 @@ func TestListRolesWithNoMatchingPattern(t *testing.T) {
 	fds := result.Rows.FieldDescriptions()
 	assert.NotNil(t, fds)
 
-	columnsExpected := []string{
-		"rolname",
-		"rolsuper",
-		"rolinherit",
-		"rolcreaterole",
-		"rolcreatedb",
-		"rolcanlogin",
-		"rolconnlimit",
-		"rolvaliduntil",
-		"memberof",
-		"rolreplication",
-	}
+	columnsExpected := roleColumns(serverVersion(t, db), false)
 	assert.Equal(t, columnsExpected, getColumnNames(fds), "Column names do not match expected")
-	// expecting 10 columns
-	assert.Len(t, fds, 10)
 
 	var allRows []map[string]interface{}
 	allRows, err = RowsToMaps(result.Rows)
@@ -159,7 +121,7 @@ func TestListRolesWithPatternVerbose(t *testing.T) {
 	pattern := "pg_w*"
 	verbose := true
 
-	res, err := dbcommands.ListRoles(context.Background(), db, pattern, verbose)
+	res, err := dbcommands.ListRoles(context.Background(), db, pattern, pgxspecial.CommandOptions{Verbose: verbose})
 	if err != nil {
 		t.Fatalf("ListRoles failed: %v", err)
 	}
@@ -170,22 +132,8 @@ func TestListRolesWithPatternVerbose(t *testing.T) {
 	fds := result.Rows.FieldDescriptions()
 	assert.NotNil(t, fds)
 
-	columnsExpected := []string{
-		"rolname",
-		"rolsuper",
-		"rolinherit",
-		"rolcreaterole",
-		"rolcreatedb",
-		"rolcanlogin",
-		"rolconnlimit",
-		"rolvaliduntil",
-		"memberof",
-		"description",
-		"rolreplication",
-	}
+	columnsExpected := roleColumns(serverVersion(t, db), true)
 	assert.Equal(t, columnsExpected, getColumnNames(fds), "Column names do not match expected")
-	// expecting 11 columns
-	assert.Len(t, fds, 11)
 
 	var allRows []map[string]interface{}
 	allRows, err = RowsToMaps(result.Rows)
@@ -209,7 +157,7 @@ func TestListRolesWithNoMatchingPatternVerbose(t *testing.T) {
 	pattern := "pg_xwrite*" // intentional typo
 	verbose := true
 
-	res, err := dbcommands.ListRoles(context.Background(), db, pattern, verbose)
+	res, err := dbcommands.ListRoles(context.Background(), db, pattern, pgxspecial.CommandOptions{Verbose: verbose})
 	if err != nil {
 		t.Fatalf("ListRoles failed: %v", err)
 	}
@@ -220,22 +168,8 @@ func TestListRolesWithNoMatchingPatternVerbose(t *testing.T) {
 	fds := result.Rows.FieldDescriptions()
 	assert.NotNil(t, fds)
 
-	columnsExpected := []string{
-		"rolname",
-		"rolsuper",
-		"rolinherit",
-		"rolcreaterole",
-		"rolcreatedb",
-		"rolcanlogin",
-		"rolconnlimit",
-		"rolvaliduntil",
-		"memberof",
-		"description",
-		"rolreplication",
-	}
+	columnsExpected := roleColumns(serverVersion(t, db), true)
 	assert.Equal(t, columnsExpected, getColumnNames(fds), "Column names do not match expected")
-	// expecting 11 columns
-	assert.Len(t, fds, 11)
 
 	var allRows []map[string]interface{}
 	allRows, err = RowsToMaps(result.Rows)
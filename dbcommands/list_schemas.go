@@ -2,7 +2,6 @@ package dbcommands
 
 import (
 	"context"
-	"strconv"
 	"strings"
 
 	"github.com/balaji01-4d/pgxspecial"
@@ -10,45 +9,46 @@ import (
 )
 
 func init() {
-	pgxspecial.RegisterCommand(pgxspecial.SpecialCommandRegistry{
+	pgxspecial.MustRegisterCommand(pgxspecial.SpecialCommandRegistry{
 		Cmd:           "\\dn",
 		Description:   "List schemas.",
-		Syntax:        "\\dn[+] [pattern]",
+		Group:         pgxspecial.GroupInformational,
+		Syntax:        "\\dn[S+] [pattern]",
 		Handler:       ListSchemas,
 		CaseSensitive: true,
 	})
 }
 
-func ListSchemas(ctx context.Context, db database.Queryer, pattern string, verbose bool) (pgxspecial.SpecialCommandResult, error) {
+func ListSchemas(ctx context.Context, db database.Queryer, pattern string, opts pgxspecial.CommandOptions) (pgxspecial.SpecialCommandResult, error) {
 	var sb strings.Builder
 	args := []any{}
-	argIndex := 1
 
 	sb.WriteString(`
 	SELECT n.nspname AS name, pg_catalog.pg_get_userbyid(n.nspowner) AS owner
 	`)
 
-	if verbose {
+	if opts.Verbose {
 		sb.WriteString(`
 		, pg_catalog.array_to_string(n.nspacl, E'\n') AS access_privileges, pg_catalog.obj_description(n.oid, 'pg_namespace') AS description
 		`)
 	}
-	sb.WriteString(`FROM pg_catalog.pg_namespace n WHERE n.nspname`)
+	sb.WriteString(`FROM pg_catalog.pg_namespace n `)
 
-	if pattern != "" {
-		_, tablePattern := sqlNamePattern(pattern)
-
-		if tablePattern != "" {
-			sb.WriteString("~ $" + strconv.Itoa(argIndex) + " ")
-			args = append(args, tablePattern)
-		}
-	} else {
+	patterns, err := parsePatterns(ctx, db, pattern, 2)
+	if err != nil {
+		return nil, err
+	}
+	if len(patterns) > 0 {
+		var filter string
+		filter, args = patternFilter(patterns, "", "n.nspname ~ %[1]s", "", args)
+		sb.WriteString("WHERE " + filter + " ")
+	} else if !opts.System {
 		sb.WriteString(`
-		!~ '^pg_' AND n.nspname <> 'information_schema'
+		WHERE n.nspname !~ '^pg_' AND n.nspname <> 'information_schema'
 		`)
 	}
 
 	sb.WriteString("ORDER BY 1")
 	rows, err := db.Query(ctx, sb.String(), args...)
-	return pgxspecial.RowResult{Rows: rows}, err
+	return pgxspecial.RowResult{Rows: rows, Title: "List of schemas"}, err
 }
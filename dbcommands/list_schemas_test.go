@@ -4,6 +4,7 @@ import (
 	"context"
 	"testing"
 
+	"github.com/balaji01-4d/pgxspecial"
 	"github.com/balaji01-4d/pgxspecial/dbcommands"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/stretchr/testify/assert"
@@ -22,7 +23,7 @@ func TestListSchemas(t *testing.T) {
 		defer DropSchema(t, context.Background(), db.(*pgxpool.Pool), schema)
 	}
 
-	res, err := dbcommands.ListSchemas(context.Background(), db, pattern, verbose)
+	res, err := dbcommands.ListSchemas(context.Background(), db, pattern, pgxspecial.CommandOptions{Verbose: verbose})
 	if err != nil {
 		t.Fatalf("ListSchemas failed: %v", err)
 	}
@@ -64,7 +65,7 @@ func TestListSchemasWithPattern(t *testing.T) {
 		defer DropSchema(t, context.Background(), db.(*pgxpool.Pool), schema)
 	}
 
-	res, err := dbcommands.ListSchemas(context.Background(), db, pattern, verbose)
+	res, err := dbcommands.ListSchemas(context.Background(), db, pattern, pgxspecial.CommandOptions{Verbose: verbose})
 	if err != nil {
 		t.Fatalf("ListSchemas failed: %v", err)
 	}
@@ -100,7 +101,7 @@ func TestListSchemasWithNoMatchingPattern(t *testing.T) {
 	pattern := "non_existing_schema"
 	verbose := false
 
-	res, err := dbcommands.ListSchemas(context.Background(), db, pattern, verbose)
+	res, err := dbcommands.ListSchemas(context.Background(), db, pattern, pgxspecial.CommandOptions{Verbose: verbose})
 	if err != nil {
 		t.Fatalf("ListSchemas failed: %v", err)
 	}
@@ -139,7 +140,7 @@ func TestListSchemasVerbose(t *testing.T) {
 		defer DropSchema(t, context.Background(), db.(*pgxpool.Pool), schema)
 	}
 
-	res, err := dbcommands.ListSchemas(context.Background(), db, pattern, verbose)
+	res, err := dbcommands.ListSchemas(context.Background(), db, pattern, pgxspecial.CommandOptions{Verbose: verbose})
 	if err != nil {
 		t.Fatalf("ListSchemas failed: %v", err)
 	}
@@ -180,7 +181,7 @@ func TestListSchemasWithPatternVerbose(t *testing.T) {
 		defer DropSchema(t, context.Background(), db.(*pgxpool.Pool), schema)
 	}
 
-	res, err := dbcommands.ListSchemas(context.Background(), db, pattern, verbose)
+	res, err := dbcommands.ListSchemas(context.Background(), db, pattern, pgxspecial.CommandOptions{Verbose: verbose})
 	if err != nil {
 		t.Fatalf("ListSchemas failed: %v", err)
 	}
@@ -214,7 +215,7 @@ func TestListSchemasWithNoMatchingPatternVerbose(t *testing.T) {
 
 	pattern := "non_existing_schema"
 	verbose := true
-	res, err := dbcommands.ListSchemas(context.Background(), db, pattern, verbose)
+	res, err := dbcommands.ListSchemas(context.Background(), db, pattern, pgxspecial.CommandOptions{Verbose: verbose})
 	if err != nil {
 		t.Fatalf("ListSchemas failed: %v", err)
 	}
@@ -2,7 +2,6 @@ package dbcommands
 
 import (
 	"context"
-	"strconv"
 	"strings"
 
 	"github.com/balaji01-4d/pgxspecial"
@@ -10,37 +9,36 @@ import (
 )
 
 func init() {
-	pgxspecial.RegisterCommand(pgxspecial.SpecialCommandRegistry{
+	pgxspecial.MustRegisterCommand(pgxspecial.SpecialCommandRegistry{
 		Cmd:           "\\db",
 		Description:   "List tablespaces.",
+		Group:         pgxspecial.GroupInformational,
 		Syntax:        "\\db[+] [pattern]",
 		Handler:       ListTablespaces,
 		CaseSensitive: true,
 	})
 }
 
-func ListTablespaces(ctx context.Context, db database.Queryer, pattern string, verbose bool) (pgxspecial.SpecialCommandResult, error) {
-	var sb strings.Builder
-	args := []any{}
-	argIndex := 1
-
-	var isLocationSupported bool
-	rows := db.QueryRow(ctx, `
-	  SELECT EXISTS (
-            SELECT * FROM pg_proc
-            WHERE proname = 'pg_tablespace_location'
-        )
-	`)
-	if err := rows.Scan(&isLocationSupported); err != nil {
+func ListTablespaces(ctx context.Context, db database.Queryer, pattern string, opts pgxspecial.CommandOptions) (pgxspecial.SpecialCommandResult, error) {
+	patterns, err := parsePatterns(ctx, db, pattern, 1)
+	if err != nil {
 		return nil, err
 	}
+	version, err := pgxspecial.ServerVersion(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	var sb strings.Builder
+	args := []any{}
 
 	sb.WriteString(`
 	SELECT
 		n.spcname AS name,
 		pg_catalog.pg_get_userbyid(n.spcowner) AS owner,
 	`)
-	if isLocationSupported {
+	// pg_tablespace_location is PG 9.2
+	if version >= 90200 {
 		sb.WriteString("    pg_catalog.pg_tablespace_location(n.oid) AS location\n")
 	} else {
 		sb.WriteString("    'Not supported' AS location\n")
@@ -50,16 +48,13 @@ func ListTablespaces(ctx context.Context, db database.Queryer, pattern string, v
 	FROM pg_catalog.pg_tablespace n
 	`)
 
-	if pattern != "" {
-		_, tablePattern := sqlNamePattern(pattern)
-		if tablePattern != "" {
-			sb.WriteString(" WHERE n.spcname ~ $" + strconv.Itoa(argIndex) + " COLLATE pg_catalog.default ")
-			args = append(args, tablePattern)
-		}
+	filter, args := patternFilter(patterns, "", "n.spcname ~ %[1]s COLLATE pg_catalog.default", "", args)
+	if filter != "" {
+		sb.WriteString(" WHERE " + filter + " ")
 	}
 
 	sb.WriteString(" ORDER BY 1;")
 	rowResult, err := db.Query(ctx, sb.String(), args...)
-	return pgxspecial.RowResult{Rows: rowResult}, err
+	return pgxspecial.RowResult{Rows: rowResult, Title: "List of tablespaces"}, err
 
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"testing"
 
+	"github.com/balaji01-4d/pgxspecial"
 	"github.com/balaji01-4d/pgxspecial/dbcommands"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/stretchr/testify/assert"
@@ -16,7 +17,7 @@ func TestListTablespaces(t *testing.T) {
 	pattern := ""
 	verbose := false
 
-	res, err := dbcommands.ListTablespaces(context.Background(), db, pattern, verbose)
+	res, err := dbcommands.ListTablespaces(context.Background(), db, pattern, pgxspecial.CommandOptions{Verbose: verbose})
 	if err != nil {
 		t.Fatalf("ListTablespaces failed: %v", err)
 	}
@@ -53,7 +54,7 @@ func TestListTablespacesWithPattern(t *testing.T) {
 	pattern := "pg_d*"
 	verbose := false
 
-	res, err := dbcommands.ListTablespaces(context.Background(), db, pattern, verbose)
+	res, err := dbcommands.ListTablespaces(context.Background(), db, pattern, pgxspecial.CommandOptions{Verbose: verbose})
 	if err != nil {
 		t.Fatalf("ListTablespaces failed: %v", err)
 	}
@@ -91,7 +92,7 @@ func TestListTablespacesWithInvalidPattern(t *testing.T) {
 	pattern := "pg_xd*"
 	verbose := false
 
-	res, err := dbcommands.ListTablespaces(context.Background(), db, pattern, verbose)
+	res, err := dbcommands.ListTablespaces(context.Background(), db, pattern, pgxspecial.CommandOptions{Verbose: verbose})
 	if err != nil {
 		t.Fatalf("ListTablespaces failed: %v", err)
 	}
@@ -0,0 +1,54 @@
+package dbcommands_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/balaji01-4d/pgxspecial"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSafePolicy(t *testing.T) {
+	db := &fakeConn{results: map[string]*fakeRows{
+		"select 1": {columns: []string{"n"}, values: [][]*string{{text("1")}}, tag: "SELECT 1"},
+	}}
+	ctx, session := withQueryBuffer("select 1")
+	ctx = pgxspecial.WithPolicy(ctx, pgxspecial.SafePolicy())
+	file := filepath.Join(t.TempDir(), "f.sql")
+
+	for _, cmd := range []string{
+		"\\! rm -rf /",
+		"\\e",
+		"\\ef f",
+		"\\ev v",
+		"\\i " + file,
+		"\\include_relative " + file,
+		"\\w " + file,
+		"\\g " + file,
+		"\\gx |cat",
+	} {
+		_, _, err := pgxspecial.ExecuteSpecialCommand(ctx, db, cmd)
+		assert.ErrorIs(t, err, pgxspecial.ErrPermissionDenied, cmd)
+	}
+	assert.Empty(t, db.queries)
+	assert.Equal(t, "select 1", session.QueryBuffer().String())
+
+	// commands without side effects outside the session and database run
+	res, _, err := pgxspecial.ExecuteSpecialCommand(ctx, db, "\\p")
+	require.NoError(t, err)
+	assert.Equal(t, pgxspecial.QueryBufferResult{Text: "select 1"}, res)
+	_, _, err = pgxspecial.ExecuteSpecialCommand(ctx, db, "\\g")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"select 1"}, db.queries)
+}
+
+func TestMutatingPolicy(t *testing.T) {
+	ctx, _ := withQueryBuffer("drop table t")
+	ctx = pgxspecial.WithPolicy(ctx, &pgxspecial.Policy{DenyCategories: []string{pgxspecial.CategoryMutating}})
+
+	for _, cmd := range []string{"\\g", "\\gx", "\\gset", "\\gexec"} {
+		_, _, err := pgxspecial.ExecuteSpecialCommand(ctx, nil, cmd)
+		assert.EqualError(t, err, "permission denied: "+cmd+" (mutating commands are not allowed)")
+	}
+}
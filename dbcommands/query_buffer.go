@@ -0,0 +1,300 @@
+package dbcommands
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/balaji01-4d/pgxspecial"
+	"github.com/balaji01-4d/pgxspecial/database"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func init() {
+	pgxspecial.MustRegisterCommand(pgxspecial.SpecialCommandRegistry{
+		Cmd:           "\\g",
+		Description:   "Execute query (and send result to file or |pipe).",
+		Group:         pgxspecial.GroupGeneral,
+		Syntax:        "\\g [FILE]",
+		Handler:       SendQueryBuffer,
+		Categories:    []string{pgxspecial.CategoryMutating},
+		CaseSensitive: true,
+	})
+
+	pgxspecial.MustRegisterCommand(pgxspecial.SpecialCommandRegistry{
+		Cmd:         "\\gx",
+		Description: "As \\g, but forces expanded output mode.",
+		Group:       pgxspecial.GroupGeneral,
+		Syntax:      "\\gx [FILE]",
+		Handler: func(ctx context.Context, db database.Queryer, args string, opts pgxspecial.CommandOptions) (pgxspecial.SpecialCommandResult, error) {
+			opts.Expanded = true
+			return SendQueryBuffer(ctx, db, args, opts)
+		},
+		Categories:    []string{pgxspecial.CategoryMutating},
+		CaseSensitive: true,
+	})
+
+	pgxspecial.MustRegisterCommand(pgxspecial.SpecialCommandRegistry{
+		Cmd:           "\\gset",
+		Description:   "Execute query and store result in psql variables.",
+		Group:         pgxspecial.GroupGeneral,
+		Syntax:        "\\gset [PREFIX]",
+		Handler:       StoreQueryResult,
+		Categories:    []string{pgxspecial.CategoryMutating},
+		CaseSensitive: true,
+	})
+
+	pgxspecial.MustRegisterCommand(pgxspecial.SpecialCommandRegistry{
+		Cmd:           "\\gexec",
+		Description:   "Execute query, then execute each value in its result.",
+		Group:         pgxspecial.GroupGeneral,
+		Syntax:        "\\gexec",
+		Handler:       ExecuteQueryResult,
+		Categories:    []string{pgxspecial.CategoryMutating},
+		CaseSensitive: true,
+	})
+
+	pgxspecial.MustRegisterCommand(pgxspecial.SpecialCommandRegistry{
+		Cmd:           "\\gdesc",
+		Description:   "Describe result of query, without executing it.",
+		Group:         pgxspecial.GroupGeneral,
+		Syntax:        "\\gdesc",
+		Handler:       DescribeQueryBuffer,
+		CaseSensitive: true,
+	})
+}
+
+// SendQueryBuffer sends the query buffer of the session carried by ctx, or
+// the previous query if the buffer is empty, and returns its rows. A file
+// name or "|command" argument is returned in RowResult.Output for the caller
+// to write the result to, if the policy carried by ctx allows writing files
+// or running shell commands; the x modifier (\gx) sets RowResult.Expanded.
+// The outcome is recorded in the session variables when the caller closes
+// the rows, once they have been read.
+func SendQueryBuffer(ctx context.Context, db database.Queryer, args string, opts pgxspecial.CommandOptions) (pgxspecial.SpecialCommandResult, error) {
+	output := args
+	if !strings.HasPrefix(args, "|") {
+		words, err := splitArgs(args, nil)
+		if err != nil {
+			return nil, err
+		}
+		output = ""
+		if len(words) > 0 {
+			output = words[0]
+		}
+	}
+	command := "\\g"
+	if opts.Expanded {
+		command = "\\gx"
+	}
+	if err := checkOutputTarget(ctx, command, output); err != nil {
+		return nil, err
+	}
+
+	session, query, err := takeQueryBuffer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := pgxspecial.UnwrapQueryer(db).Query(ctx, query, pgx.QueryExecModeSimpleProtocol)
+	if err != nil {
+		session.RecordResult(0, err)
+		return nil, err
+	}
+	rows = &recordRows{Rows: rows, session: session}
+	return pgxspecial.RowResult{Rows: rows, Expanded: opts.Expanded, Output: output}, nil
+}
+
+// recordRows records the outcome of the query in the session when the rows
+// are closed: only then are the command tag and any error known.
+type recordRows struct {
+	pgx.Rows
+	session  *pgxspecial.Session
+	recorded bool
+}
+
+func (r *recordRows) Close() {
+	r.Rows.Close()
+	if !r.recorded {
+		r.recorded = true
+		r.session.RecordResult(r.Rows.CommandTag().RowsAffected(), r.Rows.Err())
+	}
+}
+
+// StoreQueryResult sends the query buffer like SendQueryBuffer and stores
+// the columns of the single row it must return in session variables named
+// after the columns, with the optional prefix prepended. A NULL value unsets
+// its variable.
+func StoreQueryResult(ctx context.Context, db database.Queryer, args string, opts pgxspecial.CommandOptions) (pgxspecial.SpecialCommandResult, error) {
+	words, err := splitArgs(args, nil)
+	if err != nil {
+		return nil, err
+	}
+	var prefix string
+	if len(words) > 0 {
+		prefix = words[0]
+	}
+
+	session, query, err := takeQueryBuffer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	columns, values, tag, err := readTextRows(pgxspecial.UnwrapQueryer(db).Query(ctx, query, pgx.QueryExecModeSimpleProtocol))
+	switch {
+	case err != nil:
+	case len(values) == 0:
+		err = errors.New("no rows returned for \\gset")
+	case len(values) > 1:
+		err = errors.New("more than one row returned for \\gset")
+	}
+	session.RecordResult(tag.RowsAffected(), err)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, column := range columns {
+		name := prefix + column
+		if values[0][i] == nil {
+			err = session.Unset(name)
+		} else {
+			err = session.Set(name, *values[0][i])
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+// ExecuteQueryResult sends the query buffer like SendQueryBuffer, then
+// executes every non-NULL value of the result as an SQL statement, row by
+// row and from left to right. The values are not interpolated. As in a
+// script, a failed statement stops the execution only if ON_ERROR_STOP is
+// set.
+func ExecuteQueryResult(ctx context.Context, db database.Queryer, args string, opts pgxspecial.CommandOptions) (pgxspecial.SpecialCommandResult, error) {
+	session, query, err := takeQueryBuffer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	_, values, tag, err := readTextRows(pgxspecial.UnwrapQueryer(db).Query(ctx, query, pgx.QueryExecModeSimpleProtocol))
+	session.RecordResult(tag.RowsAffected(), err)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []pgxspecial.ScriptResult
+	for _, row := range values {
+		for _, value := range row {
+			if value == nil {
+				continue
+			}
+			res := pgxspecial.ScriptResult{
+				Statement: pgxspecial.Statement{Kind: pgxspecial.StatementSQL, Text: *value, Complete: true},
+			}
+			res.Result, res.Err = pgxspecial.ExecuteQuery(ctx, db, *value)
+			if res.Err != nil {
+				res.Result = nil
+			}
+			results = append(results, res)
+			if res.Err != nil && session.OnErrorStop() {
+				return pgxspecial.ScriptResults{Results: results}, res.Err
+			}
+		}
+	}
+	return pgxspecial.ScriptResults{Results: results}, nil
+}
+
+// DescribeQueryBuffer prepares the query buffer, or the previous query if
+// the buffer is empty, without executing it and lists the name and type of
+// its result columns. db must implement database.Preparer or be a
+// *pgxpool.Pool.
+func DescribeQueryBuffer(ctx context.Context, db database.Queryer, args string, opts pgxspecial.CommandOptions) (pgxspecial.SpecialCommandResult, error) {
+	_, query, err := takeQueryBuffer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// preparing the query of the user is not a hidden query
+	fields, err := describeStatement(ctx, pgxspecial.UnwrapQueryer(db), query)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(fields))
+	oids := make([]uint32, len(fields))
+	typmods := make([]int32, len(fields))
+	for i, fd := range fields {
+		names[i], oids[i], typmods[i] = fd.Name, fd.DataTypeOID, fd.TypeModifier
+	}
+
+	rows, err := db.Query(ctx, `
+		SELECT s.name AS "Column",
+		       pg_catalog.format_type(s.oid, s.typmod) AS "Type"
+		FROM unnest($1::pg_catalog.text[], $2::pg_catalog.oid[], $3::pg_catalog.int4[])
+		     WITH ORDINALITY AS s(name, oid, typmod, n)
+		ORDER BY s.n`, names, oids, typmods)
+	if err != nil {
+		return nil, err
+	}
+	return pgxspecial.RowResult{Rows: rows, Expanded: opts.Expanded}, nil
+}
+
+// takeQueryBuffer returns the session carried by ctx and the query to send
+// from its query buffer.
+func takeQueryBuffer(ctx context.Context) (*pgxspecial.Session, string, error) {
+	session := pgxspecial.SessionFromContext(ctx)
+	if session == nil {
+		return nil, "", pgxspecial.ErrNoSession
+	}
+	query, ok := session.QueryBuffer().Take()
+	if !ok {
+		return nil, "", pgxspecial.ErrEmptyQueryBuffer
+	}
+	return session, query, nil
+}
+
+// readTextRows reads rows to completion, returning the column names and the
+// values in text form, nil for NULL. Results of the simple protocol are
+// always in text form.
+func readTextRows(rows pgx.Rows, err error) ([]string, [][]*string, pgconn.CommandTag, error) {
+	if err != nil {
+		return nil, nil, pgconn.CommandTag{}, err
+	}
+	defer rows.Close()
+
+	var columns []string
+	for _, fd := range rows.FieldDescriptions() {
+		columns = append(columns, fd.Name)
+	}
+
+	var values [][]*string
+	for rows.Next() {
+		raw := rows.RawValues()
+		row := make([]*string, len(raw))
+		for i, v := range raw {
+			if v != nil {
+				s := string(v)
+				row[i] = &s
+			}
+		}
+		values = append(values, row)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, nil, pgconn.CommandTag{}, err
+	}
+	return columns, values, rows.CommandTag(), nil
+}
+
+// describeStatement returns the result columns of sql as reported by the
+// server when preparing it as the unnamed statement.
+func describeStatement(ctx context.Context, db database.Queryer, sql string) ([]pgconn.FieldDescription, error) {
+	sd, err := database.Prepare(ctx, db, "", sql)
+	if err != nil {
+		return nil, err
+	}
+	return sd.Fields, nil
+}
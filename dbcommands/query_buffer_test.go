@@ -0,0 +1,298 @@
+package dbcommands_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/balaji01-4d/pgxspecial"
+	_ "github.com/balaji01-4d/pgxspecial/dbcommands"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeConn answers queries from results, keyed by the query text, and
+// records the queries it receives. Unknown queries fail.
+type fakeConn struct {
+	results map[string]*fakeRows
+	fields  []pgconn.FieldDescription
+	queries []string
+	args    [][]any
+}
+
+func (c *fakeConn) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	c.queries = append(c.queries, sql)
+	c.args = append(c.args, args)
+	rows, ok := c.results[sql]
+	if !ok {
+		if strings.Contains(sql, "format_type") {
+			return &fakeRows{columns: []string{"Column", "Type"}}, nil
+		}
+		return nil, &pgconn.PgError{Severity: "ERROR", Code: "42601", Message: "syntax error"}
+	}
+	copied := *rows
+	return &copied, nil
+}
+
+func (c *fakeConn) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return nil
+}
+
+func (c *fakeConn) Prepare(ctx context.Context, name, sql string) (*pgconn.StatementDescription, error) {
+	c.queries = append(c.queries, "PREPARE "+sql)
+	return &pgconn.StatementDescription{Name: name, SQL: sql, Fields: c.fields}, nil
+}
+
+type fakeRows struct {
+	columns []string
+	values  [][]*string
+	tag     string
+	next    int
+}
+
+func (r *fakeRows) Close()                        {}
+func (r *fakeRows) Err() error                    { return nil }
+func (r *fakeRows) CommandTag() pgconn.CommandTag { return pgconn.NewCommandTag(r.tag) }
+func (r *fakeRows) Scan(dest ...any) error        { return errors.New("not implemented") }
+func (r *fakeRows) Conn() *pgx.Conn               { return nil }
+
+func (r *fakeRows) FieldDescriptions() []pgconn.FieldDescription {
+	fds := make([]pgconn.FieldDescription, len(r.columns))
+	for i, name := range r.columns {
+		fds[i].Name = name
+	}
+	return fds
+}
+
+func (r *fakeRows) Next() bool {
+	r.next++
+	return r.next <= len(r.values)
+}
+
+func (r *fakeRows) RawValues() [][]byte {
+	raw := make([][]byte, len(r.values[r.next-1]))
+	for i, v := range r.values[r.next-1] {
+		if v != nil {
+			raw[i] = []byte(*v)
+		}
+	}
+	return raw
+}
+
+func (r *fakeRows) Values() ([]any, error) {
+	values := make([]any, len(r.values[r.next-1]))
+	for i, v := range r.values[r.next-1] {
+		if v != nil {
+			values[i] = *v
+		}
+	}
+	return values, nil
+}
+
+func text(s string) *string {
+	return &s
+}
+
+func withQueryBuffer(text string) (context.Context, *pgxspecial.Session) {
+	session := pgxspecial.NewSession()
+	session.QueryBuffer().Set(text)
+	return pgxspecial.WithSession(context.Background(), session), session
+}
+
+func TestSendQueryBuffer(t *testing.T) {
+	db := &fakeConn{results: map[string]*fakeRows{
+		"select 1": {columns: []string{"?column?"}, values: [][]*string{{text("1")}}, tag: "SELECT 1"},
+	}}
+
+	ctx, session := withQueryBuffer("select 1")
+	res, _, err := pgxspecial.ExecuteSpecialCommand(ctx, db, "\\g")
+	require.NoError(t, err)
+	rows := res.(pgxspecial.RowResult)
+	assert.False(t, rows.Expanded)
+	assert.Empty(t, rows.Output)
+	assert.Empty(t, session.QueryBuffer().String())
+
+	// an empty buffer sends the previous query again
+	res, _, err = pgxspecial.ExecuteSpecialCommand(ctx, db, "\\gx 'out file.txt'")
+	require.NoError(t, err)
+	rows = res.(pgxspecial.RowResult)
+	assert.True(t, rows.Expanded)
+	assert.Equal(t, "out file.txt", rows.Output)
+
+	res, _, err = pgxspecial.ExecuteSpecialCommand(ctx, db, "\\g |grep 1 | wc -l")
+	require.NoError(t, err)
+	assert.Equal(t, "|grep 1 | wc -l", res.(pgxspecial.RowResult).Output)
+	assert.Equal(t, []string{"select 1", "select 1", "select 1"}, db.queries)
+
+	session.QueryBuffer().Set("bogus")
+	_, _, err = pgxspecial.ExecuteSpecialCommand(ctx, db, "\\g")
+	assert.Error(t, err)
+	sqlState, _ := session.Get(pgxspecial.VarSQLState)
+	assert.Equal(t, "42601", sqlState)
+
+	// a successful query is recorded once its rows are read and closed
+	session.QueryBuffer().Set("select 1")
+	res, _, err = pgxspecial.ExecuteSpecialCommand(ctx, db, "\\g")
+	require.NoError(t, err)
+	rows = res.(pgxspecial.RowResult)
+	for rows.Rows.Next() {
+	}
+	rows.Rows.Close()
+	errVar, _ := session.Get(pgxspecial.VarError)
+	assert.Equal(t, "false", errVar)
+	rowCount, _ := session.Get(pgxspecial.VarRowCount)
+	assert.Equal(t, "1", rowCount)
+}
+
+func TestSendQueryBufferErrors(t *testing.T) {
+	_, _, err := pgxspecial.ExecuteSpecialCommand(context.Background(), nil, "\\g")
+	assert.ErrorIs(t, err, pgxspecial.ErrNoSession)
+
+	ctx, _ := withQueryBuffer("  ")
+	for _, cmd := range []string{"\\g", "\\gx", "\\gset", "\\gexec", "\\gdesc"} {
+		_, _, err = pgxspecial.ExecuteSpecialCommand(ctx, nil, cmd)
+		assert.ErrorIs(t, err, pgxspecial.ErrEmptyQueryBuffer, cmd)
+	}
+}
+
+func TestStoreQueryResult(t *testing.T) {
+	db := &fakeConn{results: map[string]*fakeRows{
+		"one row":  {columns: []string{"a", "b"}, values: [][]*string{{text("1"), nil}}, tag: "SELECT 1"},
+		"no rows":  {columns: []string{"a"}, tag: "SELECT 0"},
+		"two rows": {columns: []string{"a"}, values: [][]*string{{text("1")}, {text("2")}}, tag: "SELECT 2"},
+	}}
+
+	ctx, session := withQueryBuffer("one row")
+	require.NoError(t, session.Set("p_b", "stale"))
+	res, _, err := pgxspecial.ExecuteSpecialCommand(ctx, db, "\\gset p_")
+	require.NoError(t, err)
+	assert.Nil(t, res)
+
+	a, _ := session.Get("p_a")
+	assert.Equal(t, "1", a)
+	_, ok := session.Get("p_b")
+	assert.False(t, ok, "NULL unsets the variable")
+	rowCount, _ := session.Get(pgxspecial.VarRowCount)
+	assert.Equal(t, "1", rowCount)
+
+	session.QueryBuffer().Set("no rows")
+	_, _, err = pgxspecial.ExecuteSpecialCommand(ctx, db, "\\gset")
+	assert.EqualError(t, err, "no rows returned for \\gset")
+
+	session.QueryBuffer().Set("two rows")
+	_, _, err = pgxspecial.ExecuteSpecialCommand(ctx, db, "\\gset")
+	assert.EqualError(t, err, "more than one row returned for \\gset")
+	_, ok = session.Get("a")
+	assert.False(t, ok)
+}
+
+func TestExecuteQueryResult(t *testing.T) {
+	db := &fakeConn{results: map[string]*fakeRows{
+		"generate": {
+			columns: []string{"a", "b"},
+			values:  [][]*string{{text("create table t1()"), nil}, {text("bogus"), text("create table t2()")}},
+			tag:     "SELECT 2",
+		},
+		"create table t1()": {tag: "CREATE TABLE"},
+		"create table t2()": {tag: "CREATE TABLE"},
+	}}
+
+	ctx, session := withQueryBuffer("generate")
+	res, _, err := pgxspecial.ExecuteSpecialCommand(ctx, db, "\\gexec")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"generate", "create table t1()", "bogus", "create table t2()"}, db.queries)
+
+	results := res.(pgxspecial.ScriptResults).Results
+	require.Len(t, results, 3)
+	assert.Equal(t, "CREATE TABLE", results[0].Result.(pgxspecial.QueryResult).CommandTag)
+	assert.Error(t, results[1].Err)
+	assert.Nil(t, results[1].Result)
+	assert.Equal(t, "create table t2()", results[2].Statement.Text)
+
+	// ON_ERROR_STOP stops at the first failure
+	require.NoError(t, session.Set(pgxspecial.VarOnErrorStop, "on"))
+	db.queries = nil
+	res, _, err = pgxspecial.ExecuteSpecialCommand(ctx, db, "\\gexec")
+	assert.Error(t, err)
+	assert.Equal(t, []string{"generate", "create table t1()", "bogus"}, db.queries)
+}
+
+func TestDescribeQueryBuffer(t *testing.T) {
+	db := &fakeConn{fields: []pgconn.FieldDescription{
+		{Name: "id", DataTypeOID: 23, TypeModifier: -1},
+		{Name: "name", DataTypeOID: 1043, TypeModifier: 14},
+	}}
+
+	ctx, session := withQueryBuffer("select id, name from users")
+	res, _, err := pgxspecial.ExecuteSpecialCommand(ctx, db, "\\gdesc")
+	require.NoError(t, err)
+	assert.Equal(t, pgxspecial.ResultKindRows, res.ResultKind())
+
+	require.Len(t, db.queries, 2)
+	assert.Equal(t, "PREPARE select id, name from users", db.queries[0])
+	assert.Equal(t, []any{[]string{"id", "name"}, []uint32{23, 1043}, []int32{-1, 14}}, db.args[0])
+	assert.Equal(t, "select id, name from users", session.QueryBuffer().Previous())
+}
+
+func TestQueryBufferInScript(t *testing.T) {
+	db := &fakeConn{results: map[string]*fakeRows{
+		"select 1":          {columns: []string{"n"}, values: [][]*string{{text("1")}}, tag: "SELECT 1"},
+		"select 'x'":        {columns: []string{"x"}, values: [][]*string{{text("x")}}, tag: "SELECT 1"},
+		"select 'x' as\n n": {columns: []string{"n"}, values: [][]*string{{text("x")}}, tag: "SELECT 1"},
+	}}
+	session := pgxspecial.NewSession()
+	session.QueryBuffer().Set("typed by the user")
+	ctx := pgxspecial.WithSession(context.Background(), session)
+
+	script := "select 1 \\gx out.txt\nselect 'x' \\gset\nselect :'x' as\n\\nope\n n;\n\\g\n"
+	results, err := pgxspecial.ExecuteScript(ctx, db, strings.NewReader(script))
+	require.NoError(t, err)
+
+	// \gx and \gset consume the SQL before them, an unknown command does
+	// not, and \g alone sends the previous query again
+	assert.Equal(t, []string{"select 1", "select 'x'", "select 'x' as\n n", "select 'x' as\n n"}, db.queries)
+	require.Len(t, results, 5)
+	assert.Equal(t, pgxspecial.QueryResult{
+		Columns:    []string{"n"},
+		Rows:       [][]any{{"1"}},
+		CommandTag: "SELECT 1",
+		Expanded:   true,
+		Output:     "out.txt",
+	}, results[0].Result)
+	x, _ := session.Get("x")
+	assert.Equal(t, "x", x)
+	assert.Error(t, results[2].Err)
+	assert.Equal(t, "SELECT 1", results[4].Result.(pgxspecial.QueryResult).CommandTag)
+
+	// the buffer of the caller is left alone
+	assert.Equal(t, "typed by the user", session.QueryBuffer().String())
+}
+
+func TestQueryBufferEchoHidden(t *testing.T) {
+	db := &fakeConn{
+		results: map[string]*fakeRows{
+			"select 1": {columns: []string{"n"}, values: [][]*string{{text("1")}}, tag: "SELECT 1"},
+		},
+		fields: []pgconn.FieldDescription{{Name: "n", DataTypeOID: 23, TypeModifier: -1}},
+	}
+	ctx, session := withQueryBuffer("select 1")
+	require.NoError(t, session.Set(pgxspecial.VarEchoHidden, "noexec"))
+
+	// the query of the user is sent, it is not a hidden query
+	res, _, err := pgxspecial.ExecuteSpecialCommand(ctx, db, "\\g")
+	require.NoError(t, err)
+	assert.Equal(t, pgxspecial.ResultKindRows, res.ResultKind())
+	assert.Equal(t, []string{"select 1"}, db.queries)
+
+	// \gdesc prepares it, but only shows the query describing the columns
+	res, _, err = pgxspecial.ExecuteSpecialCommand(ctx, db, "\\gdesc")
+	require.NoError(t, err)
+	echo := res.(pgxspecial.EchoHiddenResult)
+	require.Len(t, echo.Queries, 1)
+	assert.Contains(t, echo.Queries[0].SQL, "format_type")
+	assert.Nil(t, echo.Result)
+	assert.Equal(t, []string{"select 1", "PREPARE select 1"}, db.queries)
+}
@@ -1,36 +1,96 @@
 package dbcommands
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
 	"os"
 	"os/exec"
+	"strings"
 
 	"github.com/balaji01-4d/pgxspecial"
 	"github.com/balaji01-4d/pgxspecial/database"
-	"github.com/google/shlex"
 )
 
 func init() {
-	pgxspecial.RegisterCommand(pgxspecial.SpecialCommandRegistry{
+	pgxspecial.MustRegisterCommand(pgxspecial.SpecialCommandRegistry{
 		Cmd:           "\\!",
-		Description:   "Execute a shell command.",
-		Syntax:        "\\! command",
+		Description:   "Execute command in shell or start interactive shell.",
+		Group:         pgxspecial.GroupOperatingSystem,
+		Syntax:        "\\! [COMMAND]",
 		Handler:       ShellCommand,
+		Categories:    []string{pgxspecial.CategoryShell},
+		RawArgs:       true,
 		CaseSensitive: true,
 	})
 }
 
-func ShellCommand(ctx context.Context, db database.Queryer, args string, verbose bool) (pgxspecial.SpecialCommandResult, error) {
-	parts, err := shlex.Split(args)
-	if err != nil {
-		return nil, err
+// shellNotFound is the exit status of sh for a command it cannot find,
+// which psql reports as a failure of \! itself.
+const shellNotFound = 127
+
+// ShellCommand runs command through sh, or without a command starts the
+// interactive shell named by SHELL (default /bin/sh). The command is
+// attached to the streams carried by ctx, see pgxspecial.WithShellStreams,
+// and output to nil streams is returned in the pgxspecial.ShellResult. If
+// ctx carries a Session, the command runs in its environment and working
+// directory.
+func ShellCommand(ctx context.Context, db database.Queryer, args string, opts pgxspecial.CommandOptions) (pgxspecial.SpecialCommandResult, error) {
+	env := os.Environ()
+	session := pgxspecial.SessionFromContext(ctx)
+	if session != nil {
+		env = session.Environ()
+	}
+
+	var cmd *exec.Cmd
+	if command := strings.TrimSpace(args); command != "" {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	} else {
+		shell := lookupEnv(env, "SHELL")
+		if shell == "" {
+			shell = "/bin/sh"
+		}
+		cmd = exec.CommandContext(ctx, shell)
 	}
 
-	cmd := exec.CommandContext(ctx, parts[0], parts[1:]...)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	var output bytes.Buffer
+	streams := pgxspecial.ShellStreamsFromContext(ctx)
+	cmd.Stdin = streams.Stdin
+	cmd.Stdout = streams.Stdout
+	cmd.Stderr = streams.Stderr
+	if cmd.Stdout == nil {
+		cmd.Stdout = &output
+	}
+	if cmd.Stderr == nil {
+		cmd.Stderr = &output
+	}
+	cmd.Env = env
+	if session != nil {
+		cmd.Dir = session.Dir()
+	}
 
-	err = cmd.Run()
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() != shellNotFound {
+		err = nil
+	}
+	if err != nil {
+		if msg := strings.TrimSpace(output.String()); msg != "" {
+			return nil, fmt.Errorf("\\!: %w: %s", err, msg)
+		}
+		return nil, fmt.Errorf("\\!: %w", err)
+	}
+	return pgxspecial.ShellResult{Output: output.String(), ExitCode: cmd.ProcessState.ExitCode()}, nil
+}
 
-	return nil, err
+// lookupEnv returns the value of name in env, a list of "NAME=VALUE"
+// entries, or "" if it is not set.
+func lookupEnv(env []string, name string) string {
+	for _, kv := range env {
+		if value, ok := strings.CutPrefix(kv, name+"="); ok {
+			return value
+		}
+	}
+	return ""
 }
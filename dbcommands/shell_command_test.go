@@ -1,16 +1,22 @@
 package dbcommands_test
 
 import (
+	"bytes"
 	"context"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/balaji01-4d/pgxspecial"
 	"github.com/balaji01-4d/pgxspecial/dbcommands"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestShellCommand_Success(t *testing.T) {
 	ctx := context.Background()
 
-	_, err := dbcommands.ShellCommand(ctx, nil, "echo hello", false)
+	_, err := dbcommands.ShellCommand(ctx, nil, "echo hello", pgxspecial.CommandOptions{})
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
@@ -20,8 +26,70 @@ func TestShellCommand_Failure(t *testing.T) {
 	ctx := context.Background()
 
 	// invalid command should error
-	_, err := dbcommands.ShellCommand(ctx, nil, "commandthatdoesnotexist", false)
+	_, err := dbcommands.ShellCommand(ctx, nil, "commandthatdoesnotexist", pgxspecial.CommandOptions{})
 	if err == nil {
 		t.Fatalf("expected error, got nil")
 	}
 }
+
+func TestShellCommandCapturedOutput(t *testing.T) {
+	ctx := pgxspecial.WithShellStreams(context.Background(), pgxspecial.ShellStreams{})
+
+	res, _, err := pgxspecial.ExecuteSpecialCommand(ctx, nil, "\\! echo out; echo err >&2; exit 3")
+	require.NoError(t, err)
+	assert.Equal(t, pgxspecial.ShellResult{Output: "out\nerr\n", ExitCode: 3}, res)
+
+	var stdout bytes.Buffer
+	ctx = pgxspecial.WithShellStreams(context.Background(), pgxspecial.ShellStreams{
+		Stdin:  strings.NewReader("piped"),
+		Stdout: &stdout,
+	})
+	res, _, err = pgxspecial.ExecuteSpecialCommand(ctx, nil, "\\! cat; echo err >&2")
+	require.NoError(t, err)
+	assert.Equal(t, "piped", stdout.String())
+	assert.Equal(t, pgxspecial.ShellResult{Output: "err\n"}, res)
+
+	_, _, err = pgxspecial.ExecuteSpecialCommand(ctx, nil, "\\! commandthatdoesnotexist")
+	assert.ErrorContains(t, err, "not found")
+}
+
+func TestShellCommandNotInterpolated(t *testing.T) {
+	// the line reaches the shell as typed, as variables may hold query
+	// results stored by \gset
+	session := pgxspecial.NewSession()
+	require.NoError(t, session.Set("x", "$(echo injected)"))
+	ctx := pgxspecial.WithShellStreams(pgxspecial.WithSession(context.Background(), session), pgxspecial.ShellStreams{})
+
+	res, _, err := pgxspecial.ExecuteSpecialCommand(ctx, nil, "\\! echo :x")
+	require.NoError(t, err)
+	assert.Equal(t, pgxspecial.ShellResult{Output: ":x\n"}, res)
+}
+
+func TestShellCommandInteractiveShell(t *testing.T) {
+	t.Setenv("SHELL", "/bin/sh")
+
+	for _, cmd := range []string{"\\!", "\\!   "} {
+		ctx := pgxspecial.WithShellStreams(context.Background(), pgxspecial.ShellStreams{
+			Stdin: strings.NewReader("echo from shell\n"),
+		})
+		res, _, err := pgxspecial.ExecuteSpecialCommand(ctx, nil, cmd)
+		require.NoError(t, err, cmd)
+		assert.Equal(t, pgxspecial.ShellResult{Output: "from shell\n"}, res, cmd)
+	}
+}
+
+func TestShellCommandSessionEnvironment(t *testing.T) {
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+	t.Setenv("PGXSPECIAL_REMOVED", "still set")
+
+	session := pgxspecial.NewSession()
+	require.NoError(t, session.Setenv("GREETING", "hello"))
+	require.NoError(t, session.Unsetenv("PGXSPECIAL_REMOVED"))
+	require.NoError(t, session.Chdir(dir))
+	ctx := pgxspecial.WithShellStreams(pgxspecial.WithSession(context.Background(), session), pgxspecial.ShellStreams{})
+
+	res, _, err := pgxspecial.ExecuteSpecialCommand(ctx, nil, `\! echo "$GREETING ${PGXSPECIAL_REMOVED-unset}"; pwd -P`)
+	require.NoError(t, err)
+	assert.Equal(t, "hello unset\n"+dir+"\n", res.(pgxspecial.ShellResult).Output)
+}
@@ -2,7 +2,6 @@ package dbcommands
 
 import (
 	"context"
-	"fmt"
 	"strings"
 
 	"github.com/balaji01-4d/pgxspecial"
@@ -10,70 +9,65 @@ import (
 )
 
 func init() {
-	pgxspecial.RegisterCommand(pgxspecial.SpecialCommandRegistry{
+	pgxspecial.MustRegisterCommand(pgxspecial.SpecialCommandRegistry{
 		Cmd:           "\\sf",
 		Description:   "Show a function's definition.",
+		Group:         pgxspecial.GroupInformational,
 		Syntax:        "\\sf[+] FUNCNAME",
 		Handler:       ShowFunctionDefinition,
+		RawArgs:       true,
 		CaseSensitive: true,
 	})
 }
 
-func ShowFunctionDefinition(ctx context.Context, db database.Queryer, pattern string, verbose bool) (pgxspecial.SpecialCommandResult, error) {
-	var sql string
-	if strings.Contains(pattern, "(") {
-		sql = "SELECT $1::pg_catalog.regprocedure::pg_catalog.oid"
-	} else {
-		sql = "SELECT $1::pg_catalog.regproc::pg_catalog.oid"
+// ShowFunctionDefinition returns the CREATE OR REPLACE FUNCTION statement of
+// a function as a pgxspecial.TextResult. With \sf+ the lines of the function
+// body are numbered, as in psql.
+func ShowFunctionDefinition(ctx context.Context, db database.Queryer, pattern string, opts pgxspecial.CommandOptions) (pgxspecial.SpecialCommandResult, error) {
+	foid, err := functionOID(ctx, db, pattern)
+	if err != nil {
+		return nil, err
 	}
 
-	var foid uint32
-	err := db.QueryRow(ctx, sql, pattern).Scan(&foid)
+	var source string
+	err = db.QueryRow(ctx, "SELECT pg_catalog.pg_get_functiondef($1)", foid).Scan(&source)
 	if err != nil {
 		return nil, err
 	}
 
-	sql = "SELECT pg_catalog.pg_get_functiondef($1) as source"
-	if !verbose {
-		rows, err := db.Query(ctx, sql, foid)
-		if err != nil {
-			return nil, err
+	res := pgxspecial.NewTextResult(source)
+	if opts.Verbose {
+		body := functionBodyLine(source)
+		for i := body; i < len(res.Lines); i++ {
+			res.Lines[i].Number = i - body + 1
 		}
-		return pgxspecial.RowResult{Rows: rows}, nil
-
 	}
+	return res, nil
+}
 
-	var source string
-	err = db.QueryRow(ctx, sql, foid).Scan(&source)
-	if err != nil {
-		return nil, err
+// functionOID resolves a function name, with an argument list if it is
+// overloaded, as regproc or regprocedure do.
+func functionOID(ctx context.Context, db database.Queryer, name string) (uint32, error) {
+	sql := "SELECT $1::pg_catalog.regproc::pg_catalog.oid"
+	if strings.Contains(name, "(") {
+		sql = "SELECT $1::pg_catalog.regprocedure::pg_catalog.oid"
 	}
 
-	var sb strings.Builder
-	lines := strings.Split(source, "\n")
-	var rown int
-	started := false
+	var foid uint32
+	err := db.QueryRow(ctx, sql, name).Scan(&foid)
+	return foid, err
+}
 
-	for _, row := range lines {
-		var prefix string
-		if !started {
-			if strings.HasPrefix(row, "AS ") {
-				started = true
-				rown = 1
-				prefix = fmt.Sprintf("%-7d", rown)
-			} else {
-				prefix = fmt.Sprintf("%-7s", "")
+// functionBodyLine returns the number of lines of a pg_get_functiondef
+// result before the function body, which starts on the first line beginning
+// with "AS ", "BEGIN " or "RETURN ".
+func functionBodyLine(def string) int {
+	for i, line := range strings.Split(def, "\n") {
+		for _, prefix := range []string{"AS ", "BEGIN ", "RETURN "} {
+			if strings.HasPrefix(line, prefix) {
+				return i
 			}
-		} else {
-			rown++
-			prefix = fmt.Sprintf("%-7d", rown)
 		}
-		sb.WriteString(prefix + " " + row + "\n")
 	}
-	rows, err := db.Query(ctx, "SELECT $1 as source", sb.String())
-	if err != nil {
-		return nil, err
-	}
-	return pgxspecial.RowResult{Rows: rows}, nil
-
+	return 0
 }
@@ -6,6 +6,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/balaji01-4d/pgxspecial"
 	"github.com/balaji01-4d/pgxspecial/dbcommands"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/stretchr/testify/assert"
@@ -47,26 +48,19 @@ func TestShowFunctionDefinition(t *testing.T) {
 	pattern := "add_numbers(integer, integer)"
 	verbose := false
 
-	res, err := dbcommands.ShowFunctionDefinition(ctx, db, pattern, verbose)
+	res, err := dbcommands.ShowFunctionDefinition(ctx, db, pattern, pgxspecial.CommandOptions{Verbose: verbose})
 	if err != nil {
 		t.Fatalf("ShowFunctionDefinition failed: %v", err)
 	}
-	result := RequiresRowResult(t, res)
-	defer result.Rows.Close()
-
-	var source string
-	if result.Rows.Next() {
-		err = result.Rows.Scan(&source)
-		if err != nil {
-			t.Fatalf("Failed to scan result: %v", err)
-		}
-	} else {
-		t.Fatalf("No rows returned")
-	}
+	result, ok := res.(pgxspecial.TextResult)
+	require.True(t, ok, "expected TextResult, got %T", res)
 
+	source := result.String()
 	assert.Contains(t, strings.TrimSpace(source), "add_numbers", "Function definition does not match expected")
 	assert.Contains(t, strings.TrimSpace(source), "RETURN a + b;", "Function body does not match expected")
-	assert.False(t, result.Rows.Next(), "Expected only one row")
+	for _, line := range result.Lines {
+		assert.Zero(t, line.Number, "Expected unnumbered lines")
+	}
 }
 
 func TestShowFunctionDefinitionVerbose(t *testing.T) {
@@ -92,21 +86,21 @@ func TestShowFunctionDefinitionVerbose(t *testing.T) {
 	pattern := "add_numbers_verbose(integer, integer)"
 	verbose := true
 
-	res, err := dbcommands.ShowFunctionDefinition(ctx, db, pattern, verbose)
+	res, err := dbcommands.ShowFunctionDefinition(ctx, db, pattern, pgxspecial.CommandOptions{Verbose: verbose})
 	if err != nil {
 		t.Fatalf("ShowFunctionDefinition with verbose failed: %v", err)
 	}
-	result := RequiresRowResult(t, res)
-	defer result.Rows.Close()
-
-	var source string
-	if result.Rows.Next() {
-		err = result.Rows.Scan(&source)
-		if err != nil {
-			t.Fatalf("Failed to scan result: %v", err)
+	result, ok := res.(pgxspecial.TextResult)
+	require.True(t, ok, "expected TextResult, got %T", res)
+	source := result.String()
+
+	// the header is not numbered, the body starts at line 1
+	assert.Equal(t, "CREATE OR REPLACE FUNCTION public.add_numbers_verbose(a integer, b integer)", result.Lines[0].Text)
+	assert.Zero(t, result.Lines[0].Number)
+	for _, line := range result.Lines {
+		if strings.HasPrefix(line.Text, "AS ") {
+			assert.Equal(t, 1, line.Number)
 		}
-	} else {
-		t.Fatalf("No rows returned")
 	}
 
 	if !strings.Contains(source, "1      ") {
@@ -0,0 +1,45 @@
+package dbcommands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/balaji01-4d/pgxspecial"
+	"github.com/balaji01-4d/pgxspecial/database"
+)
+
+func init() {
+	pgxspecial.MustRegisterCommand(pgxspecial.SpecialCommandRegistry{
+		Cmd:           "\\sv",
+		Description:   "Show a view's definition.",
+		Group:         pgxspecial.GroupInformational,
+		Syntax:        "\\sv[+] VIEWNAME",
+		Handler:       ShowViewDefinition,
+		RawArgs:       true,
+		CaseSensitive: true,
+	})
+}
+
+// ShowViewDefinition returns the statement recreating a view or
+// materialized view as a pgxspecial.TextResult. With \sv+ every line is
+// numbered, starting from the CREATE line.
+func ShowViewDefinition(ctx context.Context, db database.Queryer, name string, opts pgxspecial.CommandOptions) (pgxspecial.SpecialCommandResult, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("missing required argument")
+	}
+
+	def, err := viewDefinition(ctx, db, name)
+	if err != nil {
+		return nil, err
+	}
+
+	res := pgxspecial.NewTextResult(def)
+	if opts.Verbose {
+		for i := range res.Lines {
+			res.Lines[i].Number = i + 1
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,63 @@
+package dbcommands_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/balaji01-4d/pgxspecial"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShowViewDefinition(t *testing.T) {
+	db := connectTestDB(t)
+	defer db.(*pgxpool.Pool).Close()
+
+	ctx := context.Background()
+	_, err := db.(*pgxpool.Pool).Exec(ctx, `CREATE OR REPLACE VIEW show_view WITH (security_barrier) AS
+		SELECT 1 AS one WHERE true WITH CASCADED CHECK OPTION`)
+	require.NoError(t, err)
+	defer db.(*pgxpool.Pool).Exec(ctx, "DROP VIEW IF EXISTS show_view")
+
+	res, _, err := pgxspecial.ExecuteSpecialCommand(ctx, db, "\\sv show_view")
+	require.NoError(t, err)
+	assert.Equal(t, pgxspecial.ResultKindText, res.ResultKind())
+	assert.Equal(t, "CREATE OR REPLACE VIEW public.show_view\n"+
+		" WITH (security_barrier='true') AS\n"+
+		" SELECT 1 AS one\n"+
+		"  WHERE true\n"+
+		" WITH CASCADED CHECK OPTION\n", res.(pgxspecial.TextResult).String())
+
+	res, _, err = pgxspecial.ExecuteSpecialCommand(ctx, db, "\\sv+ show_view")
+	require.NoError(t, err)
+	lines := res.(pgxspecial.TextResult).Lines
+	require.Len(t, lines, 5)
+	assert.Equal(t, pgxspecial.TextLine{Number: 1, Text: "CREATE OR REPLACE VIEW public.show_view"}, lines[0])
+	assert.Equal(t, pgxspecial.TextLine{Number: 5, Text: " WITH CASCADED CHECK OPTION"}, lines[4])
+}
+
+func TestShowMaterializedViewDefinition(t *testing.T) {
+	db := connectTestDB(t)
+	defer db.(*pgxpool.Pool).Close()
+
+	ctx := context.Background()
+	_, err := db.(*pgxpool.Pool).Exec(ctx, `CREATE MATERIALIZED VIEW IF NOT EXISTS show_matview AS SELECT 1 AS one`)
+	require.NoError(t, err)
+	defer db.(*pgxpool.Pool).Exec(ctx, "DROP MATERIALIZED VIEW IF EXISTS show_matview")
+
+	res, _, err := pgxspecial.ExecuteSpecialCommand(ctx, db, "\\sv show_matview")
+	require.NoError(t, err)
+	assert.Equal(t, "CREATE MATERIALIZED VIEW public.show_matview AS\n"+
+		" SELECT 1 AS one\n", res.(pgxspecial.TextResult).String())
+
+	_, _, err = pgxspecial.ExecuteSpecialCommand(ctx, db, "\\sv no_such_view")
+	assert.Error(t, err)
+	_, _, err = pgxspecial.ExecuteSpecialCommand(ctx, db, "\\sv pg_catalog.pg_class")
+	assert.EqualError(t, err, `"pg_catalog.pg_class" is not a view`)
+}
+
+func TestShowViewDefinitionMissingName(t *testing.T) {
+	_, _, err := pgxspecial.ExecuteSpecialCommand(context.Background(), nil, "\\sv+")
+	assert.EqualError(t, err, "missing required argument")
+}
@@ -0,0 +1,220 @@
+package dbcommands
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/balaji01-4d/pgxspecial"
+	"github.com/balaji01-4d/pgxspecial/database"
+)
+
+func init() {
+	pgxspecial.MustRegisterCommand(pgxspecial.SpecialCommandRegistry{
+		Cmd:           "\\h",
+		Alias:         []string{"\\help"},
+		Description:   "Show help on syntax of SQL commands, * for all commands.",
+		Group:         pgxspecial.GroupHelp,
+		Syntax:        "\\h [command]",
+		Handler:       ShowSQLHelp,
+		RawArgs:       true,
+		CaseSensitive: true,
+	})
+}
+
+// sqlHelpData holds the SQL command synopses, see the header of sql_help.txt
+// for the format.
+//
+//go:embed sql_help.txt
+var sqlHelpData string
+
+const sqlHelpDocURL = "https://www.postgresql.org/docs/%s/%s.html"
+
+type sqlHelpSet struct {
+	version string
+	topics  []pgxspecial.SQLHelpTopic
+}
+
+var loadSQLHelp = sync.OnceValue(func() sqlHelpSet {
+	set, err := parseSQLHelp(sqlHelpData)
+	if err != nil {
+		panic("pgxspecial: sql_help.txt: " + err.Error())
+	}
+	return set
+})
+
+// parseSQLHelp parses the embedded synopsis dataset. Topics are returned
+// sorted by name.
+func parseSQLHelp(data string) (sqlHelpSet, error) {
+	var set sqlHelpSet
+	var cur *pgxspecial.SQLHelpTopic
+	var synopsis []string
+
+	flush := func() {
+		if cur == nil {
+			return
+		}
+		for len(synopsis) > 0 && strings.TrimSpace(synopsis[len(synopsis)-1]) == "" {
+			synopsis = synopsis[:len(synopsis)-1]
+		}
+		cur.Synopsis = strings.Join(synopsis, "\n")
+		set.topics = append(set.topics, *cur)
+		cur, synopsis = nil, nil
+	}
+
+	for i, line := range strings.Split(data, "\n") {
+		switch {
+		case strings.HasPrefix(line, "@ "):
+			flush()
+			fields := strings.Split(line[2:], "|")
+			if len(fields) < 2 || len(fields) > 3 {
+				return sqlHelpSet{}, fmt.Errorf("line %d: malformed entry header", i+1)
+			}
+			name := strings.TrimSpace(fields[0])
+			page := "sql-" + strings.ToLower(strings.ReplaceAll(name, " ", ""))
+			if len(fields) == 3 {
+				page = strings.TrimSpace(fields[2])
+			}
+			cur = &pgxspecial.SQLHelpTopic{
+				Name:        name,
+				Description: strings.TrimSpace(fields[1]),
+				URL:         page,
+			}
+		case cur != nil:
+			synopsis = append(synopsis, strings.TrimRight(line, " \t\r"))
+		case strings.HasPrefix(line, "# version:"):
+			set.version = strings.TrimSpace(strings.TrimPrefix(line, "# version:"))
+		case line == "" || strings.HasPrefix(line, "#"):
+		default:
+			return sqlHelpSet{}, fmt.Errorf("line %d: text outside of an entry", i+1)
+		}
+	}
+	flush()
+
+	if set.version == "" {
+		return sqlHelpSet{}, fmt.Errorf("missing version")
+	}
+	for i := range set.topics {
+		set.topics[i].URL = fmt.Sprintf(sqlHelpDocURL, set.version, set.topics[i].URL)
+	}
+	sort.Slice(set.topics, func(i, j int) bool {
+		return set.topics[i].Name < set.topics[j].Name
+	})
+	return set, nil
+}
+
+// ShowSQLHelp returns the syntax of the SQL commands matching topic.
+//
+// Like psql, an exact command name shows only that command, while a partial
+// name shows every command starting with it ("\h create t"). Names within a
+// small edit distance are accepted when nothing starts with the topic, so
+// misspellings such as "\h craete table" still resolve. Failing that, trailing
+// words are dropped one at a time ("\h alter foo" lists the ALTER commands).
+// An empty topic or "*" returns every command.
+func ShowSQLHelp(ctx context.Context, db database.Queryer, topic string, opts pgxspecial.CommandOptions) (pgxspecial.SpecialCommandResult, error) {
+	set := loadSQLHelp()
+
+	topics := matchSQLHelp(set.topics, topic)
+	if len(topics) == 0 {
+		return nil, fmt.Errorf("no help available for %q", strings.TrimSpace(topic))
+	}
+	return pgxspecial.SQLHelpResult{Version: set.version, Topics: topics}, nil
+}
+
+func matchSQLHelp(topics []pgxspecial.SQLHelpTopic, topic string) []pgxspecial.SQLHelpTopic {
+	topic = strings.TrimSuffix(strings.TrimSpace(topic), ";")
+	words := strings.Fields(strings.ToUpper(topic))
+	if len(words) == 0 || (len(words) == 1 && words[0] == "*") {
+		return topics
+	}
+
+	for n := len(words); n > 0; n-- {
+		prefix := strings.Join(words[:n], " ")
+		var matches []pgxspecial.SQLHelpTopic
+		for _, t := range topics {
+			if t.Name == prefix {
+				return []pgxspecial.SQLHelpTopic{t}
+			}
+			if strings.HasPrefix(t.Name, prefix) {
+				matches = append(matches, t)
+			}
+		}
+		if len(matches) == 0 {
+			matches = closestSQLHelp(topics, words[:n])
+		}
+		if len(matches) > 0 {
+			return matches
+		}
+	}
+	return nil
+}
+
+// closestSQLHelp returns the topics whose leading words are closest to words
+// by edit distance. Each word may be off by one edit per four characters, so
+// a misspelled first word ("\h craete") still matches a whole family of
+// commands while short words must match exactly. As with exact matches, names
+// that are no longer than the topic win over longer ones.
+func closestSQLHelp(topics []pgxspecial.SQLHelpTopic, words []string) []pgxspecial.SQLHelpTopic {
+	best := -1
+	var matches, whole []pgxspecial.SQLHelpTopic
+	for _, t := range topics {
+		nameWords := strings.Fields(t.Name)
+		if len(nameWords) < len(words) {
+			continue
+		}
+		d := 0
+		for i, w := range words {
+			wd := editDistance(w, nameWords[i])
+			if wd > len(w)/4 {
+				d = -1
+				break
+			}
+			d += wd
+		}
+		if d < 0 || (best >= 0 && d > best) {
+			continue
+		}
+		if d < best || best < 0 {
+			best = d
+			matches, whole = nil, nil
+		}
+		matches = append(matches, t)
+		if len(nameWords) == len(words) {
+			whole = append(whole, t)
+		}
+	}
+	if len(whole) > 0 {
+		return whole
+	}
+	return matches
+}
+
+// editDistance returns the optimal string alignment distance between a and b:
+// the number of insertions, deletions, substitutions and adjacent
+// transpositions needed to turn one into the other.
+func editDistance(a, b string) int {
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(b)]
+}
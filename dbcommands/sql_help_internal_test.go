@@ -0,0 +1,65 @@
+package dbcommands
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLHelpDataset(t *testing.T) {
+	set, err := parseSQLHelp(sqlHelpData)
+	require.NoError(t, err)
+
+	seen := map[string]bool{}
+	for _, topic := range set.topics {
+		assert.False(t, seen[topic.Name], "duplicate topic %s", topic.Name)
+		seen[topic.Name] = true
+
+		assert.NotEmpty(t, topic.Description, topic.Name)
+		assert.NotEmpty(t, topic.Synopsis, topic.Name)
+		assert.True(t, strings.HasPrefix(topic.URL, "https://www.postgresql.org/docs/"+set.version+"/sql-"), topic.URL)
+	}
+
+	// pages whose names are not the command without spaces
+	pages := map[string]string{
+		"COMMIT PREPARED":                 "sql-commit-prepared",
+		"CREATE OPERATOR CLASS":           "sql-createopclass",
+		"DROP OPERATOR FAMILY":            "sql-dropopfamily",
+		"ALTER TEXT SEARCH CONFIGURATION": "sql-altertsconfig",
+		"CREATE TEXT SEARCH DICTIONARY":   "sql-createtsdictionary",
+		"DROP TEXT SEARCH TEMPLATE":       "sql-droptstemplate",
+	}
+	for _, topic := range set.topics {
+		if page, ok := pages[topic.Name]; ok {
+			assert.Equal(t, "https://www.postgresql.org/docs/"+set.version+"/"+page+".html", topic.URL)
+			delete(pages, topic.Name)
+		}
+	}
+	assert.Empty(t, pages, "topics not found")
+}
+
+func TestParseSQLHelp(t *testing.T) {
+	set, err := parseSQLHelp("# version: 17\n\n@ B | second\nB x\n\n@ A | first | sql-other\nA y\n  z\n\n\n")
+	require.NoError(t, err)
+	assert.Equal(t, "17", set.version)
+	require.Len(t, set.topics, 2)
+	assert.Equal(t, "A", set.topics[0].Name)
+	assert.Equal(t, "A y\n  z", set.topics[0].Synopsis)
+	assert.Equal(t, "https://www.postgresql.org/docs/17/sql-other.html", set.topics[0].URL)
+	assert.Equal(t, "https://www.postgresql.org/docs/17/sql-b.html", set.topics[1].URL)
+
+	_, err = parseSQLHelp("@ A | first\nA\n")
+	assert.Error(t, err)
+
+	_, err = parseSQLHelp("# version: 17\nstray\n")
+	assert.Error(t, err)
+}
+
+func TestEditDistance(t *testing.T) {
+	assert.Equal(t, 0, editDistance("SELECT", "SELECT"))
+	assert.Equal(t, 1, editDistance("SELCT", "SELECT"))
+	assert.Equal(t, 1, editDistance("SELCET", "SELECT"))
+	assert.Equal(t, 3, editDistance("", "SET"))
+}
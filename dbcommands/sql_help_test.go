@@ -0,0 +1,109 @@
+package dbcommands_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/balaji01-4d/pgxspecial"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sqlHelpNames(t *testing.T, command string) []string {
+	t.Helper()
+	res, isSpecial, err := pgxspecial.ExecuteSpecialCommand(context.Background(), nil, command)
+	require.NoError(t, err)
+	assert.True(t, isSpecial)
+	require.Equal(t, pgxspecial.ResultKindSQLHelp, res.ResultKind())
+
+	var names []string
+	for _, topic := range res.(pgxspecial.SQLHelpResult).Topics {
+		names = append(names, topic.Name)
+	}
+	return names
+}
+
+func TestShowSQLHelp(t *testing.T) {
+	tests := []struct {
+		name     string
+		command  string
+		expected []string
+	}{
+		{
+			name:     "exact match",
+			command:  "\\h select",
+			expected: []string{"SELECT"},
+		},
+		{
+			name:     "exact multi-word match",
+			command:  "\\h CREATE   table",
+			expected: []string{"CREATE TABLE"},
+		},
+		{
+			name:     "prefix match",
+			command:  "\\h create tab",
+			expected: []string{"CREATE TABLE", "CREATE TABLE AS", "CREATE TABLESPACE"},
+		},
+		{
+			name:     "alias and trailing semicolon",
+			command:  "\\help alter role;",
+			expected: []string{"ALTER ROLE"},
+		},
+		{
+			name:     "trailing words dropped",
+			command:  "\\h select * from users",
+			expected: []string{"SELECT"},
+		},
+		{
+			name:     "misspelled name",
+			command:  "\\h craete tabel",
+			expected: []string{"CREATE TABLE"},
+		},
+		{
+			name:     "misspelled single word",
+			command:  "\\h truncte",
+			expected: []string{"TRUNCATE"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, sqlHelpNames(t, tt.command))
+		})
+	}
+}
+
+func TestShowSQLHelpAll(t *testing.T) {
+	all := sqlHelpNames(t, "\\h")
+	assert.Greater(t, len(all), 150)
+	assert.Equal(t, all, sqlHelpNames(t, "\\h *"))
+}
+
+func TestShowSQLHelpFamily(t *testing.T) {
+	names := sqlHelpNames(t, "\\h alter foo")
+	assert.Contains(t, names, "ALTER TABLE")
+	for _, name := range names {
+		assert.True(t, strings.HasPrefix(name, "ALTER "), name)
+	}
+}
+
+func TestShowSQLHelpTopic(t *testing.T) {
+	res, _, err := pgxspecial.ExecuteSpecialCommand(context.Background(), nil, "\\h create index")
+	require.NoError(t, err)
+
+	help := res.(pgxspecial.SQLHelpResult)
+	assert.Equal(t, "16", help.Version)
+	require.Len(t, help.Topics, 1)
+
+	topic := help.Topics[0]
+	assert.Equal(t, "define a new index", topic.Description)
+	assert.Contains(t, topic.Synopsis, "CREATE [ UNIQUE ] INDEX [ CONCURRENTLY ]")
+	assert.Equal(t, "https://www.postgresql.org/docs/16/sql-createindex.html", topic.URL)
+}
+
+func TestShowSQLHelpNoMatch(t *testing.T) {
+	_, isSpecial, err := pgxspecial.ExecuteSpecialCommand(context.Background(), nil, "\\h xyzzy")
+	assert.True(t, isSpecial)
+	assert.EqualError(t, err, `no help available for "xyzzy"`)
+}
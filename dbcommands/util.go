@@ -1,13 +1,228 @@
 package dbcommands
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
 	"strings"
+
+	"github.com/balaji01-4d/pgxspecial"
+	"github.com/balaji01-4d/pgxspecial/database"
 )
 
-func sqlNamePattern(pattern string) (schema, table string) {
+// splitPatterns splits the arguments of a meta-command into patterns at
+// whitespace outside double quotes, so that \dt users orders lists both
+// tables. The quotes are kept for ParseNamePattern to interpret.
+func splitPatterns(args string) []string {
+	var patterns []string
+	var buf strings.Builder
 	inQuotes := false
+
+	for i := 0; i < len(args); i++ {
+		c := args[i]
+
+		switch {
+		case c == '"':
+			inQuotes = !inQuotes
+			buf.WriteByte(c)
+
+		case !inQuotes && strings.ContainsRune(" \t\n\r", rune(c)):
+			if buf.Len() > 0 {
+				patterns = append(patterns, buf.String())
+				buf.Reset()
+			}
+
+		default:
+			buf.WriteByte(c)
+		}
+	}
+
+	if buf.Len() > 0 {
+		patterns = append(patterns, buf.String())
+	}
+	return patterns
+}
+
+// splitArgs splits meta-command arguments into words the way psql does for
+// commands such as \set. Words are separated by whitespace. Single-quoted
+// text is unquoted, with a doubled quote standing for one and C-like
+// backslash escapes (\n, \t, \141, \x61, ...); double-quoted and backquoted
+// text is kept verbatim, quotes included. Variable references outside quotes
+// are expanded from session, if not nil, and become part of the current word
+// even if the value contains whitespace.
+func splitArgs(args string, session *pgxspecial.Session) ([]string, error) {
+	var words []string
 	var buf strings.Builder
-	var schemaBuf *string
+	inWord := false
+
+	for i := 0; i < len(args); i++ {
+		c := args[i]
+
+		switch {
+		case strings.IndexByte(" \t\n\r", c) >= 0:
+			if inWord {
+				words = append(words, buf.String())
+				buf.Reset()
+				inWord = false
+			}
+			continue
+
+		case c == '\'':
+			end, err := unquoteArg(args, i, &buf)
+			if err != nil {
+				return nil, err
+			}
+			i = end
+
+		case c == '"' || c == '`':
+			end := strings.IndexByte(args[i+1:], c)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated quoted string")
+			}
+			buf.WriteString(args[i : i+end+2])
+			i += end + 1
+
+		case c == ':' && session != nil && strings.HasPrefix(args[i:], "::"):
+			buf.WriteString("::")
+			i++
+
+		case c == ':' && session != nil:
+			value, n, ok := session.ExpandVariable(args[i+1:])
+			if !ok {
+				buf.WriteByte(c)
+				break
+			}
+			buf.WriteString(value)
+			i += n
+
+		default:
+			buf.WriteByte(c)
+		}
+		inWord = true
+	}
+
+	if inWord {
+		words = append(words, buf.String())
+	}
+	return words, nil
+}
+
+// unquoteArg writes the content of the single-quoted string starting at
+// args[start] to buf and returns the index of its closing quote.
+func unquoteArg(args string, start int, buf *strings.Builder) (int, error) {
+	for i := start + 1; i < len(args); i++ {
+		c := args[i]
+		switch {
+		case c == '\'' && i+1 < len(args) && args[i+1] == '\'':
+			buf.WriteByte('\'')
+			i++
+
+		case c == '\'':
+			return i, nil
+
+		case c == '\\' && i+1 < len(args):
+			i++
+			switch e := args[i]; {
+			case e == 'n':
+				buf.WriteByte('\n')
+			case e == 't':
+				buf.WriteByte('\t')
+			case e == 'b':
+				buf.WriteByte('\b')
+			case e == 'r':
+				buf.WriteByte('\r')
+			case e == 'f':
+				buf.WriteByte('\f')
+			case e >= '0' && e <= '7':
+				n := 0
+				for j := 0; j < 3 && i < len(args) && args[i] >= '0' && args[i] <= '7'; j++ {
+					n = n*8 + int(args[i]-'0')
+					i++
+				}
+				i--
+				buf.WriteByte(byte(n))
+			case e == 'x' && i+1 < len(args) && isHexDigit(args[i+1]):
+				n := 0
+				for j := 0; j < 2 && i+1 < len(args) && isHexDigit(args[i+1]); j++ {
+					i++
+					v, _ := strconv.ParseUint(args[i:i+1], 16, 8)
+					n = n*16 + int(v)
+				}
+				buf.WriteByte(byte(n))
+			default:
+				buf.WriteByte(e)
+			}
+
+		default:
+			buf.WriteByte(c)
+		}
+	}
+	return 0, fmt.Errorf("unterminated quoted string")
+}
+
+func isHexDigit(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+// checkOutputTarget checks the output target of command against the policy
+// carried by ctx: "|command" runs a shell command, anything else writes a
+// file.
+func checkOutputTarget(ctx context.Context, command, target string) error {
+	if target == "" {
+		return nil
+	}
+	category := pgxspecial.CategoryFilesystem
+	if strings.HasPrefix(target, "|") {
+		category = pgxspecial.CategoryShell
+	}
+	return pgxspecial.PolicyFromContext(ctx).CheckCategory(command, category)
+}
+
+// Errors reported (wrapped in a PatternError) for malformed object name
+// patterns.
+var (
+	ErrTooManyDottedNames     = errors.New("improper qualified name (too many dotted names)")
+	ErrCrossDatabaseReference = errors.New("cross-database references are not implemented")
+	ErrUnterminatedQuote      = errors.New("unterminated quoted identifier")
+)
+
+// PatternError reports the pattern a command rejected.
+type PatternError struct {
+	Pattern string
+	Err     error
+}
+
+func (e *PatternError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Err, e.Pattern)
+}
+
+func (e *PatternError) Unwrap() error {
+	return e.Err
+}
+
+// NamePattern is a parsed psql object name pattern such as
+// mydb.public.user*. Schema and Name are anchored POSIX regular expressions
+// for the server's ~ operator, while Database is the literal database name.
+// A part that is absent or empty in the pattern is "" and matches anything.
+type NamePattern struct {
+	Database string
+	Schema   string
+	Name     string
+}
+
+// ParseNamePattern parses pattern following psql's rules: unquoted letters
+// are folded to lower case, * and ? are wildcards, double quotes protect
+// their content and "" inside them stands for a quote. Dots outside quotes
+// separate at most maxParts parts, which are read from the right: the last
+// part is the name, and for maxParts 3 the ones before it are the schema and
+// database. For maxParts 2, used by objects that do not live in a schema, the
+// qualifier is the database.
+func ParseNamePattern(pattern string, maxParts int) (NamePattern, error) {
+	type part struct{ regex, literal string }
+	var parts []part
+	var re, lit strings.Builder
+	inQuotes := false
 
 	for i := 0; i < len(pattern); i++ {
 		c := pattern[i]
@@ -15,40 +230,136 @@ func sqlNamePattern(pattern string) (schema, table string) {
 		switch {
 		case c == '"':
 			if inQuotes && i+1 < len(pattern) && pattern[i+1] == '"' {
-				buf.WriteByte('"')
+				re.WriteByte('"')
+				lit.WriteByte('"')
 				i++
 			} else {
 				inQuotes = !inQuotes
 			}
 
 		case !inQuotes && c >= 'A' && c <= 'Z':
-			buf.WriteByte(byte(c + 32))
+			re.WriteByte(c + 32)
+			lit.WriteByte(c + 32)
 
 		case !inQuotes && c == '*':
-			buf.WriteString(".*")
+			re.WriteString(".*")
+			lit.WriteByte(c)
 
 		case !inQuotes && c == '?':
-			buf.WriteByte('.')
+			re.WriteByte('.')
+			lit.WriteByte(c)
 
 		case !inQuotes && c == '.':
-			s := buf.String()
-			schemaBuf = &s
-			buf.Reset()
+			parts = append(parts, part{re.String(), lit.String()})
+			re.Reset()
+			lit.Reset()
 
 		default:
 			if c == '$' || (inQuotes && strings.ContainsRune("|*+?()[]{}.^\\", rune(c))) {
-				buf.WriteByte('\\')
+				re.WriteByte('\\')
 			}
-			buf.WriteByte(c)
+			re.WriteByte(c)
+			lit.WriteByte(c)
 		}
 	}
+	parts = append(parts, part{re.String(), lit.String()})
 
-	if buf.Len() > 0 {
-		table = "^(" + buf.String() + ")$"
+	if inQuotes {
+		return NamePattern{}, &PatternError{Pattern: pattern, Err: ErrUnterminatedQuote}
 	}
-	if schemaBuf != nil {
-		schema = "^(" + *schemaBuf + ")$"
+	if len(parts) > maxParts {
+		return NamePattern{}, &PatternError{Pattern: pattern, Err: ErrTooManyDottedNames}
 	}
 
-	return schema, table
+	anchor := func(s string) string {
+		if s == "" {
+			return ""
+		}
+		return "^(" + s + ")$"
+	}
+
+	var np NamePattern
+	np.Name = anchor(parts[len(parts)-1].regex)
+	switch {
+	case len(parts) == 3:
+		np.Database = parts[0].literal
+		np.Schema = anchor(parts[1].regex)
+	case len(parts) == 2 && maxParts == 2:
+		np.Database = parts[0].literal
+	case len(parts) == 2:
+		np.Schema = anchor(parts[0].regex)
+	}
+	return np, nil
+}
+
+// parsePatterns splits args into patterns and parses each with
+// ParseNamePattern. Like psql, a database qualifier must name the current
+// database; it is only checked, with one extra query, when a pattern has one.
+// psql knows the database without asking, so the query is sent past the
+// recording of ECHO_HIDDEN: it is neither echoed nor stopped by noexec.
+func parsePatterns(ctx context.Context, db database.Queryer, args string, maxParts int) ([]NamePattern, error) {
+	raw := splitPatterns(args)
+	patterns := make([]NamePattern, 0, len(raw))
+	qualified := false
+	for _, p := range raw {
+		np, err := ParseNamePattern(p, maxParts)
+		if err != nil {
+			return nil, err
+		}
+		qualified = qualified || np.Database != ""
+		patterns = append(patterns, np)
+	}
+	if !qualified {
+		return patterns, nil
+	}
+
+	var current string
+	err := pgxspecial.UnwrapQueryer(db).QueryRow(ctx, "SELECT pg_catalog.current_database()").Scan(&current)
+	if err != nil {
+		return nil, err
+	}
+	for i, np := range patterns {
+		if np.Database != "" && np.Database != current {
+			return nil, &PatternError{Pattern: raw[i], Err: ErrCrossDatabaseReference}
+		}
+	}
+	return patterns, nil
+}
+
+// patternFilter returns a condition selecting the rows matched by any of
+// patterns, together with args extended by the regular expressions it
+// references. Matching every pattern in one query yields a single result in
+// which an object matched by several patterns appears once.
+//
+// schemaCond and nameCond are fmt templates that test the schema and name part
+// of a pattern against the placeholder passed as %[1]s; an empty schemaCond
+// ignores the schema part. Patterns without a schema part, and an empty
+// pattern list, are restricted by unqualified instead, typically a visibility
+// check. unqualified may be empty, in which case an empty pattern list yields
+// an empty condition.
+func patternFilter(patterns []NamePattern, schemaCond, nameCond, unqualified string, args []any) (string, []any) {
+	if len(patterns) == 0 {
+		return unqualified, args
+	}
+
+	alternatives := make([]string, 0, len(patterns))
+	for _, pattern := range patterns {
+		var conds []string
+
+		if pattern.Schema != "" && schemaCond != "" {
+			args = append(args, pattern.Schema)
+			conds = append(conds, fmt.Sprintf(schemaCond, "$"+strconv.Itoa(len(args))))
+		} else if unqualified != "" {
+			conds = append(conds, unqualified)
+		}
+		if pattern.Name != "" {
+			args = append(args, pattern.Name)
+			conds = append(conds, fmt.Sprintf(nameCond, "$"+strconv.Itoa(len(args))))
+		}
+		if len(conds) == 0 {
+			conds = append(conds, "true")
+		}
+		alternatives = append(alternatives, "("+strings.Join(conds, " AND ")+")")
+	}
+	return "(" + strings.Join(alternatives, "\n    OR ") + ")", args
 }
@@ -3,10 +3,12 @@ package dbcommands
 import (
 	"testing"
 
+	"github.com/balaji01-4d/pgxspecial"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
-func TestSqlNamePattern(t *testing.T) {
+func TestParseNamePattern(t *testing.T) {
 	tests := []struct {
 		name           string
 		pattern        string
@@ -77,9 +79,172 @@ func TestSqlNamePattern(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			schema, table := sqlNamePattern(tt.pattern)
-			assert.Equal(t, tt.expectedSchema, schema)
-			assert.Equal(t, tt.expectedTable, table)
+			np, err := ParseNamePattern(tt.pattern, 3)
+			require.NoError(t, err)
+			assert.Equal(t, "", np.Database)
+			assert.Equal(t, tt.expectedSchema, np.Schema)
+			assert.Equal(t, tt.expectedTable, np.Name)
 		})
 	}
 }
+
+func TestParseNamePatternQualified(t *testing.T) {
+	np, err := ParseNamePattern(`MyDB.public.user*`, 3)
+	require.NoError(t, err)
+	assert.Equal(t, NamePattern{Database: "mydb", Schema: "^(public)$", Name: "^(user.*)$"}, np)
+
+	// the database is compared literally, quotes keep its case
+	np, err = ParseNamePattern(`"My.DB".s.t`, 3)
+	require.NoError(t, err)
+	assert.Equal(t, "My.DB", np.Database)
+
+	// objects outside schemas take the database as their only qualifier
+	np, err = ParseNamePattern("mydb.pub*", 2)
+	require.NoError(t, err)
+	assert.Equal(t, NamePattern{Database: "mydb", Name: "^(pub.*)$"}, np)
+
+	// empty parts match anything
+	np, err = ParseNamePattern("public.", 3)
+	require.NoError(t, err)
+	assert.Equal(t, NamePattern{Schema: "^(public)$"}, np)
+
+	np, err = ParseNamePattern("..users", 3)
+	require.NoError(t, err)
+	assert.Equal(t, NamePattern{Name: "^(users)$"}, np)
+}
+
+func TestParseNamePatternErrors(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		maxParts int
+		expected error
+	}{
+		{"a.b.c.d", 3, ErrTooManyDottedNames},
+		{"mydb.public.users", 2, ErrTooManyDottedNames},
+		{"public.postgres", 1, ErrTooManyDottedNames},
+		{"a...", 3, ErrTooManyDottedNames},
+		{`"users`, 3, ErrUnterminatedQuote},
+		{`public."a""b`, 3, ErrUnterminatedQuote},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			_, err := ParseNamePattern(tt.pattern, tt.maxParts)
+			require.ErrorIs(t, err, tt.expected)
+
+			var perr *PatternError
+			require.ErrorAs(t, err, &perr)
+			assert.Equal(t, tt.pattern, perr.Pattern)
+			assert.Equal(t, tt.expected.Error()+": "+tt.pattern, err.Error())
+		})
+	}
+
+	// dots inside quotes do not count
+	_, err := ParseNamePattern(`"a.b.c.d"`, 1)
+	assert.NoError(t, err)
+}
+
+func TestSplitPatterns(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     string
+		expected []string
+	}{
+		{
+			name:     "empty",
+			args:     "   ",
+			expected: nil,
+		},
+		{
+			name:     "single pattern",
+			args:     "users",
+			expected: []string{"users"},
+		},
+		{
+			name:     "several patterns",
+			args:     " users\torders  public.items ",
+			expected: []string{"users", "orders", "public.items"},
+		},
+		{
+			name:     "whitespace inside quotes",
+			args:     `"order items" users`,
+			expected: []string{`"order items"`, "users"},
+		},
+		{
+			name:     "quoted part of a pattern",
+			args:     `"My Schema".t* x`,
+			expected: []string{`"My Schema".t*`, "x"},
+		},
+		{
+			name:     "escaped quote inside quotes",
+			args:     `"a"" b" c`,
+			expected: []string{`"a"" b"`, "c"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, splitPatterns(tt.args))
+		})
+	}
+}
+
+func TestPatternFilter(t *testing.T) {
+	filter, args := patternFilter(nil, "n.nspname ~ %[1]s", "c.relname ~ %[1]s", "visible(c.oid)", []any{"r"})
+	assert.Equal(t, "visible(c.oid)", filter)
+	assert.Equal(t, []any{"r"}, args)
+
+	patterns := []NamePattern{{Name: "^(users)$"}, {Schema: "^(s)$", Name: "^(o.*)$"}}
+	filter, args = patternFilter(patterns, "n.nspname ~ %[1]s", "c.relname ~ %[1]s", "visible(c.oid)", []any{"r"})
+	assert.Equal(t, "((visible(c.oid) AND c.relname ~ $2)\n    OR (n.nspname ~ $3 AND c.relname ~ $4))", filter)
+	assert.Equal(t, []any{"r", "^(users)$", "^(s)$", "^(o.*)$"}, args)
+
+	filter, args = patternFilter(nil, "", "r.rolname ~ %[1]s", "", nil)
+	assert.Equal(t, "", filter)
+	assert.Empty(t, args)
+
+	patterns = []NamePattern{{Database: "a", Name: "^(b)$"}, {Schema: "^(public)$"}}
+	filter, args = patternFilter(patterns, "", "r.rolname ~ %[1]s", "", nil)
+	assert.Equal(t, "((r.rolname ~ $1)\n    OR (true))", filter)
+	assert.Equal(t, []any{"^(b)$"}, args)
+}
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     string
+		expected []string
+	}{
+		{"empty", "  ", nil},
+		{"words", "a  b\tc", []string{"a", "b", "c"}},
+		{"single quotes", `'hello world' x`, []string{"hello world", "x"}},
+		{"doubled quote", `'it''s'`, []string{"it's"}},
+		{"escapes", `'a\nb\t\\\'\101\x42'`, []string{"a\nb\t\\'AB"}},
+		{"adjacent parts", `pre'fix'post`, []string{"prefixpost"}},
+		{"double quotes kept", `"a b" c`, []string{`"a b"`, "c"}},
+		{"backquotes kept", "`date` x", []string{"`date`", "x"}},
+		{"empty quotes", `''`, []string{""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			words, err := splitArgs(tt.args, nil)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, words)
+		})
+	}
+
+	for _, args := range []string{`'open`, `"open`, "`open", `'a\'`} {
+		_, err := splitArgs(args, nil)
+		assert.EqualError(t, err, "unterminated quoted string", args)
+	}
+}
+
+func TestSplitArgsVariables(t *testing.T) {
+	session := pgxspecial.NewSession()
+	require.NoError(t, session.Set("v", "a b"))
+
+	words, err := splitArgs(`x:v :'v' ':v' ":v" :nope 1::int`, session)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"xa b", "'a b'", ":v", `":v"`, ":nope", "1::int"}, words)
+}
@@ -0,0 +1,71 @@
+package dbcommands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/balaji01-4d/pgxspecial"
+	"github.com/balaji01-4d/pgxspecial/database"
+)
+
+func init() {
+	pgxspecial.MustRegisterCommand(pgxspecial.SpecialCommandRegistry{
+		Cmd:           "\\set",
+		Description:   "Set internal variable, or list all if no parameters.",
+		Group:         pgxspecial.GroupVariables,
+		Syntax:        "\\set [NAME [VALUE]]",
+		Handler:       SetVariable,
+		CaseSensitive: true,
+		RawArgs:       true,
+	})
+
+	pgxspecial.MustRegisterCommand(pgxspecial.SpecialCommandRegistry{
+		Cmd:           "\\unset",
+		Description:   "Unset (delete) internal variable.",
+		Group:         pgxspecial.GroupVariables,
+		Syntax:        "\\unset NAME",
+		Handler:       UnsetVariable,
+		CaseSensitive: true,
+		RawArgs:       true,
+	})
+}
+
+// SetVariable sets a variable of the session carried by ctx. Like psql, the
+// words following the name are concatenated to form the value, so values
+// containing whitespace must be quoted: \set greeting 'hello world'. Without
+// arguments it lists every variable.
+func SetVariable(ctx context.Context, db database.Queryer, args string, opts pgxspecial.CommandOptions) (pgxspecial.SpecialCommandResult, error) {
+	session := pgxspecial.SessionFromContext(ctx)
+	if session == nil {
+		return nil, pgxspecial.ErrNoSession
+	}
+
+	words, err := splitArgs(args, session)
+	if err != nil {
+		return nil, err
+	}
+	if len(words) == 0 {
+		return pgxspecial.VariablesResult{Variables: session.Variables()}, nil
+	}
+
+	return nil, session.Set(words[0], strings.Join(words[1:], ""))
+}
+
+// UnsetVariable removes a variable from the session carried by ctx.
+func UnsetVariable(ctx context.Context, db database.Queryer, args string, opts pgxspecial.CommandOptions) (pgxspecial.SpecialCommandResult, error) {
+	session := pgxspecial.SessionFromContext(ctx)
+	if session == nil {
+		return nil, pgxspecial.ErrNoSession
+	}
+
+	words, err := splitArgs(args, session)
+	if err != nil {
+		return nil, err
+	}
+	if len(words) == 0 {
+		return nil, fmt.Errorf("missing required argument")
+	}
+
+	return nil, session.Unset(words[0])
+}
@@ -0,0 +1,82 @@
+package dbcommands_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/balaji01-4d/pgxspecial"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetVariable(t *testing.T) {
+	session := pgxspecial.NewSession()
+	ctx := pgxspecial.WithSession(context.Background(), session)
+
+	for _, cmd := range []string{
+		"\\set greeting 'hello world'",
+		"\\set joined a b 'c d'",
+		"\\set empty",
+		"\\set copy :greeting",
+		"\\set tab 'a\\tb'",
+	} {
+		res, isSpecial, err := pgxspecial.ExecuteSpecialCommand(ctx, nil, cmd)
+		require.NoError(t, err, cmd)
+		assert.True(t, isSpecial)
+		assert.Nil(t, res)
+	}
+
+	expected := map[string]string{
+		"greeting": "hello world",
+		"joined":   "abc d",
+		"empty":    "",
+		"copy":     "hello world",
+		"tab":      "a\tb",
+	}
+	for name, value := range expected {
+		v, ok := session.Get(name)
+		assert.True(t, ok, name)
+		assert.Equal(t, value, v, name)
+	}
+
+	_, _, err := pgxspecial.ExecuteSpecialCommand(ctx, nil, "\\set ON_ERROR_STOP maybe")
+	assert.Error(t, err)
+
+	_, _, err = pgxspecial.ExecuteSpecialCommand(ctx, nil, "\\set broken 'value")
+	assert.EqualError(t, err, "unterminated quoted string")
+}
+
+func TestSetVariableList(t *testing.T) {
+	session := pgxspecial.NewSession()
+	require.NoError(t, session.Set("foo", "bar"))
+	ctx := pgxspecial.WithSession(context.Background(), session)
+
+	res, _, err := pgxspecial.ExecuteSpecialCommand(ctx, nil, "\\set")
+	require.NoError(t, err)
+	require.Equal(t, pgxspecial.ResultKindVariables, res.ResultKind())
+
+	vars := res.(pgxspecial.VariablesResult).Variables
+	assert.Contains(t, vars, pgxspecial.Variable{Name: "foo", Value: "bar"})
+	assert.Contains(t, vars, pgxspecial.Variable{Name: pgxspecial.VarOnErrorStop, Value: "off"})
+}
+
+func TestUnsetVariable(t *testing.T) {
+	session := pgxspecial.NewSession()
+	require.NoError(t, session.Set("foo", "bar"))
+	ctx := pgxspecial.WithSession(context.Background(), session)
+
+	_, _, err := pgxspecial.ExecuteSpecialCommand(ctx, nil, "\\unset foo")
+	require.NoError(t, err)
+	_, ok := session.Get("foo")
+	assert.False(t, ok)
+
+	_, _, err = pgxspecial.ExecuteSpecialCommand(ctx, nil, "\\unset")
+	assert.EqualError(t, err, "missing required argument")
+}
+
+func TestVariablesRequireSession(t *testing.T) {
+	for _, cmd := range []string{"\\set foo bar", "\\unset foo"} {
+		_, _, err := pgxspecial.ExecuteSpecialCommand(context.Background(), nil, cmd)
+		assert.ErrorIs(t, err, pgxspecial.ErrNoSession, cmd)
+	}
+}
@@ -0,0 +1,220 @@
+package dbcommands_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/balaji01-4d/pgxspecial"
+	"github.com/balaji01-4d/pgxspecial/database"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// versionConn pretends to be a server of the given version, connected to
+// database. It records the catalog queries it receives and answers them with
+// no rows.
+type versionConn struct {
+	version  int
+	database string
+	queries  []string
+	args     [][]any
+}
+
+func (c *versionConn) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	c.queries = append(c.queries, sql)
+	c.args = append(c.args, args)
+	return &fakeRows{}, nil
+}
+
+func (c *versionConn) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	if strings.Contains(sql, "server_version_num") {
+		return versionRow(c.version)
+	}
+	if strings.Contains(sql, "current_database") {
+		return databaseRow(c.database)
+	}
+	return versionRow(-1)
+}
+
+// versionRow scans a server version, or fails if it is negative.
+type versionRow int
+
+func (r versionRow) Scan(dest ...any) error {
+	if r < 0 {
+		return errors.New("not implemented")
+	}
+	*dest[0].(*int) = int(r)
+	return nil
+}
+
+// databaseRow scans the name of the current database.
+type databaseRow string
+
+func (r databaseRow) Scan(dest ...any) error {
+	*dest[0].(*string) = string(r)
+	return nil
+}
+
+// serverVersions are the major versions the catalog queries are tested
+// against.
+var serverVersions = []int{100000, 110000, 120000, 130000, 140000, 150000, 160000, 170000, 180000}
+
+// TestCatalogQueriesByServerVersion documents which catalog columns the
+// commands query on which server versions, following psql.
+func TestCatalogQueriesByServerVersion(t *testing.T) {
+	tests := []struct {
+		command  string
+		fragment string
+		since    int // first version querying fragment
+		until    int // first version no longer querying it, 0 if none
+	}{
+		{"\\df", "p.proisagg", 0, 110000},
+		{"\\df", "p.prokind", 110000, 0},
+		{"\\df", "'proc'", 110000, 0},
+		{"\\df+", "pg_get_function_sqlbody", 140000, 0},
+		{"\\l", "d.datcollate", 0, 0},
+		{"\\l", "as locale_provider", 150000, 0},
+		{"\\l", "d.daticulocale as icu_locale", 150000, 170000},
+		{"\\l", "d.datlocale as locale", 170000, 0},
+		{"\\l", "d.daticurules as icu_rules", 160000, 0},
+		{"\\dt+", "am.amname as access_method", 120000, 0},
+		{"\\dm+", "am.amname as access_method", 120000, 0},
+		{"\\di+", "am.amname as access_method", 120000, 0},
+		{"\\db", "pg_tablespace_location", 0, 0},
+		{"\\du", "as memberof", 0, 160000},
+		{"\\du", "r.rolreplication", 0, 0},
+		{"\\dn+", "n.nspacl", 0, 0},
+		{"\\dp", "polpermissive", 0, 0},
+		{"\\ddp", "'large object'", 180000, 0},
+		{"\\dT+", "pg_catalog.pg_enum", 0, 0},
+		{"\\dD", "r.contype = 'c'", 170000, 0},
+		{"\\dx", "ae.default_version", 180000, 0},
+		{"\\dE+", "pg_table_size", 0, 0},
+	}
+
+	for _, version := range serverVersions {
+		for _, tt := range tests {
+			t.Run(fmt.Sprintf("%d/%s/%s", version/10000, tt.command, tt.fragment), func(t *testing.T) {
+				db := &versionConn{version: version}
+				_, _, err := pgxspecial.ExecuteSpecialCommand(context.Background(), db, tt.command)
+				require.NoError(t, err)
+				require.NotEmpty(t, db.queries)
+
+				sql := strings.Join(db.queries, "\n")
+				if version >= tt.since && (tt.until == 0 || version < tt.until) {
+					assert.Contains(t, sql, tt.fragment)
+				} else {
+					assert.NotContains(t, sql, tt.fragment)
+				}
+			})
+		}
+	}
+}
+
+func TestCatalogQueriesWithoutAccessMethod(t *testing.T) {
+	for _, command := range []string{"\\dv+", "\\ds+", "\\dt"} {
+		db := &versionConn{version: 180000}
+		_, _, err := pgxspecial.ExecuteSpecialCommand(context.Background(), db, command)
+		require.NoError(t, err)
+		assert.NotContains(t, db.queries[0], "access_method", command)
+	}
+}
+
+func TestListIndexesPartitioned(t *testing.T) {
+	for _, version := range serverVersions {
+		db := &versionConn{version: version}
+		_, _, err := pgxspecial.ExecuteSpecialCommand(context.Background(), db, "\\di")
+		require.NoError(t, err)
+
+		relkinds := db.args[0][0].([]string)
+		assert.Equal(t, version >= 110000, slices.Contains(relkinds, "I"), "version %d", version)
+	}
+
+	// the relkinds of the registered commands are left alone
+	db := &versionConn{version: 180000}
+	_, _, err := pgxspecial.ExecuteSpecialCommand(context.Background(), db, "\\di")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"i", "s", "", "I"}, db.args[0][0])
+}
+
+func TestCatalogQueriesVersionError(t *testing.T) {
+	db := &fakeConn{}
+	_, _, err := pgxspecial.ExecuteSpecialCommand(context.Background(), &failingVersionConn{db}, "\\l")
+	assert.EqualError(t, err, "no version")
+	assert.Empty(t, db.queries)
+}
+
+// failingVersionConn fails to tell its server version.
+type failingVersionConn struct {
+	*fakeConn
+}
+
+func (c *failingVersionConn) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return failingRow{errors.New("no version")}
+}
+
+type failingRow struct {
+	err error
+}
+
+func (r failingRow) Scan(dest ...any) error {
+	return r.err
+}
+
+// serverVersion returns the version of the test database.
+func serverVersion(t *testing.T, db database.Queryer) int {
+	t.Helper()
+	version, err := pgxspecial.ServerVersion(context.Background(), db)
+	require.NoError(t, err)
+	return version
+}
+
+// roleColumns returns the columns \du lists on a server version.
+func roleColumns(version int, verbose bool) []string {
+	columns := []string{"rolname", "rolsuper", "rolinherit", "rolcreaterole",
+		"rolcreatedb", "rolcanlogin", "rolconnlimit", "rolvaliduntil"}
+	if version < 160000 {
+		columns = append(columns, "memberof")
+	}
+	if verbose {
+		columns = append(columns, "description")
+	}
+	return append(columns, "rolreplication")
+}
+
+// extensionColumns returns the columns \dx lists on a server version.
+func extensionColumns(version int) []string {
+	columns := []string{"name", "version"}
+	if version >= 180000 {
+		columns = append(columns, "default_version")
+	}
+	return append(columns, "schema", "description")
+}
+
+// databaseColumns returns the columns \l lists on a server version.
+func databaseColumns(version int, verbose bool) []string {
+	columns := []string{"name", "owner", "encoding"}
+	if version >= 150000 {
+		columns = append(columns, "locale_provider")
+	}
+	columns = append(columns, "collate", "ctype")
+	switch {
+	case version >= 170000:
+		columns = append(columns, "locale")
+	case version >= 150000:
+		columns = append(columns, "icu_locale")
+	}
+	if version >= 160000 {
+		columns = append(columns, "icu_rules")
+	}
+	columns = append(columns, "access_privileges")
+	if verbose {
+		columns = append(columns, "size", "Tablespace", "description")
+	}
+	return columns
+}
@@ -0,0 +1,395 @@
+package pgxspecial
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ExpandedMode selects when results are displayed as vertical records, one
+// line per column, rather than as tables. It is set by \x.
+type ExpandedMode int
+
+const (
+	ExpandedOff ExpandedMode = iota
+	ExpandedOn
+	// ExpandedAuto expands the tables too wide for the terminal.
+	ExpandedAuto
+)
+
+// String returns the name \x and \pset use for the mode.
+func (m ExpandedMode) String() string {
+	switch m {
+	case ExpandedOn:
+		return "on"
+	case ExpandedAuto:
+		return "auto"
+	}
+	return "off"
+}
+
+// ParseExpandedMode parses the value of psql's expanded option: "auto" or a
+// boolean as accepted by ParseBool.
+func ParseExpandedMode(value string) (ExpandedMode, error) {
+	if strings.EqualFold(value, "auto") {
+		return ExpandedAuto, nil
+	}
+	if b, ok := ParseBool(value); ok {
+		if b {
+			return ExpandedOn, nil
+		}
+		return ExpandedOff, nil
+	}
+	return ExpandedOff, fmt.Errorf("unrecognized value %q for \"expanded\": available values are on, off, auto", value)
+}
+
+// Output formats, the values of the format print option.
+const (
+	FormatAligned   = "aligned"
+	FormatUnaligned = "unaligned"
+	FormatHTML      = "html"
+	FormatCSV       = "csv"
+)
+
+// Line styles of the aligned format, the values of the linestyle print
+// option.
+const (
+	LineStyleASCII    = "ascii"
+	LineStyleOldASCII = "old-ascii"
+	LineStyleUnicode  = "unicode"
+)
+
+// Unicode line styles, the values of the unicode_border_linestyle print
+// option.
+const (
+	UnicodeSingle = "single"
+	UnicodeDouble = "double"
+)
+
+// PrintOptions holds the display settings of a session, which psql manages
+// with \pset. Use DefaultPrintOptions for psql's defaults.
+type PrintOptions struct {
+	Format                 string       // FormatAligned, FormatUnaligned, FormatHTML or FormatCSV
+	Border                 int          // 0, 1 or 2
+	Expanded               ExpandedMode // set by \x
+	LineStyle              string       // LineStyleASCII, LineStyleOldASCII or LineStyleUnicode
+	UnicodeBorderLineStyle string       // UnicodeSingle or UnicodeDouble
+	Null                   string       // text shown for NULL values
+	FieldSep               string       // field separator of the unaligned format
+	CSVFieldSep            string       // field separator of the csv format, a single character
+	RecordSep              string       // record separator of the unaligned format
+	TuplesOnly             bool         // print the rows only, without title, headers and footers
+	Footer                 bool         // print the number of rows below query results
+	Title                  string       // title of the results without one of their own
+	NumericLocale          bool         // group the digits of numbers in thousands
+	Columns                int          // width expanded auto mode fits tables in, 0 for the terminal's
+}
+
+// DefaultPrintOptions returns the display settings psql starts with.
+func DefaultPrintOptions() PrintOptions {
+	return PrintOptions{
+		Format:                 FormatAligned,
+		Border:                 1,
+		LineStyle:              LineStyleASCII,
+		UnicodeBorderLineStyle: UnicodeSingle,
+		FieldSep:               "|",
+		CSVFieldSep:            ",",
+		RecordSep:              "\n",
+		Footer:                 true,
+	}
+}
+
+// printOption describes a \pset option: how to set it from a value, how
+// \pset without a value changes it, how \pset lists its value and the
+// message confirming it.
+type printOption struct {
+	set    func(o *PrintOptions, value string) error
+	toggle func(o *PrintOptions)
+	value  func(o *PrintOptions) string
+	status func(o *PrintOptions) string
+}
+
+// boolOption describes a boolean option, which \pset toggles.
+func boolOption(name string, field func(o *PrintOptions) *bool, status string) printOption {
+	return printOption{
+		set: func(o *PrintOptions, value string) error {
+			b, ok := ParseBool(value)
+			if !ok {
+				return fmt.Errorf("unrecognized value %q for %q: Boolean expected", value, name)
+			}
+			*field(o) = b
+			return nil
+		},
+		toggle: func(o *PrintOptions) { *field(o) = !*field(o) },
+		value:  func(o *PrintOptions) string { return onOff(*field(o)) },
+		status: func(o *PrintOptions) string { return status + " is " + onOff(*field(o)) + "." },
+	}
+}
+
+// intOption describes an integer option.
+func intOption(name string, field func(o *PrintOptions) *int) printOption {
+	return printOption{
+		set: func(o *PrintOptions, value string) error {
+			n, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil || n < 0 {
+				return fmt.Errorf("invalid value %q for %q: integer expected", value, name)
+			}
+			*field(o) = n
+			return nil
+		},
+		value: func(o *PrintOptions) string { return strconv.Itoa(*field(o)) },
+	}
+}
+
+// stringOption describes an option holding any text, such as a separator.
+func stringOption(field func(o *PrintOptions) *string) printOption {
+	return printOption{
+		set: func(o *PrintOptions, value string) error {
+			*field(o) = value
+			return nil
+		},
+		value: func(o *PrintOptions) string { return quotePrintOption(*field(o)) },
+	}
+}
+
+// enumOption describes an option taking one of values, or an abbreviation.
+func enumOption(field func(o *PrintOptions) *string, values []string, invalid string) printOption {
+	return printOption{
+		set: func(o *PrintOptions, value string) error {
+			v, ok := matchAbbreviation(value, values)
+			if !ok {
+				return fmt.Errorf("%s %s", invalid, strings.Join(values, ", "))
+			}
+			*field(o) = v
+			return nil
+		},
+		value: func(o *PrintOptions) string { return *field(o) },
+	}
+}
+
+var printOptions map[string]printOption
+
+func init() {
+	border := intOption("border", func(o *PrintOptions) *int { return &o.Border })
+	border.status = func(o *PrintOptions) string { return fmt.Sprintf("Border style is %d.", o.Border) }
+
+	columns := intOption("columns", func(o *PrintOptions) *int { return &o.Columns })
+	columns.status = func(o *PrintOptions) string {
+		if o.Columns == 0 {
+			return "Target width is unset."
+		}
+		return fmt.Sprintf("Target width is %d.", o.Columns)
+	}
+
+	expanded := printOption{
+		set: func(o *PrintOptions, value string) (err error) {
+			o.Expanded, err = ParseExpandedMode(value)
+			return err
+		},
+		toggle: func(o *PrintOptions) {
+			if o.Expanded == ExpandedOff {
+				o.Expanded = ExpandedOn
+			} else {
+				o.Expanded = ExpandedOff
+			}
+		},
+		value: func(o *PrintOptions) string { return o.Expanded.String() },
+		status: func(o *PrintOptions) string {
+			if o.Expanded == ExpandedAuto {
+				return "Expanded display is used automatically."
+			}
+			return "Expanded display is " + o.Expanded.String() + "."
+		},
+	}
+
+	// the separator must keep the fields of a csv record apart
+	csvFieldsep := stringOption(func(o *PrintOptions) *string { return &o.CSVFieldSep })
+	csvFieldsep.set = func(o *PrintOptions, value string) error {
+		if len(value) != 1 {
+			return errors.New("csv_fieldsep must be a single one-byte character")
+		}
+		if value == "\"" || value == "\n" || value == "\r" {
+			return errors.New("csv_fieldsep cannot be a double quote, a newline, or a carriage return")
+		}
+		o.CSVFieldSep = value
+		return nil
+	}
+	csvFieldsep.status = func(o *PrintOptions) string {
+		return fmt.Sprintf("Field separator for CSV is \"%s\".", o.CSVFieldSep)
+	}
+
+	fieldsep := stringOption(func(o *PrintOptions) *string { return &o.FieldSep })
+	fieldsep.status = func(o *PrintOptions) string { return fmt.Sprintf("Field separator is \"%s\".", o.FieldSep) }
+
+	format := enumOption(func(o *PrintOptions) *string { return &o.Format },
+		[]string{FormatAligned, FormatCSV, FormatHTML, FormatUnaligned}, "allowed formats are")
+	format.status = func(o *PrintOptions) string { return "Output format is " + o.Format + "." }
+
+	linestyle := enumOption(func(o *PrintOptions) *string { return &o.LineStyle },
+		[]string{LineStyleASCII, LineStyleOldASCII, LineStyleUnicode}, "allowed line styles are")
+	linestyle.status = func(o *PrintOptions) string { return "Line style is " + o.LineStyle + "." }
+
+	null := stringOption(func(o *PrintOptions) *string { return &o.Null })
+	null.status = func(o *PrintOptions) string { return fmt.Sprintf("Null display is \"%s\".", o.Null) }
+
+	recordsep := stringOption(func(o *PrintOptions) *string { return &o.RecordSep })
+	recordsep.status = func(o *PrintOptions) string {
+		if o.RecordSep == "\n" {
+			return "Record separator is <newline>."
+		}
+		return fmt.Sprintf("Record separator is \"%s\".", o.RecordSep)
+	}
+
+	// \pset title without a value unsets the title
+	title := stringOption(func(o *PrintOptions) *string { return &o.Title })
+	title.toggle = func(o *PrintOptions) { o.Title = "" }
+	title.value = func(o *PrintOptions) string {
+		if o.Title == "" {
+			return ""
+		}
+		return quotePrintOption(o.Title)
+	}
+	title.status = func(o *PrintOptions) string {
+		if o.Title == "" {
+			return "Title is unset."
+		}
+		return fmt.Sprintf("Title is \"%s\".", o.Title)
+	}
+
+	unicodeBorder := enumOption(func(o *PrintOptions) *string { return &o.UnicodeBorderLineStyle },
+		[]string{UnicodeSingle, UnicodeDouble}, "allowed Unicode border line styles are")
+	unicodeBorder.status = func(o *PrintOptions) string {
+		return fmt.Sprintf("Unicode border line style is \"%s\".", o.UnicodeBorderLineStyle)
+	}
+
+	printOptions = map[string]printOption{
+		"border":                   border,
+		"columns":                  columns,
+		"csv_fieldsep":             csvFieldsep,
+		"expanded":                 expanded,
+		"fieldsep":                 fieldsep,
+		"footer":                   boolOption("footer", func(o *PrintOptions) *bool { return &o.Footer }, "Default footer"),
+		"format":                   format,
+		"linestyle":                linestyle,
+		"null":                     null,
+		"numericlocale":            boolOption("numericlocale", func(o *PrintOptions) *bool { return &o.NumericLocale }, "Locale-adjusted numeric output"),
+		"recordsep":                recordsep,
+		"title":                    title,
+		"tuples_only":              boolOption("tuples_only", func(o *PrintOptions) *bool { return &o.TuplesOnly }, "Tuples only"),
+		"unicode_border_linestyle": unicodeBorder,
+	}
+}
+
+// printOptionAliases are the short names \pset also accepts.
+var printOptionAliases = map[string]string{
+	"C": "title",
+	"t": "tuples_only",
+	"x": "expanded",
+}
+
+func lookupPrintOption(name string) (printOption, error) {
+	if alias, ok := printOptionAliases[name]; ok {
+		name = alias
+	}
+	opt, ok := printOptions[name]
+	if !ok {
+		return printOption{}, fmt.Errorf("unknown option: %s", name)
+	}
+	return opt, nil
+}
+
+// Set sets the print option name, as named by \pset, to value. Values of
+// format, linestyle and unicode_border_linestyle may be abbreviated, and
+// expanded, tuples_only and title may be named x, t and C.
+func (o *PrintOptions) Set(name, value string) error {
+	opt, err := lookupPrintOption(name)
+	if err != nil {
+		return err
+	}
+	return opt.set(o, value)
+}
+
+// Toggle changes the print option name as \pset does when given no value:
+// boolean options and expanded are toggled and title is unset, while the
+// other options are left unchanged.
+func (o *PrintOptions) Toggle(name string) error {
+	opt, err := lookupPrintOption(name)
+	if err != nil {
+		return err
+	}
+	if opt.toggle != nil {
+		opt.toggle(o)
+	}
+	return nil
+}
+
+// Status returns the message psql confirms the value of the print option
+// name with, such as "Border style is 2.".
+func (o PrintOptions) Status(name string) (string, error) {
+	opt, err := lookupPrintOption(name)
+	if err != nil {
+		return "", err
+	}
+	return opt.status(&o), nil
+}
+
+// Values returns every print option with its value as \pset lists them,
+// sorted by name. Text values are quoted.
+func (o PrintOptions) Values() []Variable {
+	values := make([]Variable, 0, len(printOptions))
+	for name, opt := range printOptions {
+		values = append(values, Variable{Name: name, Value: opt.value(&o)})
+	}
+	sort.Slice(values, func(i, j int) bool {
+		return values[i].Name < values[j].Name
+	})
+	return values
+}
+
+// matchAbbreviation returns the value of values that s names, ignoring
+// case: the value itself or the first value it is a prefix of.
+func matchAbbreviation(s string, values []string) (string, bool) {
+	if s == "" {
+		return "", false
+	}
+	for _, v := range values {
+		if strings.EqualFold(s, v) {
+			return v, true
+		}
+	}
+	for _, v := range values {
+		if len(s) < len(v) && strings.EqualFold(s, v[:len(s)]) {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// quotePrintOption quotes a text value as \pset lists it.
+func quotePrintOption(s string) string {
+	r := strings.NewReplacer("\n", `\n`, "'", `\'`)
+	return "'" + r.Replace(s) + "'"
+}
+
+func onOff(b bool) string {
+	if b {
+		return "on"
+	}
+	return "off"
+}
+
+// PrintOptions returns the display settings of the session.
+func (s *Session) PrintOptions() PrintOptions {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.print
+}
+
+// SetPrintOptions replaces the display settings of the session.
+func (s *Session) SetPrintOptions(o PrintOptions) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.print = o
+}
@@ -0,0 +1,131 @@
+package pgxspecial_test
+
+import (
+	"testing"
+
+	"github.com/balaji01-4d/pgxspecial"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseExpandedMode(t *testing.T) {
+	for value, mode := range map[string]pgxspecial.ExpandedMode{
+		"on":   pgxspecial.ExpandedOn,
+		"t":    pgxspecial.ExpandedOn,
+		"off":  pgxspecial.ExpandedOff,
+		"0":    pgxspecial.ExpandedOff,
+		"auto": pgxspecial.ExpandedAuto,
+		"AUTO": pgxspecial.ExpandedAuto,
+	} {
+		got, err := pgxspecial.ParseExpandedMode(value)
+		assert.NoError(t, err, value)
+		assert.Equal(t, mode, got, value)
+	}
+
+	_, err := pgxspecial.ParseExpandedMode("au")
+	assert.Error(t, err)
+}
+
+func TestSessionPrintOptions(t *testing.T) {
+	s := pgxspecial.NewSession()
+	assert.Equal(t, pgxspecial.DefaultPrintOptions(), s.PrintOptions())
+	assert.Equal(t, "off", s.PrintOptions().Expanded.String())
+
+	s.SetPrintOptions(pgxspecial.PrintOptions{Expanded: pgxspecial.ExpandedAuto})
+	assert.Equal(t, "auto", s.PrintOptions().Expanded.String())
+}
+
+func TestPrintOptionsSet(t *testing.T) {
+	o := pgxspecial.DefaultPrintOptions()
+
+	require.NoError(t, o.Set("border", "2"))
+	assert.Equal(t, 2, o.Border)
+	require.NoError(t, o.Set("format", "u"))
+	assert.Equal(t, pgxspecial.FormatUnaligned, o.Format)
+	require.NoError(t, o.Set("linestyle", "UNI"))
+	assert.Equal(t, pgxspecial.LineStyleUnicode, o.LineStyle)
+	require.NoError(t, o.Set("unicode_border_linestyle", "double"))
+	assert.Equal(t, pgxspecial.UnicodeDouble, o.UnicodeBorderLineStyle)
+	require.NoError(t, o.Set("null", "(null)"))
+	assert.Equal(t, "(null)", o.Null)
+	require.NoError(t, o.Set("tuples_only", "on"))
+	assert.True(t, o.TuplesOnly)
+	require.NoError(t, o.Set("expanded", "auto"))
+	assert.Equal(t, pgxspecial.ExpandedAuto, o.Expanded)
+	require.NoError(t, o.Set("columns", "80"))
+	assert.Equal(t, 80, o.Columns)
+	require.NoError(t, o.Set("csv_fieldsep", "\t"))
+	assert.Equal(t, "\t", o.CSVFieldSep)
+
+	assert.EqualError(t, o.Set("format", "latex"), "allowed formats are aligned, csv, html, unaligned")
+	assert.EqualError(t, o.Set("border", "wide"), `invalid value "wide" for "border": integer expected`)
+	assert.EqualError(t, o.Set("footer", "maybe"), `unrecognized value "maybe" for "footer": Boolean expected`)
+	assert.EqualError(t, o.Set("pager", "off"), "unknown option: pager")
+	assert.EqualError(t, o.Set("csv_fieldsep", ";;"), "csv_fieldsep must be a single one-byte character")
+	assert.EqualError(t, o.Set("csv_fieldsep", `"`), "csv_fieldsep cannot be a double quote, a newline, or a carriage return")
+	assert.Equal(t, "\t", o.CSVFieldSep)
+	assert.Equal(t, pgxspecial.FormatUnaligned, o.Format)
+}
+
+func TestPrintOptionsToggle(t *testing.T) {
+	o := pgxspecial.DefaultPrintOptions()
+	o.Title = "Results"
+
+	require.NoError(t, o.Toggle("footer"))
+	assert.False(t, o.Footer)
+	require.NoError(t, o.Toggle("expanded"))
+	assert.Equal(t, pgxspecial.ExpandedOn, o.Expanded)
+	require.NoError(t, o.Toggle("title"))
+	assert.Empty(t, o.Title)
+
+	// options without a boolean value are left unchanged
+	require.NoError(t, o.Toggle("border"))
+	assert.Equal(t, 1, o.Border)
+	assert.Error(t, o.Toggle("pager"))
+}
+
+func TestPrintOptionsStatus(t *testing.T) {
+	o := pgxspecial.DefaultPrintOptions()
+	for name, want := range map[string]string{
+		"border":                   "Border style is 1.",
+		"columns":                  "Target width is unset.",
+		"csv_fieldsep":             `Field separator for CSV is ",".`,
+		"expanded":                 "Expanded display is off.",
+		"fieldsep":                 `Field separator is "|".`,
+		"footer":                   "Default footer is on.",
+		"format":                   "Output format is aligned.",
+		"linestyle":                "Line style is ascii.",
+		"null":                     `Null display is "".`,
+		"numericlocale":            "Locale-adjusted numeric output is off.",
+		"recordsep":                "Record separator is <newline>.",
+		"title":                    "Title is unset.",
+		"tuples_only":              "Tuples only is off.",
+		"unicode_border_linestyle": `Unicode border line style is "single".`,
+	} {
+		got, err := o.Status(name)
+		require.NoError(t, err, name)
+		assert.Equal(t, want, got, name)
+	}
+
+	_, err := o.Status("pager")
+	assert.Error(t, err)
+}
+
+func TestPrintOptionsValues(t *testing.T) {
+	o := pgxspecial.DefaultPrintOptions()
+	o.Title = "it's"
+
+	values := o.Values()
+	require.Len(t, values, 14)
+	assert.Equal(t, pgxspecial.Variable{Name: "border", Value: "1"}, values[0])
+
+	got := map[string]string{}
+	for _, v := range values {
+		got[v.Name] = v.Value
+	}
+	assert.Equal(t, "'|'", got["fieldsep"])
+	assert.Equal(t, `'\n'`, got["recordsep"])
+	assert.Equal(t, `'it\'s'`, got["title"])
+	assert.Equal(t, "on", got["footer"])
+	assert.Equal(t, "aligned", got["format"])
+}
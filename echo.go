@@ -0,0 +1,131 @@
+package pgxspecial
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/balaji01-4d/pgxspecial/database"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ECHO_HIDDEN modes, the values of the ECHO_HIDDEN variable as returned by
+// Session.EchoHidden. With EchoHiddenOn the queries a command issues are
+// recorded and returned in an EchoHiddenResult; with EchoHiddenNoExec they
+// are recorded but not executed.
+const (
+	EchoHiddenOff    = "off"
+	EchoHiddenOn     = "on"
+	EchoHiddenNoExec = "noexec"
+)
+
+// errNoExec stops a command at its first query in EchoHiddenNoExec mode.
+var errNoExec = errors.New("query not executed: ECHO_HIDDEN is noexec")
+
+// HiddenQuery is a query a command issued through its database.Queryer,
+// with the arguments it was given.
+type HiddenQuery struct {
+	SQL  string
+	Args []any
+}
+
+// String returns the query framed as psql echoes it, followed by its
+// arguments, if any, as comments.
+func (q HiddenQuery) String() string {
+	var sb strings.Builder
+	sb.WriteString("/******** QUERY *********/\n")
+	sb.WriteString(strings.TrimSpace(q.SQL))
+	sb.WriteString("\n")
+	for i, arg := range q.Args {
+		fmt.Fprintf(&sb, "-- $%d = %v\n", i+1, arg)
+	}
+	sb.WriteString("/************************/\n")
+	return sb.String()
+}
+
+type echoHiddenContextKey struct{}
+
+// WithEchoHidden returns a copy of ctx that sets the ECHO_HIDDEN mode of the
+// commands executed with it, overriding the ECHO_HIDDEN variable of the
+// session.
+func WithEchoHidden(ctx context.Context, mode string) context.Context {
+	return context.WithValue(ctx, echoHiddenContextKey{}, mode)
+}
+
+// echoHiddenMode returns the ECHO_HIDDEN mode in effect for ctx.
+func echoHiddenMode(ctx context.Context) string {
+	if mode, ok := ctx.Value(echoHiddenContextKey{}).(string); ok {
+		return mode
+	}
+	if session := SessionFromContext(ctx); session != nil {
+		return session.EchoHidden()
+	}
+	return EchoHiddenOff
+}
+
+// echoQueryer records the queries sent through it and, in noexec mode,
+// fails them instead of sending them.
+type echoQueryer struct {
+	db     database.Queryer
+	noExec bool
+
+	mu      sync.Mutex
+	queries []HiddenQuery
+}
+
+func (q *echoQueryer) record(sql string, args []any) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.queries = append(q.queries, HiddenQuery{SQL: sql, Args: args})
+	if q.noExec {
+		return errNoExec
+	}
+	return nil
+}
+
+func (q *echoQueryer) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	if err := q.record(sql, args); err != nil {
+		return nil, err
+	}
+	return q.db.Query(ctx, sql, args...)
+}
+
+func (q *echoQueryer) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	if err := q.record(sql, args); err != nil {
+		return errRow{err}
+	}
+	return q.db.QueryRow(ctx, sql, args...)
+}
+
+func (q *echoQueryer) Prepare(ctx context.Context, name, sql string) (*pgconn.StatementDescription, error) {
+	if err := q.record(sql, nil); err != nil {
+		return nil, err
+	}
+	return database.Prepare(ctx, q.db, name, sql)
+}
+
+// errRow is a pgx.Row failing with err.
+type errRow struct {
+	err error
+}
+
+func (r errRow) Scan(dest ...any) error {
+	return r.err
+}
+
+// UnwrapQueryer returns the Queryer the caller passed to the command being
+// executed, bypassing the recording of ECHO_HIDDEN. Handlers send the SQL
+// of the user through it, as \g does, so that only the queries the command
+// issues itself are echoed and suppressed by noexec.
+func UnwrapQueryer(db database.Queryer) database.Queryer {
+	for {
+		q, ok := db.(*echoQueryer)
+		if !ok {
+			return db
+		}
+		db = q.db
+	}
+}
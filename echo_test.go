@@ -0,0 +1,121 @@
+package pgxspecial_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/balaji01-4d/pgxspecial"
+	"github.com/balaji01-4d/pgxspecial/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// catalogRegistry returns a registry with \cat, which looks up its argument
+// with two queries, \user, which sends its argument as the SQL of the user,
+// and \none, which issues no query.
+func catalogRegistry(t *testing.T) *pgxspecial.Registry {
+	reg := pgxspecial.NewRegistry()
+	require.NoError(t, reg.Register(pgxspecial.SpecialCommandRegistry{
+		Cmd: "\\cat",
+		Handler: func(ctx context.Context, db database.Queryer, args string, opts pgxspecial.CommandOptions) (pgxspecial.SpecialCommandResult, error) {
+			rows, err := db.Query(ctx, "SELECT oid FROM pg_class WHERE relname = $1", args)
+			if err != nil {
+				return nil, err
+			}
+			rows.Close()
+			rows, err = db.Query(ctx, "SELECT attname FROM pg_attribute")
+			return pgxspecial.RowResult{Rows: rows}, err
+		},
+		CaseSensitive: true,
+	}))
+	require.NoError(t, reg.Register(pgxspecial.SpecialCommandRegistry{
+		Cmd: "\\user",
+		Handler: func(ctx context.Context, db database.Queryer, args string, opts pgxspecial.CommandOptions) (pgxspecial.SpecialCommandResult, error) {
+			rows, err := pgxspecial.UnwrapQueryer(db).Query(ctx, args)
+			return pgxspecial.RowResult{Rows: rows}, err
+		},
+		CaseSensitive: true,
+	}))
+	require.NoError(t, reg.Register(pgxspecial.SpecialCommandRegistry{
+		Cmd: "\\none",
+		Handler: func(ctx context.Context, db database.Queryer, args string, opts pgxspecial.CommandOptions) (pgxspecial.SpecialCommandResult, error) {
+			return pgxspecial.StatusResult{Message: "done"}, nil
+		},
+		CaseSensitive: true,
+	}))
+	return reg
+}
+
+func TestEchoHidden(t *testing.T) {
+	reg := catalogRegistry(t)
+	db := &fakeQueryer{}
+	session := pgxspecial.NewSession()
+	ctx := pgxspecial.WithSession(context.Background(), session)
+
+	res, _, err := reg.Execute(ctx, db, "\\cat users")
+	require.NoError(t, err)
+	assert.Equal(t, pgxspecial.ResultKindRows, res.ResultKind(), "ECHO_HIDDEN is off by default")
+
+	require.NoError(t, session.Set(pgxspecial.VarEchoHidden, "on"))
+	db.queries = nil
+	res, _, err = reg.Execute(ctx, db, "\\cat users")
+	require.NoError(t, err)
+	echo := res.(pgxspecial.EchoHiddenResult)
+	assert.Equal(t, []pgxspecial.HiddenQuery{
+		{SQL: "SELECT oid FROM pg_class WHERE relname = $1", Args: []any{"users"}},
+		{SQL: "SELECT attname FROM pg_attribute"},
+	}, echo.Queries)
+	assert.Equal(t, pgxspecial.ResultKindRows, echo.Result.ResultKind())
+	assert.Len(t, db.queries, 2)
+	assert.Equal(t, "/******** QUERY *********/\n"+
+		"SELECT oid FROM pg_class WHERE relname = $1\n"+
+		"-- $1 = users\n"+
+		"/************************/\n", echo.Queries[0].String())
+
+	// the SQL of the user and commands without queries are not wrapped
+	res, _, err = reg.Execute(ctx, db, "\\user SELECT 1")
+	require.NoError(t, err)
+	assert.Equal(t, pgxspecial.ResultKindRows, res.ResultKind())
+	res, _, err = reg.Execute(ctx, db, "\\none")
+	require.NoError(t, err)
+	assert.Equal(t, pgxspecial.StatusResult{Message: "done"}, res)
+
+	// the context overrides the session
+	res, _, err = reg.Execute(pgxspecial.WithEchoHidden(ctx, pgxspecial.EchoHiddenOff), db, "\\cat users")
+	require.NoError(t, err)
+	assert.Equal(t, pgxspecial.ResultKindRows, res.ResultKind())
+}
+
+func TestEchoHiddenNoExec(t *testing.T) {
+	reg := catalogRegistry(t)
+	db := &fakeQueryer{}
+	ctx := pgxspecial.WithEchoHidden(context.Background(), pgxspecial.EchoHiddenNoExec)
+
+	res, _, err := reg.Execute(ctx, db, "\\cat users")
+	require.NoError(t, err)
+	assert.Equal(t, pgxspecial.EchoHiddenResult{Queries: []pgxspecial.HiddenQuery{
+		{SQL: "SELECT oid FROM pg_class WHERE relname = $1", Args: []any{"users"}},
+	}}, res)
+	assert.Empty(t, db.queries, "noexec must not send hidden queries")
+
+	_, _, err = reg.Execute(ctx, db, "\\user SELECT 1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"SELECT 1"}, db.queries)
+}
+
+func TestEchoHiddenInScript(t *testing.T) {
+	reg := catalogRegistry(t)
+	db := &fakeQueryer{}
+	ctx := pgxspecial.WithEchoHidden(context.Background(), pgxspecial.EchoHiddenOn)
+
+	results, err := reg.ExecuteScript(ctx, db, strings.NewReader("SELECT 1;\n\\cat users\n"))
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, "SELECT 1", results[0].Result.(pgxspecial.QueryResult).CommandTag)
+
+	// the rows are read before the next statement, inside the echo result
+	echo := results[1].Result.(pgxspecial.EchoHiddenResult)
+	assert.Len(t, echo.Queries, 2)
+	assert.Equal(t, "SELECT 1", echo.Result.(pgxspecial.QueryResult).CommandTag)
+}
@@ -0,0 +1,69 @@
+package pgxspecial
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// EditorRunner opens file in an editor, positioned at line if it is
+// positive, and returns once the editor has exited. Commands such as \e
+// launch the editor through the runner carried by the context, so clients
+// can substitute their own, see WithEditorRunner.
+type EditorRunner func(ctx context.Context, file string, line int) error
+
+type editorContextKey struct{}
+
+// WithEditorRunner returns a copy of ctx carrying runner.
+func WithEditorRunner(ctx context.Context, runner EditorRunner) context.Context {
+	return context.WithValue(ctx, editorContextKey{}, runner)
+}
+
+// EditorRunnerFromContext returns the EditorRunner carried by ctx, or
+// RunEditor if there is none.
+func EditorRunnerFromContext(ctx context.Context) EditorRunner {
+	if runner, ok := ctx.Value(editorContextKey{}).(EditorRunner); ok && runner != nil {
+		return runner
+	}
+	return RunEditor
+}
+
+// RunEditor is the default EditorRunner. Like psql it runs the editor named
+// by PSQL_EDITOR, EDITOR or VISUAL, or vi, through the shell, attached to
+// the standard input and output of the process. A line number is passed
+// after the PSQL_EDITOR_LINENUMBER_ARG prefix, "+" by default.
+func RunEditor(ctx context.Context, file string, line int) error {
+	editor := "vi"
+	for _, name := range []string{"PSQL_EDITOR", "EDITOR", "VISUAL"} {
+		if value := os.Getenv(name); value != "" {
+			editor = value
+			break
+		}
+	}
+
+	command := editor
+	if line > 0 {
+		lineArg, ok := os.LookupEnv("PSQL_EDITOR_LINENUMBER_ARG")
+		if !ok {
+			lineArg = "+"
+		}
+		command += fmt.Sprintf(" %s%d", lineArg, line)
+	}
+	command += " " + shellQuote(file)
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("editor %q: %w", editor, err)
+	}
+	return nil
+}
+
+// shellQuote quotes s as a single word for sh.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
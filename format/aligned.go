@@ -0,0 +1,237 @@
+package format
+
+import "strings"
+
+// writeAligned prints t as psql's aligned format does: the title centered
+// over the columns, the headers centered and underlined, and the cells
+// padded to the widest value of their column. Cells spanning several lines
+// continue on the following lines, with a mark after every line but the
+// last. The border and linestyle options select how the table is drawn.
+func (o Options) writeAligned(sb *strings.Builder, t table) {
+	l := newLayout(t)
+	if len(l.widths) == 0 {
+		return
+	}
+	border := o.border()
+	s := o.lineStyle()
+
+	if !o.TuplesOnly {
+		if t.title != "" {
+			total := l.width(border)
+			if tw := displayWidth(t.title); tw < total {
+				sb.WriteString(strings.Repeat(" ", (total-tw)/2))
+			}
+			sb.WriteString(t.title + "\n")
+		}
+		if border == 2 {
+			writeRule(sb, l.widths, border, s.top)
+		}
+		writeHeaders(sb, l.headers, l.widths, border, s)
+		writeRule(sb, l.widths, border, s.middle)
+	} else if border == 2 {
+		writeRule(sb, l.widths, border, s.top)
+	}
+
+	for _, row := range l.cells {
+		writeRow(sb, row, l.widths, t.aligns, border, s)
+	}
+	if border == 2 {
+		writeRule(sb, l.widths, border, s.bottom)
+	}
+
+	if !o.TuplesOnly {
+		for _, f := range o.footers(t) {
+			sb.WriteString(f + "\n")
+		}
+	}
+	sb.WriteString("\n")
+}
+
+// layout holds the lines of the headers and cells of a table, and the
+// width of each column.
+type layout struct {
+	headers [][]string
+	cells   [][][]string
+	widths  []int
+}
+
+func newLayout(t table) layout {
+	ncols := len(t.headers)
+	l := layout{
+		headers: make([][]string, ncols),
+		cells:   make([][][]string, len(t.cells)),
+		widths:  make([]int, ncols),
+	}
+	for i, h := range t.headers {
+		l.headers[i] = strings.Split(h, "\n")
+		for _, line := range l.headers[i] {
+			l.widths[i] = max(l.widths[i], displayWidth(line))
+		}
+	}
+	for r, row := range t.cells {
+		l.cells[r] = make([][]string, ncols)
+		for i := range ncols {
+			var cell string
+			if i < len(row) {
+				cell = row[i]
+			}
+			l.cells[r][i] = strings.Split(cell, "\n")
+			for _, line := range l.cells[r][i] {
+				l.widths[i] = max(l.widths[i], displayWidth(line))
+			}
+		}
+	}
+	return l
+}
+
+// width returns the width of the lines of the table drawn with border.
+func (l layout) width(border int) int {
+	var total int
+	switch border {
+	case 0:
+		total = len(l.widths)
+	case 1:
+		total = 3*len(l.widths) - 1
+	default:
+		total = 3*len(l.widths) + 1
+	}
+	for _, w := range l.widths {
+		total += w
+	}
+	return total
+}
+
+// writeRule prints a horizontal rule across the columns, the line under
+// the headers or, with border 2, above or below the table.
+func writeRule(sb *strings.Builder, widths []int, border int, r rule) {
+	switch border {
+	case 1:
+		sb.WriteString(r.hrule)
+	case 2:
+		sb.WriteString(r.left + r.hrule)
+	}
+	for i, w := range widths {
+		sb.WriteString(strings.Repeat(r.hrule, w))
+		if i == len(widths)-1 {
+			break
+		}
+		if border == 0 {
+			sb.WriteString(" ")
+		} else {
+			sb.WriteString(r.hrule + r.mid + r.hrule)
+		}
+	}
+	switch border {
+	case 1:
+		sb.WriteString(r.hrule)
+	case 2:
+		sb.WriteString(r.hrule + r.right)
+	}
+	sb.WriteString("\n")
+}
+
+// writeHeaders prints the headers centered over their columns.
+func writeHeaders(sb *strings.Builder, headers [][]string, widths []int, border int, s lineStyle) {
+	lines := 0
+	for _, h := range headers {
+		lines = max(lines, len(h))
+	}
+	for n := range lines {
+		if border == 2 {
+			sb.WriteString(s.data.left)
+		}
+		for i, h := range headers {
+			if border != 0 || (!s.wrapRightBorder && i > 0) {
+				if n > 0 {
+					sb.WriteString(s.headerNLLeft)
+				} else {
+					sb.WriteString(" ")
+				}
+			}
+			if n < len(h) {
+				pad := widths[i] - displayWidth(h[n])
+				sb.WriteString(strings.Repeat(" ", pad/2))
+				sb.WriteString(h[n])
+				sb.WriteString(strings.Repeat(" ", (pad+1)/2))
+			} else {
+				sb.WriteString(strings.Repeat(" ", widths[i]))
+			}
+			if border != 0 || s.wrapRightBorder {
+				if n < len(h)-1 {
+					sb.WriteString(s.headerNLRight)
+				} else {
+					sb.WriteString(" ")
+				}
+			}
+			if border != 0 && i < len(headers)-1 {
+				sb.WriteString(s.data.mid)
+			}
+		}
+		if border == 2 {
+			sb.WriteString(s.data.right)
+		}
+		sb.WriteString("\n")
+	}
+}
+
+// writeRow prints the lines of a row. Left-aligned cells are padded on the
+// right, except in the last column unless a border or mark follows.
+func writeRow(sb *strings.Builder, row [][]string, widths []int, aligns []byte, border int, s lineStyle) {
+	lines := 0
+	for _, cell := range row {
+		lines = max(lines, len(cell))
+	}
+	last := len(row) - 1
+	for n := range lines {
+		if border == 2 {
+			sb.WriteString(s.data.left)
+		}
+		for i, cell := range row {
+			finalSpaces := border == 2 || i < last
+			if border != 0 {
+				if n > 0 && n < len(cell) {
+					sb.WriteString(s.nlLeft)
+				} else {
+					sb.WriteString(" ")
+				}
+			}
+
+			wraps := n < len(cell)-1
+			if n < len(cell) {
+				pad := widths[i] - displayWidth(cell[n])
+				if aligns[i] == alignRight {
+					sb.WriteString(strings.Repeat(" ", pad))
+					sb.WriteString(cell[n])
+				} else {
+					sb.WriteString(cell[n])
+					if finalSpaces || wraps {
+						sb.WriteString(strings.Repeat(" ", pad))
+					}
+				}
+			} else if finalSpaces {
+				sb.WriteString(strings.Repeat(" ", widths[i]))
+			}
+			switch {
+			case wraps:
+				sb.WriteString(s.nlRight)
+			case finalSpaces:
+				sb.WriteString(" ")
+			}
+
+			if border != 0 && i < last {
+				switch next := row[i+1]; {
+				case n > 0 && n < len(next):
+					sb.WriteString(s.midvruleNL)
+				case n >= len(next):
+					sb.WriteString(s.midvruleBlank)
+				default:
+					sb.WriteString(s.data.mid)
+				}
+			}
+		}
+		if border == 2 {
+			sb.WriteString(s.data.right)
+		}
+		sb.WriteString("\n")
+	}
+}
@@ -0,0 +1,114 @@
+package format
+
+import "strings"
+
+// writeCSV prints t as psql's csv format does: the headers, unless
+// tuples_only or OmitCSVHeader is on, and a record per row, with the
+// fields separated by the csv_fieldsep option and quoted as RFC 4180
+// requires. Titles and the number of rows are left out. Empty values that
+// are not NULL are quoted, so that they differ from NULL as in COPY.
+//
+// The footers of a table description follow its rows after an empty
+// line, unless tuples_only is on, as a second table with a record per
+// entry of each section:
+//
+//	Section,Entry
+//	Indexes,"""users_pkey"" PRIMARY KEY, btree (id)"
+//	Referenced by,"TABLE ""orders"" CONSTRAINT ..."
+func (o Options) writeCSV(sb *strings.Builder, t table) {
+	w := o.csvWriter(sb)
+	if !o.TuplesOnly && !o.OmitCSVHeader {
+		w.record(t.headers, nil)
+	}
+	for r, row := range t.cells {
+		w.record(row, t.nullsOf(r))
+	}
+
+	if len(t.sections) == 0 || o.TuplesOnly {
+		return
+	}
+	w.end()
+	if !o.OmitCSVHeader {
+		w.record([]string{"Section", "Entry"}, nil)
+	}
+	for _, s := range t.sections {
+		for _, e := range s.entries {
+			w.record([]string{s.label, e}, nil)
+		}
+	}
+}
+
+// writeCSVExpanded prints t as psql's csv format does in expanded mode: a
+// record per column of each row, holding its name and value.
+func (o Options) writeCSVExpanded(sb *strings.Builder, t table) {
+	w := o.csvWriter(sb)
+	for r, row := range t.cells {
+		nulls := t.nullsOf(r)
+		for i, h := range t.headers {
+			var cell string
+			if i < len(row) {
+				cell = row[i]
+			}
+			if i < len(nulls) {
+				w.record([]string{h, cell}, []bool{false, nulls[i]})
+			} else {
+				w.record([]string{h, cell}, nil)
+			}
+		}
+	}
+}
+
+func (t table) nullsOf(row int) []bool {
+	if row < len(t.nulls) {
+		return t.nulls[row]
+	}
+	return nil
+}
+
+// csvWriter writes the records of the csv format.
+type csvWriter struct {
+	sb  *strings.Builder
+	sep byte
+	eol string
+}
+
+func (o Options) csvWriter(sb *strings.Builder) csvWriter {
+	w := csvWriter{sb: sb, sep: ',', eol: "\n"}
+	if o.CSVFieldSep != "" {
+		w.sep = o.CSVFieldSep[0]
+	}
+	if o.UseCRLF {
+		w.eol = "\r\n"
+	}
+	return w
+}
+
+// record writes a record of fields, nulls telling which are NULL if known.
+func (w csvWriter) record(fields []string, nulls []bool) {
+	for i, f := range fields {
+		if i > 0 {
+			w.sb.WriteByte(w.sep)
+		}
+		known := i < len(nulls)
+		if w.quoted(f) || (f == "" && known && !nulls[i]) {
+			w.sb.WriteString(`"` + strings.ReplaceAll(f, `"`, `""`) + `"`)
+		} else {
+			w.sb.WriteString(f)
+		}
+	}
+	w.end()
+}
+
+func (w csvWriter) end() {
+	w.sb.WriteString(w.eol)
+}
+
+// quoted reports whether f must be quoted: if it holds the separator, a
+// quote or a line break, or, as psql takes care of so that COPY does not
+// read the end of data, if it is \. or the separator is \ or a period.
+func (w csvWriter) quoted(f string) bool {
+	return strings.IndexByte(f, w.sep) >= 0 ||
+		strings.ContainsAny(f, "\"\r\n") ||
+		f == `\.` ||
+		w.sep == '\\' || w.sep == '.'
+}
@@ -0,0 +1,160 @@
+package format_test
+
+import (
+	"testing"
+
+	"github.com/balaji01-4d/pgxspecial"
+	"github.com/balaji01-4d/pgxspecial/format"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+)
+
+func csvOptions() format.Options {
+	o := options()
+	o.Format = pgxspecial.FormatCSV
+	return o
+}
+
+func TestPrintCSV(t *testing.T) {
+	res := pgxspecial.QueryResult{
+		Columns: []string{"a", "b"},
+		Rows: [][]any{
+			{int32(1), "x,y"},
+			{nil, ""},
+			{int32(2), "line1\nline2"},
+			{int32(3), `say "hi"`},
+		},
+	}
+	o := csvOptions()
+	o.Title = "ignored"
+	assert.Equal(t, lines(
+		"a,b",
+		`1,"x,y"`,
+		`,""`,
+		`2,"line1`,
+		`line2"`,
+		`3,"say ""hi"""`,
+	), printWith(t, o, res))
+
+	o.CSVFieldSep = "\t"
+	o.TuplesOnly = true
+	o.Null = "NULL"
+	o.UseCRLF = true
+	assert.Equal(t, "1\tx,y\r\nNULL\t\"\"\r\n2\t\"line1\nline2\"\r\n3\t\"say \"\"hi\"\"\"\r\n", printWith(t, o, res))
+}
+
+func TestPrintCSVRows(t *testing.T) {
+	databases := func() pgxspecial.RowResult {
+		return pgxspecial.RowResult{Title: "List of databases", Rows: &fakeRows{
+			columns: []string{"Name", "Access privileges", "Description"},
+			oids:    []uint32{pgtype.TextOID, pgtype.TextOID, pgtype.TextOID},
+			values: [][]*string{
+				{text("template0"), text("=c/postgres\npostgres=CTc/postgres"), nil},
+				{text("app"), text(""), text(`\.`)},
+			},
+		}}
+	}
+	assert.Equal(t, lines(
+		"Name,Access privileges,Description",
+		`template0,"=c/postgres`,
+		`postgres=CTc/postgres",`,
+		`app,"","\."`,
+	), printWith(t, csvOptions(), databases()))
+
+	o := csvOptions()
+	o.OmitCSVHeader = true
+	assert.Equal(t, lines(
+		`template0,"=c/postgres`,
+		`postgres=CTc/postgres",`,
+		`app,"","\."`,
+	), printWith(t, o, databases()))
+}
+
+func TestPrintCSVExpanded(t *testing.T) {
+	o := csvOptions()
+	o.Expanded = pgxspecial.ExpandedOn
+	res := pgxspecial.QueryResult{
+		Columns: []string{"a", "b"},
+		Rows:    [][]any{{int32(1), nil}, {int32(2), ""}},
+	}
+	assert.Equal(t, lines("a,1", "b,", "a,2", `b,""`), printWith(t, o, res))
+}
+
+func TestPrintCSVDescribe(t *testing.T) {
+	view := "SELECT id\n   FROM users;\n"
+	res := pgxspecial.DescribeTableListResult{Results: []pgxspecial.DescribeTableResult{{
+		Title:   `Table "public.users"`,
+		RelKind: "r",
+		Columns: []string{"Column", "Type", "Default"},
+		Data:    [][]string{{"id", "integer", ""}},
+		TableMetaData: pgxspecial.TableFooterMeta{
+			Indexes:        []string{`"users_pkey" PRIMARY KEY, btree (id)`},
+			ViewDefinition: &view,
+			Inherits:       []string{"a", "b"},
+		},
+	}}}
+	assert.Equal(t, lines(
+		"Column,Type,Default",
+		"id,integer,",
+		"",
+		"Section,Entry",
+		`Indexes,"""users_pkey"" PRIMARY KEY, btree (id)"`,
+		`View definition,"SELECT id`,
+		`   FROM users;"`,
+		"Inherits,a",
+		"Inherits,b",
+	), printWith(t, csvOptions(), res))
+
+	// without headers the footers are still written
+	o := csvOptions()
+	o.OmitCSVHeader = true
+	assert.Equal(t, lines(
+		"id,integer,",
+		"",
+		`Indexes,"""users_pkey"" PRIMARY KEY, btree (id)"`,
+		`View definition,"SELECT id`,
+		`   FROM users;"`,
+		"Inherits,a",
+		"Inherits,b",
+	), printWith(t, o, res))
+
+	o = csvOptions()
+	o.TuplesOnly = true
+	assert.Equal(t, lines("id,integer,"), printWith(t, o, res))
+}
+
+func TestPrintCSVText(t *testing.T) {
+	o := csvOptions()
+	tests := []struct {
+		name string
+		res  pgxspecial.SpecialCommandResult
+		want string
+	}{
+		{
+			name: "help",
+			res: pgxspecial.HelpResult{Groups: []pgxspecial.CommandGroup{{
+				Name:     "Informational",
+				Commands: []pgxspecial.SpecialCommand{{Cmd: `\l`, Syntax: `\l[+] [PATTERN]`, Description: "List databases."}},
+			}}},
+			want: lines("Group,Command,Syntax,Description", `Informational,\l,\l[+] [PATTERN],List databases.`),
+		},
+		{
+			name: "variables",
+			res:  pgxspecial.VariablesResult{Variables: []pgxspecial.Variable{{Name: "a", Value: "1,2"}}},
+			want: lines("Name,Value", `a,"1,2"`),
+		},
+		{
+			name: "numbered text",
+			res:  pgxspecial.TextResult{Lines: []pgxspecial.TextLine{{Number: 1, Text: "BEGIN"}}},
+			want: lines("Number,Text", "1,BEGIN"),
+		},
+		{
+			name: "status",
+			res:  pgxspecial.StatusResult{Message: "Query buffer reset (cleared)."},
+			want: lines("Query buffer reset (cleared)."),
+		},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, printWith(t, o, tt.res), tt.name)
+	}
+}
@@ -0,0 +1,198 @@
+package format
+
+import (
+	"fmt"
+	"strings"
+)
+
+// writeExpanded prints t as psql's expanded display does: each row as a
+// record headed by its number, holding a line per column with the column
+// name, padded to the longest, and the value. The number of rows is not
+// printed, except for "(0 rows)" in place of empty results without
+// footers. The border and linestyle options select how records are drawn.
+func (o Options) writeExpanded(sb *strings.Builder, t table) {
+	l := newLayout(t)
+	if len(l.cells) == 0 {
+		if !o.TuplesOnly {
+			for _, f := range o.footers(t) {
+				sb.WriteString(f + "\n")
+			}
+		}
+		sb.WriteString("\n")
+		return
+	}
+	border := o.border()
+	s := o.lineStyle()
+
+	hwidth, dwidth := 0, 0
+	multiline := false
+	for _, h := range l.headers {
+		multiline = multiline || len(h) > 1
+		for _, line := range h {
+			hwidth = max(hwidth, displayWidth(line))
+		}
+	}
+	for _, row := range l.cells {
+		for _, cell := range row {
+			for _, line := range cell {
+				dwidth = max(dwidth, displayWidth(line))
+			}
+		}
+	}
+	v := vertical{border: border, style: s, hwidth: hwidth, dwidth: dwidth, multiline: multiline}
+
+	if t.title != "" && !o.TuplesOnly {
+		sb.WriteString(t.title + "\n")
+	}
+	for r, row := range l.cells {
+		line := s.middle
+		if r == 0 {
+			line = s.top
+		}
+		switch {
+		case !o.TuplesOnly:
+			v.writeRecordLine(sb, r+1, line)
+		case r > 0 || border == 2:
+			v.writeRecordLine(sb, 0, line)
+		}
+		for i, cell := range row {
+			v.writeField(sb, l.headers[i], cell)
+		}
+	}
+	if border == 2 {
+		v.writeRecordLine(sb, 0, s.bottom)
+	}
+	if !o.TuplesOnly {
+		for _, f := range t.footers {
+			sb.WriteString(f + "\n")
+		}
+	}
+	sb.WriteString("\n")
+}
+
+// vertical holds how the records of an expanded table are drawn: the width
+// of the column names and of the values, and whether any name spans
+// several lines.
+type vertical struct {
+	border         int
+	style          lineStyle
+	hwidth, dwidth int
+	multiline      bool
+}
+
+// writeRecordLine prints the rule heading a record, as wide as the lines
+// of the records with the record number near its start, or without one if
+// record is 0. It also closes the records with border 2.
+func (v vertical) writeRecordLine(sb *strings.Builder, record int, r rule) {
+	fill := r.hrule
+	switch v.border {
+	case 0:
+		fill = " "
+	case 1:
+		sb.WriteString(r.hrule)
+	case 2:
+		sb.WriteString(r.left + r.hrule)
+	}
+
+	reclen := 0
+	if record > 0 {
+		label := fmt.Sprintf("[ RECORD %d ]", record)
+		if v.border == 0 {
+			label = fmt.Sprintf("* Record %d", record)
+		}
+		sb.WriteString(label)
+		reclen = len(label)
+	}
+	if v.border != 2 {
+		reclen++
+	}
+
+	// the label runs over the names, then over the divider between the
+	// names and the values, then over the values
+	sb.WriteString(strings.Repeat(fill, max(v.hwidth-reclen, 0)))
+	reclen -= v.hwidth
+	divider := []string{" "}
+	if v.border > 0 {
+		divider = []string{r.hrule, r.mid, r.hrule}
+	}
+	for _, d := range divider {
+		if reclen <= 0 {
+			sb.WriteString(d)
+		}
+		reclen--
+	}
+	sb.WriteString(strings.Repeat(fill, max(v.dwidth-max(reclen, 0), 0)))
+
+	if v.border == 2 {
+		sb.WriteString(r.hrule + r.right)
+	}
+	sb.WriteString("\n")
+}
+
+// writeField prints the lines of a column of a record. Names and values
+// spanning several lines continue on the following lines, with a mark
+// after every line but the last.
+func (v vertical) writeField(sb *strings.Builder, header, cell []string) {
+	s := v.style
+	for n := range max(len(header), len(cell)) {
+		if v.border == 2 {
+			sb.WriteString(s.data.left)
+		}
+
+		if n < len(header) {
+			if v.border == 2 || (v.multiline && s.oldASCII) {
+				if n > 0 {
+					sb.WriteString(s.headerNLLeft)
+				} else {
+					sb.WriteString(" ")
+				}
+			}
+			sb.WriteString(header[n])
+			sb.WriteString(strings.Repeat(" ", v.hwidth-displayWidth(header[n])))
+			if v.border > 0 || (v.multiline && !s.oldASCII) {
+				if n < len(header)-1 {
+					sb.WriteString(s.headerNLRight)
+				} else {
+					sb.WriteString(" ")
+				}
+			}
+		} else {
+			width := v.hwidth + v.border
+			if v.border < 2 && v.multiline && s.oldASCII {
+				width++
+			}
+			if v.border == 0 && v.multiline && !s.oldASCII {
+				width++
+			}
+			sb.WriteString(strings.Repeat(" ", width))
+		}
+
+		if v.border > 0 {
+			if n == 0 {
+				sb.WriteString(s.data.mid)
+			} else {
+				sb.WriteString(s.midvruleNL)
+			}
+		}
+
+		if n >= len(cell) {
+			if v.border == 2 {
+				sb.WriteString(strings.Repeat(" ", v.dwidth+2) + s.data.right)
+			}
+			sb.WriteString("\n")
+			continue
+		}
+		sb.WriteString(" " + cell[n])
+		pad := strings.Repeat(" ", v.dwidth-displayWidth(cell[n]))
+		switch {
+		case n < len(cell)-1:
+			sb.WriteString(pad + s.nlRight)
+		case v.border == 2:
+			sb.WriteString(pad + " ")
+		}
+		if v.border == 2 {
+			sb.WriteString(s.data.right)
+		}
+		sb.WriteString("\n")
+	}
+}
@@ -0,0 +1,121 @@
+package format_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/balaji01-4d/pgxspecial"
+	"github.com/balaji01-4d/pgxspecial/format"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func printWith(t *testing.T, o format.Options, res pgxspecial.SpecialCommandResult) string {
+	t.Helper()
+	var sb strings.Builder
+	require.NoError(t, o.Print(&sb, res))
+	return sb.String()
+}
+
+// options returns the options psql prints with by default.
+func options() format.Options {
+	return format.Options{PrintOptions: pgxspecial.DefaultPrintOptions()}
+}
+
+func roles() *fakeRows {
+	return &fakeRows{
+		columns: []string{"Role name", "Attributes", "oid"},
+		oids:    []uint32{pgtype.TextOID, pgtype.TextOID, pgtype.OIDOID},
+		values: [][]*string{
+			{text("postgres"), text("Superuser\nCreate role"), text("10")},
+			{text("app"), text(""), text("16384")},
+		},
+	}
+}
+
+func TestPrintExpanded(t *testing.T) {
+	expanded := options()
+	expanded.Expanded = pgxspecial.ExpandedOn
+	want := lines(
+		"List of roles",
+		"-[ RECORD 1 ]-----------",
+		"Role name  | postgres",
+		"Attributes | Superuser  +",
+		"           | Create role",
+		"oid        | 10",
+		"-[ RECORD 2 ]-----------",
+		"Role name  | app",
+		"Attributes | ",
+		"oid        | 16384",
+		"",
+	)
+	assert.Equal(t, want, printWith(t, expanded, pgxspecial.RowResult{Rows: roles(), Title: "List of roles"}))
+
+	// \gx expands a single result
+	assert.Equal(t, want, print(t, pgxspecial.RowResult{Rows: roles(), Title: "List of roles", Expanded: true}))
+
+	out := printWith(t, expanded, pgxspecial.QueryResult{
+		Columns: []string{"a", "b"},
+		Rows:    [][]any{{int32(1), "x"}},
+	})
+	assert.Equal(t, lines(
+		"-[ RECORD 1 ]",
+		"a | 1",
+		"b | x",
+		"",
+	), out)
+
+	out = printWith(t, expanded, pgxspecial.QueryResult{Columns: []string{"a"}})
+	assert.Equal(t, lines("(0 rows)", ""), out)
+}
+
+func TestPrintExpandedAuto(t *testing.T) {
+	auto := options()
+	auto.Expanded = pgxspecial.ExpandedAuto
+
+	// the table is 33 columns wide
+	auto.TermWidth = 33
+	assert.Contains(t, printWith(t, auto, pgxspecial.RowResult{Rows: roles()}), "(2 rows)")
+
+	auto.TermWidth = 32
+	assert.Contains(t, printWith(t, auto, pgxspecial.RowResult{Rows: roles()}), "-[ RECORD 1 ]")
+
+	// without a terminal tables are not expanded
+	auto.TermWidth = 0
+	assert.Contains(t, printWith(t, auto, pgxspecial.RowResult{Rows: roles()}), "(2 rows)")
+}
+
+func TestPrintExpandedDescribe(t *testing.T) {
+	expanded := options()
+	expanded.Expanded = pgxspecial.ExpandedOn
+	res := pgxspecial.DescribeTableListResult{Results: []pgxspecial.DescribeTableResult{{
+		Title:   `Table "public.users"`,
+		RelKind: "r",
+		Columns: []string{"Column", "Type"},
+		Data:    [][]string{{"id", "integer"}},
+		TableMetaData: pgxspecial.TableFooterMeta{
+			Indexes: []string{`"users_pkey" PRIMARY KEY, btree (id)`},
+		},
+	}}}
+	assert.Equal(t, lines(
+		`Table "public.users"`,
+		"-[ RECORD 1 ]---",
+		"Column | id",
+		"Type   | integer",
+		"Indexes:",
+		`    "users_pkey" PRIMARY KEY, btree (id)`,
+		"",
+	), printWith(t, expanded, res))
+
+	ext := pgxspecial.ExtensionVerboseListResult{Results: []pgxspecial.ExtensionVerboseResult{{
+		Name:        "plpgsql",
+		Description: []string{"language plpgsql"},
+	}}}
+	assert.Equal(t, lines(
+		`Objects in extension "plpgsql"`,
+		"-[ RECORD 1 ]------+-----------------",
+		"Object description | language plpgsql",
+		"",
+	), printWith(t, expanded, ext))
+}
@@ -0,0 +1,226 @@
+// Package format renders the results of special commands as psql prints
+// them.
+package format
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/balaji01-4d/pgxspecial"
+)
+
+// Options controls how results are printed: the display settings of the
+// session, see pgxspecial.Session.PrintOptions, and the terminal printed to.
+// Start from the settings of the session, or from
+// pgxspecial.DefaultPrintOptions to print results as psql does by default.
+type Options struct {
+	pgxspecial.PrintOptions
+
+	// TermWidth is the width of the terminal, which tables must fit in
+	// ExpandedAuto mode unless the columns option is set. 0 means the
+	// output is not a terminal, where tables are never expanded
+	// automatically.
+	TermWidth int
+
+	// UseCRLF ends the records of the csv format with \r\n, as RFC 4180
+	// specifies, rather than with \n as psql does.
+	UseCRLF bool
+
+	// OmitCSVHeader leaves out the header records of the csv format: the
+	// column names, and the Section,Entry line of the footers of table
+	// descriptions, whose entries are still written. tuples_only leaves
+	// out the headers and the footers alike.
+	OmitCSVHeader bool
+}
+
+// Print writes res to w as psql prints it in its default aligned format:
+// row results as tables under their title, followed by the number of rows,
+// table descriptions followed by their footers, and the other results as
+// text. The rows of a RowResult are read and closed; its Output is left to
+// the caller.
+func Print(w io.Writer, res pgxspecial.SpecialCommandResult) error {
+	return Options{PrintOptions: pgxspecial.DefaultPrintOptions()}.Print(w, res)
+}
+
+// Print writes res to w as psql prints it with the options o. Tables are
+// printed in the output format of o, and displayed as vertical records in
+// expanded mode, or if the result asks for it, as after \gx. In the csv
+// format, help, variables and text results are printed as tables too, so
+// that the output can be read back; messages such as command tags are
+// printed as text.
+func (o Options) Print(w io.Writer, res pgxspecial.SpecialCommandResult) error {
+	var sb strings.Builder
+	if err := o.render(&sb, res); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func (o Options) render(sb *strings.Builder, res pgxspecial.SpecialCommandResult) error {
+	csv := o.Format == pgxspecial.FormatCSV
+	switch r := res.(type) {
+	case nil:
+	case pgxspecial.RowResult:
+		t, err := rowsTable(r.Rows, r.Title, o.Null)
+		if err != nil {
+			return err
+		}
+		o.writeTable(sb, t, r.Expanded)
+	case pgxspecial.QueryResult:
+		if len(r.Columns) == 0 {
+			sb.WriteString(r.CommandTag + "\n")
+			break
+		}
+		o.writeTable(sb, queryTable(r, o.Null), r.Expanded)
+	case pgxspecial.DescribeTableListResult:
+		for _, d := range r.Results {
+			o.writeTable(sb, describeTable(d), false)
+		}
+	case pgxspecial.ExtensionVerboseListResult:
+		for _, e := range r.Results {
+			o.writeTable(sb, extensionTable(e), false)
+		}
+	case pgxspecial.HelpResult:
+		if csv {
+			o.writeCSV(sb, helpTable(r))
+			break
+		}
+		writeHelp(sb, r)
+	case pgxspecial.SQLHelpResult:
+		if csv {
+			o.writeCSV(sb, sqlHelpTable(r))
+			break
+		}
+		writeSQLHelp(sb, r)
+	case pgxspecial.VariablesResult:
+		if csv {
+			o.writeCSV(sb, variablesTable(r))
+			break
+		}
+		for _, v := range r.Variables {
+			fmt.Fprintf(sb, "%s = '%s'\n", v.Name, v.Value)
+		}
+	case pgxspecial.ScriptResults:
+		for _, sr := range r.Results {
+			if sr.Err != nil {
+				sb.WriteString(sr.Err.Error() + "\n")
+				continue
+			}
+			if err := o.render(sb, sr.Result); err != nil {
+				return err
+			}
+		}
+	case pgxspecial.QueryBufferResult:
+		if r.Text == "" {
+			sb.WriteString("Query buffer is empty.\n")
+		} else {
+			sb.WriteString(r.Text + "\n")
+		}
+	case pgxspecial.TextResult:
+		if csv {
+			o.writeCSV(sb, textTable(r))
+			break
+		}
+		sb.WriteString(r.String())
+	case pgxspecial.StatusResult:
+		sb.WriteString(r.Message + "\n")
+	case pgxspecial.ShellResult:
+		sb.WriteString(r.Output)
+	case pgxspecial.EchoHiddenResult:
+		for _, q := range r.Queries {
+			sb.WriteString(q.String() + "\n")
+		}
+		return o.render(sb, r.Result)
+	default:
+		return fmt.Errorf("format: unsupported result %T", res)
+	}
+	return nil
+}
+
+// writeTable prints t in the output format, as vertical records if
+// expanded, in expanded mode, or in auto mode if it is printed aligned and
+// is wider than the columns option or the terminal.
+func (o Options) writeTable(sb *strings.Builder, t table, expanded bool) {
+	if t.query {
+		t.title = o.Title
+	}
+	if o.NumericLocale {
+		t = localize(t)
+	}
+
+	expanded = expanded || o.Expanded == pgxspecial.ExpandedOn
+	switch o.Format {
+	case pgxspecial.FormatUnaligned:
+		if expanded {
+			o.writeUnalignedExpanded(sb, t)
+		} else {
+			o.writeUnaligned(sb, t)
+		}
+	case pgxspecial.FormatHTML:
+		if expanded {
+			o.writeHTMLExpanded(sb, t)
+		} else {
+			o.writeHTML(sb, t)
+		}
+	case pgxspecial.FormatCSV:
+		if expanded {
+			o.writeCSVExpanded(sb, t)
+		} else {
+			o.writeCSV(sb, t)
+		}
+	default:
+		width := o.Columns
+		if width == 0 {
+			width = o.TermWidth
+		}
+		if !expanded && o.Expanded == pgxspecial.ExpandedAuto && width > 0 {
+			expanded = newLayout(t).width(o.border()) > width
+		}
+		if expanded {
+			o.writeExpanded(sb, t)
+		} else {
+			o.writeAligned(sb, t)
+		}
+	}
+}
+
+// footers returns the footers printed below t: its own, or else the number
+// of rows if it is counted and the footer option is on.
+func (o Options) footers(t table) []string {
+	if len(t.footers) == 0 && t.counted && o.Footer {
+		return []string{rowCount(len(t.cells))}
+	}
+	return t.footers
+}
+
+// writeHelp lists the commands of each group under its name, with their
+// syntax in a column, as \? does in psql.
+func writeHelp(sb *strings.Builder, r pgxspecial.HelpResult) {
+	for _, g := range r.Groups {
+		sb.WriteString(g.Name + "\n")
+		for _, c := range g.Commands {
+			syntax := c.Syntax
+			if syntax == "" {
+				syntax = c.Cmd
+			}
+			sb.WriteString("  " + syntax)
+			sb.WriteString(strings.Repeat(" ", max(22-displayWidth(syntax), 0)))
+			sb.WriteString(" " + c.Description + "\n")
+		}
+		sb.WriteString("\n")
+	}
+}
+
+// writeSQLHelp shows the synopsis of each topic, as \h does in psql.
+func writeSQLHelp(sb *strings.Builder, r pgxspecial.SQLHelpResult) {
+	for _, t := range r.Topics {
+		fmt.Fprintf(sb, "Command:     %s\n", t.Name)
+		fmt.Fprintf(sb, "Description: %s\n", t.Description)
+		fmt.Fprintf(sb, "Syntax:\n%s\n\n", strings.TrimRight(t.Synopsis, "\n"))
+		if t.URL != "" {
+			fmt.Fprintf(sb, "URL: %s\n\n", t.URL)
+		}
+	}
+}
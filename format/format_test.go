@@ -0,0 +1,348 @@
+package format_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/balaji01-4d/pgxspecial"
+	"github.com/balaji01-4d/pgxspecial/format"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRows holds rows of text values, nil for NULL, of columns whose types
+// are given by oids. With decoded, the values are sent in binary and
+// decoded to those.
+type fakeRows struct {
+	columns []string
+	oids    []uint32
+	values  [][]*string
+	decoded [][]any
+	next    int
+	closed  bool
+}
+
+func (r *fakeRows) Close()                        { r.closed = true }
+func (r *fakeRows) Err() error                    { return nil }
+func (r *fakeRows) CommandTag() pgconn.CommandTag { return pgconn.CommandTag{} }
+func (r *fakeRows) Scan(dest ...any) error        { return errors.New("not implemented") }
+func (r *fakeRows) Conn() *pgx.Conn               { return nil }
+
+func (r *fakeRows) FieldDescriptions() []pgconn.FieldDescription {
+	fds := make([]pgconn.FieldDescription, len(r.columns))
+	for i, name := range r.columns {
+		fds[i].Name = name
+		if i < len(r.oids) {
+			fds[i].DataTypeOID = r.oids[i]
+		}
+		if r.decoded != nil {
+			fds[i].Format = pgx.BinaryFormatCode
+		}
+	}
+	return fds
+}
+
+func (r *fakeRows) Values() ([]any, error) {
+	if r.decoded == nil {
+		return nil, errors.New("not implemented")
+	}
+	return r.decoded[r.next-1], nil
+}
+
+func (r *fakeRows) Next() bool {
+	r.next++
+	return r.next <= len(r.values)
+}
+
+func (r *fakeRows) RawValues() [][]byte {
+	raw := make([][]byte, len(r.values[r.next-1]))
+	for i, v := range r.values[r.next-1] {
+		if v != nil {
+			raw[i] = []byte(*v)
+		}
+	}
+	return raw
+}
+
+func text(s string) *string {
+	return &s
+}
+
+// lines joins the lines of the expected output, each ending with a newline.
+func lines(l ...string) string {
+	return strings.Join(l, "\n") + "\n"
+}
+
+func print(t *testing.T, res pgxspecial.SpecialCommandResult) string {
+	t.Helper()
+	var sb strings.Builder
+	require.NoError(t, format.Print(&sb, res))
+	return sb.String()
+}
+
+func TestPrintRows(t *testing.T) {
+	rows := &fakeRows{
+		columns: []string{"Name", "Owner", "Size"},
+		oids:    []uint32{pgtype.TextOID, pgtype.TextOID, pgtype.Int8OID},
+		values: [][]*string{
+			{text("postgres"), text("postgres"), text("7521")},
+			{text("template1"), nil, text("12")},
+		},
+	}
+	out := print(t, pgxspecial.RowResult{Rows: rows, Title: "List of databases"})
+	assert.Equal(t, lines(
+		"      List of databases",
+		"   Name    |  Owner   | Size ",
+		"-----------+----------+------",
+		" postgres  | postgres | 7521",
+		" template1 |          |   12",
+		"(2 rows)",
+		"",
+	), out)
+	assert.True(t, rows.closed)
+}
+
+func TestPrintRowsMultiLine(t *testing.T) {
+	rows := &fakeRows{
+		columns: []string{"Name", "Access privileges"},
+		values: [][]*string{
+			{text("t"), text("=c/postgres\npostgres=CTc/postgres")},
+			{text("long\nname"), text("")},
+		},
+	}
+	out := print(t, pgxspecial.RowResult{Rows: rows})
+	assert.Equal(t, lines(
+		" Name |   Access privileges   ",
+		"------+-----------------------",
+		" t    | =c/postgres          +",
+		"      | postgres=CTc/postgres",
+		" long+| ",
+		" name | ",
+		"(2 rows)",
+		"",
+	), out)
+}
+
+func TestPrintWideCharacters(t *testing.T) {
+	rows := &fakeRows{
+		columns: []string{"a", "b"},
+		values:  [][]*string{{text("日本"), text("x")}, {text("\u00e9"), text("y")}, {text("e\u0301"), text("z")}},
+	}
+	out := print(t, pgxspecial.RowResult{Rows: rows})
+	assert.Equal(t, lines(
+		"  a   | b ",
+		"------+---",
+		" 日本 | x",
+		" \u00e9    | y",
+		" e\u0301    | z",
+		"(3 rows)",
+		"",
+	), out)
+}
+
+func TestPrintBinaryValues(t *testing.T) {
+	rows := &fakeRows{
+		columns: []string{"ok", "ratio", "tags"},
+		oids:    []uint32{pgtype.BoolOID, pgtype.Float8OID, pgtype.TextArrayOID},
+		values:  [][]*string{{text("\x01"), text("-"), text("-")}},
+		decoded: [][]any{{true, 0.5, []any{"a", "b,c"}}},
+	}
+	out := print(t, pgxspecial.RowResult{Rows: rows})
+	assert.Equal(t, lines(
+		" ok | ratio |   tags    ",
+		"----+-------+-----------",
+		" t  |   0.5 | {a,\"b,c\"}",
+		"(1 row)",
+		"",
+	), out)
+}
+
+func TestPrintQuery(t *testing.T) {
+	out := print(t, pgxspecial.QueryResult{
+		Columns:    []string{"id", "name", "ok"},
+		Rows:       [][]any{{int32(1), "alice", true}, {int32(20), nil, false}},
+		CommandTag: "SELECT 2",
+	})
+	assert.Equal(t, lines(
+		" id | name  | ok ",
+		"----+-------+----",
+		"  1 | alice | t",
+		" 20 |       | f",
+		"(2 rows)",
+		"",
+	), out)
+
+	out = print(t, pgxspecial.QueryResult{CommandTag: "INSERT 0 1"})
+	assert.Equal(t, "INSERT 0 1\n", out)
+}
+
+func TestPrintDescribeTable(t *testing.T) {
+	res := pgxspecial.DescribeTableListResult{Results: []pgxspecial.DescribeTableResult{{
+		Title:   `Table "public.users"`,
+		RelKind: "r",
+		Columns: []string{"Column", "Type", "Modifiers"},
+		Data: [][]string{
+			{"id", "integer", " not null"},
+			{"name", "text", ""},
+		},
+		TableMetaData: pgxspecial.TableFooterMeta{
+			Indexes:          []string{`"users_pkey" PRIMARY KEY, btree (id)`},
+			CheckConstraints: []string{`"name_check" CHECK (name <> ''::text)`},
+			ReferencedBy:     []string{`TABLE "orders" CONSTRAINT "orders_user_fkey" FOREIGN KEY (user_id) REFERENCES users(id)`},
+			TriggersEnabled:  []string{"audit AFTER INSERT ON users FOR EACH ROW EXECUTE FUNCTION audit()"},
+			Inherits:         []string{"people", "accounts"},
+			Options:          text("fillfactor=70"),
+		},
+	}}}
+	assert.Equal(t, lines(
+		`     Table "public.users"`,
+		" Column |  Type   | Modifiers ",
+		"--------+---------+-----------",
+		" id     | integer |  not null",
+		" name   | text    | ",
+		"Indexes:",
+		`    "users_pkey" PRIMARY KEY, btree (id)`,
+		"Check constraints:",
+		`    "name_check" CHECK (name <> ''::text)`,
+		"Referenced by:",
+		`    TABLE "orders" CONSTRAINT "orders_user_fkey" FOREIGN KEY (user_id) REFERENCES users(id)`,
+		"Triggers:",
+		"    audit AFTER INSERT ON users FOR EACH ROW EXECUTE FUNCTION audit()",
+		"Inherits: people,",
+		"          accounts",
+		"Options: fillfactor=70",
+		"",
+	), print(t, res))
+}
+
+func TestPrintDescribeIndexAndPartitions(t *testing.T) {
+	res := pgxspecial.DescribeTableListResult{Results: []pgxspecial.DescribeTableResult{
+		{
+			Title:         `Index "public.users_pkey"`,
+			RelKind:       "i",
+			Columns:       []string{"Column", "Type", "Key?", "Definition"},
+			Data:          [][]string{{"id", "integer", "yes", "id"}},
+			TableMetaData: pgxspecial.TableFooterMeta{Options: text(`primary key, btree, for table "public.users"`)},
+		},
+		{
+			Title:   `Partitioned table "public.events"`,
+			RelKind: "p",
+			Columns: []string{"Column", "Type"},
+			Data:    [][]string{{"at", "date"}},
+			TableMetaData: pgxspecial.TableFooterMeta{
+				PartitionKey: text("RANGE (at)"),
+				Partitions: []string{
+					"public.events_2024 FOR VALUES FROM ('2024-01-01') TO ('2025-01-01')",
+					"public.events_2025 FOR VALUES FROM ('2025-01-01') TO ('2026-01-01')",
+				},
+			},
+		},
+	}}
+	assert.Equal(t, lines(
+		`      Index "public.users_pkey"`,
+		" Column |  Type   | Key? | Definition ",
+		"--------+---------+------+------------",
+		" id     | integer | yes  | id",
+		`primary key, btree, for table "public.users"`,
+		"",
+		`Partitioned table "public.events"`,
+		" Column | Type ",
+		"--------+------",
+		" at     | date",
+		"Partition key: RANGE (at)",
+		"Partitions: public.events_2024 FOR VALUES FROM ('2024-01-01') TO ('2025-01-01'),",
+		"            public.events_2025 FOR VALUES FROM ('2025-01-01') TO ('2026-01-01')",
+		"",
+	), print(t, res))
+}
+
+func TestPrintExtensionVerbose(t *testing.T) {
+	res := pgxspecial.ExtensionVerboseListResult{Results: []pgxspecial.ExtensionVerboseResult{{
+		Name:        "plpgsql",
+		Description: []string{"function plpgsql_call_handler()", "language plpgsql"},
+	}}}
+	assert.Equal(t, lines(
+		` Objects in extension "plpgsql"`,
+		"       Object description        ",
+		"---------------------------------",
+		" function plpgsql_call_handler()",
+		" language plpgsql",
+		"(2 rows)",
+		"",
+	), print(t, res))
+}
+
+func TestPrintText(t *testing.T) {
+	tests := []struct {
+		name string
+		res  pgxspecial.SpecialCommandResult
+		want string
+	}{
+		{"nil", nil, ""},
+		{"status", pgxspecial.StatusResult{Message: "Query buffer reset (cleared)."}, "Query buffer reset (cleared).\n"},
+		{"empty buffer", pgxspecial.QueryBufferResult{}, "Query buffer is empty.\n"},
+		{"buffer", pgxspecial.QueryBufferResult{Text: "select 1"}, "select 1\n"},
+		{"text", pgxspecial.NewTextResult("line 1\nline 2\n"), "line 1\nline 2\n"},
+		{"shell", pgxspecial.ShellResult{Output: "hello\n"}, "hello\n"},
+		{
+			"variables",
+			pgxspecial.VariablesResult{Variables: []pgxspecial.Variable{{Name: "A", Value: "1"}, {Name: "B", Value: "it's"}}},
+			"A = '1'\nB = 'it's'\n",
+		},
+		{
+			"help",
+			pgxspecial.HelpResult{Groups: []pgxspecial.CommandGroup{{
+				Name: "General",
+				Commands: []pgxspecial.SpecialCommand{
+					{Cmd: "\\q", Description: "quit psql"},
+					{Cmd: "\\copyright", Syntax: "\\copyright", Description: "show PostgreSQL usage and distribution terms"},
+				},
+			}}},
+			"General\n  \\q                     quit psql\n  \\copyright             show PostgreSQL usage and distribution terms\n\n",
+		},
+		{
+			"sql help",
+			pgxspecial.SQLHelpResult{Topics: []pgxspecial.SQLHelpTopic{{
+				Name:        "ABORT",
+				Description: "abort the current transaction",
+				Synopsis:    "ABORT [ WORK | TRANSACTION ] [ AND [ NO ] CHAIN ]",
+				URL:         "https://www.postgresql.org/docs/17/sql-abort.html",
+			}}},
+			"Command:     ABORT\nDescription: abort the current transaction\nSyntax:\nABORT [ WORK | TRANSACTION ] [ AND [ NO ] CHAIN ]\n\nURL: https://www.postgresql.org/docs/17/sql-abort.html\n\n",
+		},
+		{
+			"script",
+			pgxspecial.ScriptResults{Results: []pgxspecial.ScriptResult{
+				{Result: pgxspecial.QueryResult{CommandTag: "CREATE TABLE"}},
+				{Err: errors.New("ERROR: boom")},
+			}},
+			"CREATE TABLE\nERROR: boom\n",
+		},
+		{
+			"echo hidden",
+			pgxspecial.EchoHiddenResult{
+				Queries: []pgxspecial.HiddenQuery{{SQL: "SELECT 1"}},
+				Result:  pgxspecial.StatusResult{Message: "done"},
+			},
+			"/******** QUERY *********/\nSELECT 1\n/************************/\n\ndone\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, print(t, tt.res))
+		})
+	}
+}
+
+type unknownResult struct{}
+
+func (unknownResult) ResultKind() pgxspecial.SpecialResultKind { return -1 }
+
+func TestPrintUnsupported(t *testing.T) {
+	err := format.Print(&strings.Builder{}, unknownResult{})
+	assert.ErrorContains(t, err, "unsupported result")
+}
@@ -0,0 +1,131 @@
+package format
+
+import (
+	"fmt"
+	"strings"
+)
+
+// writeHTML prints t as psql's html format does: a table with the title as
+// caption, a row of headers and a row per row of t, followed by the
+// footers in a paragraph.
+func (o Options) writeHTML(sb *strings.Builder, t table) {
+	fmt.Fprintf(sb, "<table border=\"%d\">\n", o.Border)
+	if !o.TuplesOnly {
+		writeHTMLCaption(sb, t.title)
+		sb.WriteString("  <tr>\n")
+		for _, h := range t.headers {
+			sb.WriteString("    <th align=\"center\">" + htmlEscape(h) + "</th>\n")
+		}
+		sb.WriteString("  </tr>\n")
+	}
+	for _, row := range t.cells {
+		sb.WriteString("  <tr valign=\"top\">\n")
+		for i, cell := range row {
+			writeHTMLCell(sb, cell, t.aligns[i])
+		}
+		sb.WriteString("  </tr>\n")
+	}
+	sb.WriteString("</table>\n")
+	if !o.TuplesOnly {
+		writeHTMLFooters(sb, o.footers(t))
+	}
+	sb.WriteString("\n")
+}
+
+// writeHTMLExpanded prints t as psql's html format does in expanded mode:
+// a table holding a row per column of each record, under a row numbering
+// the record.
+func (o Options) writeHTMLExpanded(sb *strings.Builder, t table) {
+	fmt.Fprintf(sb, "<table border=\"%d\">\n", o.Border)
+	if !o.TuplesOnly {
+		writeHTMLCaption(sb, t.title)
+	}
+	for r, row := range t.cells {
+		if o.TuplesOnly {
+			sb.WriteString("\n  <tr><td colspan=\"2\">&nbsp;</td></tr>\n")
+		} else {
+			fmt.Fprintf(sb, "\n  <tr><td colspan=\"2\" align=\"center\">Record %d</td></tr>\n", r+1)
+		}
+		for i, h := range t.headers {
+			sb.WriteString("  <tr valign=\"top\">\n")
+			sb.WriteString("    <th>" + htmlEscape(h) + "</th>\n")
+			var cell string
+			if i < len(row) {
+				cell = row[i]
+			}
+			writeHTMLCell(sb, cell, t.aligns[i])
+			sb.WriteString("  </tr>\n")
+		}
+	}
+	sb.WriteString("</table>\n")
+	if !o.TuplesOnly {
+		writeHTMLFooters(sb, t.footers)
+	}
+	sb.WriteString("\n")
+}
+
+func writeHTMLCaption(sb *strings.Builder, title string) {
+	if title != "" {
+		sb.WriteString("  <caption>" + htmlEscape(title) + "</caption>\n")
+	}
+}
+
+// writeHTMLCell prints a cell, with a non-breaking space in place of blank
+// values so that the cell is drawn.
+func writeHTMLCell(sb *strings.Builder, cell string, align byte) {
+	a := "left"
+	if align == alignRight {
+		a = "right"
+	}
+	sb.WriteString("    <td align=\"" + a + "\">")
+	if strings.Trim(cell, " \t") == "" {
+		sb.WriteString("&nbsp; ")
+	} else {
+		sb.WriteString(htmlEscape(cell))
+	}
+	sb.WriteString("</td>\n")
+}
+
+func writeHTMLFooters(sb *strings.Builder, footers []string) {
+	if len(footers) == 0 {
+		return
+	}
+	sb.WriteString("<p>")
+	for _, f := range footers {
+		sb.WriteString(htmlEscape(f) + "<br />\n")
+	}
+	sb.WriteString("</p>")
+}
+
+// htmlEscape escapes s as psql does, breaking lines with <br /> and keeping
+// the spaces it starts with.
+func htmlEscape(s string) string {
+	var sb strings.Builder
+	leading := true
+	for _, c := range s {
+		switch c {
+		case '&':
+			sb.WriteString("&amp;")
+		case '<':
+			sb.WriteString("&lt;")
+		case '>':
+			sb.WriteString("&gt;")
+		case '"':
+			sb.WriteString("&quot;")
+		case '\n':
+			sb.WriteString("<br />\n")
+		case ' ':
+			if leading {
+				sb.WriteString("&nbsp;")
+			} else {
+				sb.WriteString(" ")
+			}
+		default:
+			sb.WriteRune(c)
+		}
+		if c != ' ' {
+			leading = false
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,74 @@
+package format_test
+
+import (
+	"testing"
+
+	"github.com/balaji01-4d/pgxspecial"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrintHTML(t *testing.T) {
+	res := pgxspecial.QueryResult{
+		Columns: []string{"a", "b"},
+		Rows:    [][]any{{int32(1), "  <x>\ny"}, {int32(2), "  "}},
+	}
+	o := options()
+	o.Format = pgxspecial.FormatHTML
+	o.Title = "T & U"
+	assert.Equal(t, lines(
+		`<table border="1">`,
+		`  <caption>T &amp; U</caption>`,
+		`  <tr>`,
+		`    <th align="center">a</th>`,
+		`    <th align="center">b</th>`,
+		`  </tr>`,
+		`  <tr valign="top">`,
+		`    <td align="right">1</td>`,
+		`    <td align="left">&nbsp;&nbsp;&lt;x&gt;<br />`,
+		`y</td>`,
+		`  </tr>`,
+		`  <tr valign="top">`,
+		`    <td align="right">2</td>`,
+		`    <td align="left">&nbsp; </td>`,
+		`  </tr>`,
+		`</table>`,
+		`<p>(2 rows)<br />`,
+		`</p>`,
+	), printWith(t, o, res))
+
+	o.TuplesOnly = true
+	o.Border = 2
+	assert.Equal(t, lines(
+		`<table border="2">`,
+		`  <tr valign="top">`,
+		`    <td align="right">2</td>`,
+		`  </tr>`,
+		`</table>`,
+		``,
+	), printWith(t, o, pgxspecial.QueryResult{Columns: []string{"a"}, Rows: [][]any{{int32(2)}}}))
+}
+
+func TestPrintHTMLExpanded(t *testing.T) {
+	o := options()
+	o.Format = pgxspecial.FormatHTML
+	o.Expanded = pgxspecial.ExpandedOn
+	res := pgxspecial.QueryResult{
+		Columns: []string{"a", "b"},
+		Rows:    [][]any{{int32(1), "x"}},
+	}
+	assert.Equal(t, lines(
+		`<table border="1">`,
+		``,
+		`  <tr><td colspan="2" align="center">Record 1</td></tr>`,
+		`  <tr valign="top">`,
+		`    <th>a</th>`,
+		`    <td align="right">1</td>`,
+		`  </tr>`,
+		`  <tr valign="top">`,
+		`    <th>b</th>`,
+		`    <td align="left">x</td>`,
+		`  </tr>`,
+		`</table>`,
+		``,
+	), printWith(t, o, res))
+}
@@ -0,0 +1,611 @@
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/balaji01-4d/pgxspecial"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// JSONVersion is the version of the schema of the documents written by
+// WriteJSON and NDJSONWriter, given in their "version" field. It changes
+// when a field is removed or changes meaning; fields and kinds may be
+// added within a version.
+const JSONVersion = 1
+
+// WriteJSON writes res to w as a JSON document followed by a newline. The
+// document holds the schema version, the kind of the result as named by
+// pgxspecial.SpecialResultKind.String, and the fields of that kind. Rows
+// are objects keyed by column name, in column order, with values of the
+// JSON type matching their column: booleans, numbers, arrays and objects,
+// dates and times as ISO 8601 strings, and other values as the text
+// PostgreSQL gives them. The rows of a RowResult are read and closed.
+func WriteJSON(w io.Writer, res pgxspecial.SpecialCommandResult) error {
+	doc, err := document(res, JSONVersion)
+	if err != nil {
+		return err
+	}
+	return json.NewEncoder(w).Encode(doc)
+}
+
+// NDJSONWriter writes results as newline-delimited JSON, so that rows can
+// be consumed as they are read. Each result is written as the document
+// WriteJSON writes, on a line of its own, except for results with rows:
+// a line holding the document without its rows is followed by a line per
+// row, {"kind":"row","row":{...}}, and a closing line,
+// {"kind":"end","row_count":N}. The results of a script are each preceded
+// by a "statement" line holding the statement and its error, if any.
+type NDJSONWriter struct {
+	enc *json.Encoder
+}
+
+// NewNDJSONWriter returns a writer of newline-delimited JSON to w.
+func NewNDJSONWriter(w io.Writer) *NDJSONWriter {
+	return &NDJSONWriter{enc: json.NewEncoder(w)}
+}
+
+// Write writes res. The rows of a RowResult are read and closed.
+func (w *NDJSONWriter) Write(res pgxspecial.SpecialCommandResult) error {
+	switch r := res.(type) {
+	case pgxspecial.RowResult:
+		defer r.Rows.Close()
+		cols, keys := rowsColumns(r.Rows)
+		head := rowsHeader{header: header{JSONVersion, r.ResultKind().String()}, Title: r.Title, Columns: cols}
+		if err := w.enc.Encode(head); err != nil {
+			return err
+		}
+		n := 0
+		err := readRows(r.Rows, func(values []any) error {
+			n++
+			return w.enc.Encode(rowLine{"row", object{keys, values}})
+		})
+		if err != nil {
+			return err
+		}
+		return w.enc.Encode(endLine{Kind: "end", RowCount: n, CommandTag: r.Rows.CommandTag().String()})
+	case pgxspecial.QueryResult:
+		if len(r.Columns) == 0 {
+			break
+		}
+		doc := queryDocument(r, JSONVersion)
+		if err := w.enc.Encode(doc.rowsHeader); err != nil {
+			return err
+		}
+		for _, row := range doc.Rows {
+			if err := w.enc.Encode(rowLine{"row", row}); err != nil {
+				return err
+			}
+		}
+		return w.enc.Encode(endLine{Kind: "end", RowCount: len(doc.Rows), CommandTag: r.CommandTag})
+	case pgxspecial.ScriptResults:
+		for _, sr := range r.Results {
+			line := statementLine{header: header{JSONVersion, "statement"}, script: newScript(sr)}
+			if err := w.enc.Encode(line); err != nil {
+				return err
+			}
+			if sr.Result != nil {
+				if err := w.Write(sr.Result); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
+	doc, err := document(res, JSONVersion)
+	if err != nil {
+		return err
+	}
+	return w.enc.Encode(doc)
+}
+
+// header starts every document. Only the outermost document of a result
+// gives the version.
+type header struct {
+	Version int    `json:"version,omitempty"`
+	Kind    string `json:"kind"`
+}
+
+type column struct {
+	Name    string `json:"name"`
+	Type    string `json:"type,omitempty"` // type name, as in pg_type
+	TypeOID uint32 `json:"type_oid,omitempty"`
+}
+
+type rowsHeader struct {
+	header
+	Title   string   `json:"title,omitempty"`
+	Columns []column `json:"columns"`
+}
+
+type rowsDocument struct {
+	rowsHeader
+	Rows       []object `json:"rows"`
+	CommandTag string   `json:"command_tag,omitempty"`
+}
+
+type commandDocument struct {
+	header
+	CommandTag string `json:"command_tag"`
+}
+
+type rowLine struct {
+	Kind string `json:"kind"`
+	Row  object `json:"row"`
+}
+
+type endLine struct {
+	Kind       string `json:"kind"`
+	RowCount   int    `json:"row_count"`
+	CommandTag string `json:"command_tag,omitempty"`
+}
+
+type describeDocument struct {
+	header
+	Tables []describedTable `json:"tables"`
+}
+
+type describedTable struct {
+	Title   string                     `json:"title"`
+	RelKind string                     `json:"relkind"`
+	Columns []string                   `json:"columns"`
+	Rows    []object                   `json:"rows"`
+	Footers pgxspecial.TableFooterMeta `json:"footers"`
+}
+
+type extensionDocument struct {
+	header
+	Extensions []pgxspecial.ExtensionVerboseResult `json:"extensions"`
+}
+
+type helpDocument struct {
+	header
+	Groups []helpGroup `json:"groups"`
+}
+
+type helpGroup struct {
+	Name     string        `json:"name"`
+	Commands []helpCommand `json:"commands"`
+}
+
+type helpCommand struct {
+	Command     string `json:"command"`
+	Syntax      string `json:"syntax"`
+	Description string `json:"description"`
+}
+
+type sqlHelpDocument struct {
+	header
+	Topics []sqlHelpTopic `json:"topics"`
+}
+
+type sqlHelpTopic struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Synopsis    string `json:"synopsis"`
+	URL         string `json:"url,omitempty"`
+}
+
+type variablesDocument struct {
+	header
+	Variables []variable `json:"variables"`
+}
+
+type variable struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type scriptDocument struct {
+	header
+	Results []script `json:"results"`
+}
+
+type script struct {
+	File      string    `json:"file,omitempty"`
+	Statement statement `json:"statement"`
+	Result    any       `json:"result,omitempty"`
+	Error     string    `json:"error,omitempty"`
+}
+
+type statement struct {
+	Text string `json:"text"`
+	Line int    `json:"line"`
+}
+
+type statementLine struct {
+	header
+	script
+}
+
+type textDocument struct {
+	header
+	Text string `json:"text"`
+}
+
+type linesDocument struct {
+	header
+	Lines []textLine `json:"lines"`
+}
+
+type textLine struct {
+	Number int    `json:"number,omitempty"`
+	Text   string `json:"text"`
+}
+
+type statusDocument struct {
+	header
+	Message string `json:"message"`
+}
+
+type shellDocument struct {
+	header
+	Output   string `json:"output"`
+	ExitCode int    `json:"exit_code"`
+}
+
+type echoHiddenDocument struct {
+	header
+	Queries []hiddenQuery `json:"queries"`
+	Result  any           `json:"result"`
+}
+
+type hiddenQuery struct {
+	SQL  string `json:"sql"`
+	Args []any  `json:"args,omitempty"`
+}
+
+// document returns the JSON document of res, nil for a nil result.
+func document(res pgxspecial.SpecialCommandResult, version int) (any, error) {
+	if res == nil {
+		return nil, nil
+	}
+	h := header{version, res.ResultKind().String()}
+	switch r := res.(type) {
+	case pgxspecial.RowResult:
+		defer r.Rows.Close()
+		cols, keys := rowsColumns(r.Rows)
+		doc := rowsDocument{rowsHeader: rowsHeader{header: h, Title: r.Title, Columns: cols}, Rows: []object{}}
+		err := readRows(r.Rows, func(values []any) error {
+			doc.Rows = append(doc.Rows, object{keys, values})
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		doc.CommandTag = r.Rows.CommandTag().String()
+		return doc, nil
+	case pgxspecial.QueryResult:
+		if len(r.Columns) == 0 {
+			// statements without rows are reported by their command tag
+			return commandDocument{header: h, CommandTag: r.CommandTag}, nil
+		}
+		return queryDocument(r, version), nil
+	case pgxspecial.DescribeTableListResult:
+		doc := describeDocument{header: h, Tables: []describedTable{}}
+		for _, d := range r.Results {
+			keys := columnKeys(d.Columns)
+			t := describedTable{Title: d.Title, RelKind: d.RelKind, Columns: d.Columns, Rows: []object{}, Footers: d.TableMetaData}
+			for _, row := range d.Data {
+				values := make([]any, len(keys))
+				for i := range values {
+					if i < len(row) {
+						values[i] = row[i]
+					}
+				}
+				t.Rows = append(t.Rows, object{keys, values})
+			}
+			doc.Tables = append(doc.Tables, t)
+		}
+		return doc, nil
+	case pgxspecial.ExtensionVerboseListResult:
+		doc := extensionDocument{header: h, Extensions: r.Results}
+		if doc.Extensions == nil {
+			doc.Extensions = []pgxspecial.ExtensionVerboseResult{}
+		}
+		return doc, nil
+	case pgxspecial.HelpResult:
+		doc := helpDocument{header: h, Groups: []helpGroup{}}
+		for _, g := range r.Groups {
+			group := helpGroup{Name: g.Name, Commands: []helpCommand{}}
+			for _, c := range g.Commands {
+				group.Commands = append(group.Commands, helpCommand{c.Cmd, c.Syntax, c.Description})
+			}
+			doc.Groups = append(doc.Groups, group)
+		}
+		return doc, nil
+	case pgxspecial.SQLHelpResult:
+		doc := sqlHelpDocument{header: h, Topics: []sqlHelpTopic{}}
+		for _, t := range r.Topics {
+			doc.Topics = append(doc.Topics, sqlHelpTopic{t.Name, t.Description, t.Synopsis, t.URL})
+		}
+		return doc, nil
+	case pgxspecial.VariablesResult:
+		doc := variablesDocument{header: h, Variables: []variable{}}
+		for _, v := range r.Variables {
+			doc.Variables = append(doc.Variables, variable{v.Name, v.Value})
+		}
+		return doc, nil
+	case pgxspecial.ScriptResults:
+		doc := scriptDocument{header: h, Results: []script{}}
+		for _, sr := range r.Results {
+			s := newScript(sr)
+			result, err := document(sr.Result, 0)
+			if err != nil {
+				return nil, err
+			}
+			s.Result = result
+			doc.Results = append(doc.Results, s)
+		}
+		return doc, nil
+	case pgxspecial.QueryBufferResult:
+		return textDocument{header: h, Text: r.Text}, nil
+	case pgxspecial.TextResult:
+		doc := linesDocument{header: h, Lines: []textLine{}}
+		for _, l := range r.Lines {
+			doc.Lines = append(doc.Lines, textLine{l.Number, l.Text})
+		}
+		return doc, nil
+	case pgxspecial.StatusResult:
+		return statusDocument{header: h, Message: r.Message}, nil
+	case pgxspecial.ShellResult:
+		return shellDocument{header: h, Output: r.Output, ExitCode: r.ExitCode}, nil
+	case pgxspecial.EchoHiddenResult:
+		m := typeMap(nil)
+		doc := echoHiddenDocument{header: h, Queries: []hiddenQuery{}}
+		for _, q := range r.Queries {
+			hq := hiddenQuery{SQL: q.SQL}
+			for _, a := range q.Args {
+				hq.Args = append(hq.Args, jsonValue(m, 0, a))
+			}
+			doc.Queries = append(doc.Queries, hq)
+		}
+		result, err := document(r.Result, 0)
+		if err != nil {
+			return nil, err
+		}
+		doc.Result = result
+		return doc, nil
+	}
+	return nil, fmt.Errorf("format: unsupported result %T", res)
+}
+
+// queryDocument returns the document of the rows of a script statement,
+// whose column types are unknown. The statement must return rows.
+func queryDocument(r pgxspecial.QueryResult, version int) rowsDocument {
+	m := typeMap(nil)
+	doc := rowsDocument{
+		rowsHeader: rowsHeader{header: header{version, r.ResultKind().String()}, Columns: []column{}},
+		Rows:       []object{},
+		CommandTag: r.CommandTag,
+	}
+	for _, name := range r.Columns {
+		doc.Columns = append(doc.Columns, column{Name: name})
+	}
+	keys := columnKeys(r.Columns)
+	for _, row := range r.Rows {
+		values := make([]any, len(keys))
+		for i := range values {
+			if i < len(row) {
+				values[i] = jsonValue(m, 0, row[i])
+			}
+		}
+		doc.Rows = append(doc.Rows, object{keys, values})
+	}
+	return doc
+}
+
+func newScript(sr pgxspecial.ScriptResult) script {
+	s := script{File: sr.File, Statement: statement{sr.Statement.Text, sr.Statement.Line}}
+	if sr.Err != nil {
+		s.Error = sr.Err.Error()
+	}
+	return s
+}
+
+// rowsColumns describes the columns of rows, and returns the keys of their
+// values in the row objects.
+func rowsColumns(rows pgx.Rows) ([]column, []string) {
+	m := typeMap(rows)
+	fields := rows.FieldDescriptions()
+	cols := make([]column, len(fields))
+	names := make([]string, len(fields))
+	for i, fd := range fields {
+		cols[i] = column{Name: fd.Name, TypeOID: fd.DataTypeOID}
+		if t, ok := m.TypeForOID(fd.DataTypeOID); ok {
+			cols[i].Type = t.Name
+		}
+		names[i] = fd.Name
+	}
+	return cols, columnKeys(names)
+}
+
+// readRows calls fn with the JSON values of each row of rows.
+func readRows(rows pgx.Rows, fn func(values []any) error) error {
+	m := typeMap(rows)
+	fields := rows.FieldDescriptions()
+	for rows.Next() {
+		raw := rows.RawValues()
+		var decoded []any
+		values := make([]any, len(fields))
+		for i, fd := range fields {
+			v, ok := rawValue(m, fd, raw[i])
+			if !ok {
+				// values sent in binary are decoded by the rows
+				if decoded == nil {
+					var err error
+					if decoded, err = rows.Values(); err != nil {
+						return err
+					}
+				}
+				v = decoded[i]
+			}
+			values[i] = jsonValue(m, fd.DataTypeOID, v)
+		}
+		if err := fn(values); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// rawValue decodes a value as sent, keeping JSON values as they are. It
+// reports false for values sent in binary other than JSON.
+func rawValue(m *pgtype.Map, fd pgconn.FieldDescription, raw []byte) (any, bool) {
+	if raw == nil {
+		return nil, true
+	}
+	text := fd.Format == pgx.TextFormatCode
+	switch fd.DataTypeOID {
+	case pgtype.JSONOID, pgtype.JSONBOID:
+		doc := raw
+		if !text && fd.DataTypeOID == pgtype.JSONBOID && len(doc) > 0 && doc[0] == 1 {
+			// binary jsonb starts with its format version
+			doc = doc[1:]
+		}
+		if json.Valid(doc) {
+			return json.RawMessage(doc), true
+		}
+	}
+	if !text {
+		return nil, false
+	}
+	if t, ok := m.TypeForOID(fd.DataTypeOID); ok {
+		if v, err := t.Codec.DecodeValue(m, fd.DataTypeOID, pgx.TextFormatCode, raw); err == nil {
+			return v, true
+		}
+	}
+	return string(raw), true
+}
+
+// jsonValue returns v, a value of the type oid, or of an unknown type if
+// oid is 0, as a value of the matching JSON type.
+func jsonValue(m *pgtype.Map, oid uint32, v any) any {
+	switch v := v.(type) {
+	case nil, bool, string, json.RawMessage, map[string]any,
+		int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return v
+	case float32:
+		return jsonFloat(float64(v))
+	case float64:
+		return jsonFloat(v)
+	case pgtype.Numeric:
+		if !v.Valid {
+			return nil
+		}
+		s := formatValue(m, pgtype.NumericOID, v)
+		if v.NaN || v.InfinityModifier != pgtype.Finite {
+			return s
+		}
+		return json.Number(s)
+	case time.Time:
+		switch oid {
+		case pgtype.DateOID:
+			return v.Format(time.DateOnly)
+		case pgtype.TimestampOID:
+			return v.Format("2006-01-02T15:04:05.999999999")
+		}
+		return v.Format(time.RFC3339Nano)
+	case []any:
+		elem := elementOID(m, oid)
+		a := make([]any, len(v))
+		for i, e := range v {
+			a[i] = jsonValue(m, elem, e)
+		}
+		return a
+	}
+	return formatValue(m, oid, v)
+}
+
+// jsonFloat returns f, or the text PostgreSQL gives the values JSON cannot
+// hold.
+func jsonFloat(f float64) any {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "Infinity"
+	case math.IsInf(f, -1):
+		return "-Infinity"
+	}
+	return f
+}
+
+// elementOID returns the type of the elements of the array type oid, or 0.
+func elementOID(m *pgtype.Map, oid uint32) uint32 {
+	if t, ok := m.TypeForOID(oid); ok {
+		if ac, ok := t.Codec.(*pgtype.ArrayCodec); ok {
+			return ac.ElementType.OID
+		}
+	}
+	return 0
+}
+
+// columnKeys returns the keys of the columns named names in row objects:
+// their names, followed by their position among the columns of the same
+// name for the second and later ones, as in "id", "id_2". A position is
+// skipped if its key is the name of another column or already a key, so
+// that the columns id, id and id_2 get the keys "id", "id_3" and "id_2".
+func columnKeys(names []string) []string {
+	taken := make(map[string]bool, len(names))
+	for _, name := range names {
+		taken[name] = true
+	}
+	keys := make([]string, len(names))
+	used := make(map[string]bool, len(names))
+	seen := make(map[string]int, len(names))
+	for i, name := range names {
+		seen[name]++
+		key := name
+		if used[key] {
+			for n := seen[name]; ; n++ {
+				key = name + "_" + strconv.Itoa(n)
+				if !taken[key] && !used[key] {
+					seen[name] = n
+					break
+				}
+			}
+		}
+		used[key] = true
+		keys[i] = key
+	}
+	return keys
+}
+
+// object is a row as a JSON object, with its values under the keys of
+// their columns, in column order.
+type object struct {
+	keys   []string
+	values []any
+}
+
+func (o object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, k := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(o.values[i])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,190 @@
+package format_test
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/balaji01-4d/pgxspecial"
+	"github.com/balaji01-4d/pgxspecial/format"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeJSON(t *testing.T, res pgxspecial.SpecialCommandResult) string {
+	t.Helper()
+	var sb strings.Builder
+	require.NoError(t, format.WriteJSON(&sb, res))
+	return sb.String()
+}
+
+func accounts() *fakeRows {
+	return &fakeRows{
+		columns: []string{"id", "name", "active", "tags", "data", "born", "seen", "balance", "id"},
+		oids: []uint32{
+			pgtype.Int4OID, pgtype.TextOID, pgtype.BoolOID, pgtype.TextArrayOID, pgtype.JSONBOID,
+			pgtype.DateOID, pgtype.TimestamptzOID, pgtype.NumericOID, pgtype.Int8OID,
+		},
+		values: [][]*string{{
+			text("1"), text("alice"), text("t"), text("{a,b}"), text(`{"k": [1, 2]}`),
+			text("2024-01-02"), text("2024-01-02 03:04:05+00"), text("12.50"), nil,
+		}},
+	}
+}
+
+func TestWriteJSONRows(t *testing.T) {
+	rows := accounts()
+	out := writeJSON(t, pgxspecial.RowResult{Rows: rows, Title: "Accounts"})
+	assert.Equal(t, `{"version":1,"kind":"rows","title":"Accounts","columns":[`+
+		`{"name":"id","type":"int4","type_oid":23},`+
+		`{"name":"name","type":"text","type_oid":25},`+
+		`{"name":"active","type":"bool","type_oid":16},`+
+		`{"name":"tags","type":"_text","type_oid":1009},`+
+		`{"name":"data","type":"jsonb","type_oid":3802},`+
+		`{"name":"born","type":"date","type_oid":1082},`+
+		`{"name":"seen","type":"timestamptz","type_oid":1184},`+
+		`{"name":"balance","type":"numeric","type_oid":1700},`+
+		`{"name":"id","type":"int8","type_oid":20}],`+
+		`"rows":[{"id":1,"name":"alice","active":true,"tags":["a","b"],"data":{"k":[1,2]},`+
+		`"born":"2024-01-02","seen":"2024-01-02T03:04:05Z","balance":12.50,"id_2":null}]}`+"\n", out)
+	assert.True(t, rows.closed)
+
+	// values sent in binary are decoded by the rows
+	binary := &fakeRows{
+		columns: []string{"f", "n"},
+		oids:    []uint32{pgtype.Float8OID, pgtype.Int8OID},
+		values:  [][]*string{{text(""), text("")}},
+		decoded: [][]any{{math.NaN(), int64(7)}},
+	}
+	assert.Contains(t, writeJSON(t, pgxspecial.RowResult{Rows: binary}), `"rows":[{"f":"NaN","n":7}]`)
+
+	empty := &fakeRows{columns: []string{"a"}, oids: []uint32{pgtype.TextOID}}
+	assert.Contains(t, writeJSON(t, pgxspecial.RowResult{Rows: empty}), `"rows":[]`)
+}
+
+func TestWriteJSONQuery(t *testing.T) {
+	out := writeJSON(t, pgxspecial.QueryResult{
+		Columns:    []string{"a", "b"},
+		Rows:       [][]any{{int32(1), []any{"x", nil}}, {nil, 2.5}},
+		CommandTag: "SELECT 2",
+	})
+	assert.Equal(t, `{"version":1,"kind":"query","columns":[{"name":"a"},{"name":"b"}],`+
+		`"rows":[{"a":1,"b":["x",null]},{"a":null,"b":2.5}],"command_tag":"SELECT 2"}`+"\n", out)
+
+	// numbered keys skip the names of other columns
+	out = writeJSON(t, pgxspecial.QueryResult{
+		Columns: []string{"id", "id", "id_2", "id"},
+		Rows:    [][]any{{1, 2, 3, 4}},
+	})
+	assert.Contains(t, out, `"rows":[{"id":1,"id_3":2,"id_2":3,"id_4":4}]`)
+
+	out = writeJSON(t, pgxspecial.QueryResult{CommandTag: "INSERT 0 1"})
+	assert.Equal(t, `{"version":1,"kind":"query","command_tag":"INSERT 0 1"}`+"\n", out)
+}
+
+func TestWriteJSONDescribe(t *testing.T) {
+	view := "SELECT 1;"
+	res := pgxspecial.DescribeTableListResult{Results: []pgxspecial.DescribeTableResult{{
+		Title:   `View "public.v"`,
+		RelKind: "v",
+		Columns: []string{"Column", "Type"},
+		Data:    [][]string{{"?column?", "integer"}},
+		TableMetaData: pgxspecial.TableFooterMeta{
+			ViewDefinition: &view,
+			Indexes:        []string{"i"},
+		},
+	}}}
+	assert.Equal(t, `{"version":1,"kind":"describe_table","tables":[{"title":"View \"public.v\"","relkind":"v",`+
+		`"columns":["Column","Type"],"rows":[{"Column":"?column?","Type":"integer"}],`+
+		`"footers":{"indexes":["i"],"view_definition":"SELECT 1;"}}]}`+"\n", writeJSON(t, res))
+}
+
+func TestWriteJSONText(t *testing.T) {
+	tests := []struct {
+		res  pgxspecial.SpecialCommandResult
+		want string
+	}{
+		{
+			pgxspecial.ExtensionVerboseListResult{Results: []pgxspecial.ExtensionVerboseResult{{Name: "plpgsql", Description: []string{"language plpgsql"}}}},
+			`{"version":1,"kind":"extension_verbose","extensions":[{"name":"plpgsql","description":["language plpgsql"]}]}`,
+		},
+		{
+			pgxspecial.HelpResult{Groups: []pgxspecial.CommandGroup{{Name: "General", Commands: []pgxspecial.SpecialCommand{{Cmd: `\q`, Description: "quit psql"}}}}},
+			`{"version":1,"kind":"help","groups":[{"name":"General","commands":[{"command":"\\q","syntax":"","description":"quit psql"}]}]}`,
+		},
+		{
+			pgxspecial.VariablesResult{Variables: []pgxspecial.Variable{{Name: "a", Value: "1"}}},
+			`{"version":1,"kind":"variables","variables":[{"name":"a","value":"1"}]}`,
+		},
+		{
+			pgxspecial.TextResult{Lines: []pgxspecial.TextLine{{Number: 1, Text: "BEGIN"}, {Text: "END"}}},
+			`{"version":1,"kind":"text","lines":[{"number":1,"text":"BEGIN"},{"text":"END"}]}`,
+		},
+		{
+			pgxspecial.StatusResult{Message: "Border style is 2."},
+			`{"version":1,"kind":"status","message":"Border style is 2."}`,
+		},
+		{
+			pgxspecial.ShellResult{Output: "ok\n", ExitCode: 1},
+			`{"version":1,"kind":"shell","output":"ok\n","exit_code":1}`,
+		},
+		{
+			pgxspecial.EchoHiddenResult{
+				Queries: []pgxspecial.HiddenQuery{{SQL: "SELECT $1", Args: []any{"x"}}},
+				Result:  pgxspecial.StatusResult{Message: "done"},
+			},
+			`{"version":1,"kind":"echo_hidden","queries":[{"sql":"SELECT $1","args":["x"]}],"result":{"kind":"status","message":"done"}}`,
+		},
+		{
+			pgxspecial.ScriptResults{Results: []pgxspecial.ScriptResult{
+				{Statement: pgxspecial.Statement{Text: "SELECT 1", Line: 1}, Result: pgxspecial.QueryResult{CommandTag: "SELECT 1"}},
+				{File: "a.sql", Statement: pgxspecial.Statement{Text: "oops", Line: 2}, Err: errors.New("syntax error")},
+			}},
+			`{"version":1,"kind":"script","results":[` +
+				`{"statement":{"text":"SELECT 1","line":1},"result":{"kind":"query","command_tag":"SELECT 1"}},` +
+				`{"file":"a.sql","statement":{"text":"oops","line":2},"error":"syntax error"}]}`,
+		},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want+"\n", writeJSON(t, tt.res), tt.res.ResultKind().String())
+	}
+
+	var sb strings.Builder
+	assert.Error(t, format.WriteJSON(&sb, unknownResult{}))
+}
+
+func TestNDJSONWriter(t *testing.T) {
+	var sb strings.Builder
+	w := format.NewNDJSONWriter(&sb)
+
+	rows := &fakeRows{
+		columns: []string{"n"},
+		oids:    []uint32{pgtype.Int4OID},
+		values:  [][]*string{{text("1")}, {text("2")}},
+	}
+	require.NoError(t, w.Write(pgxspecial.RowResult{Rows: rows}))
+	require.NoError(t, w.Write(pgxspecial.ScriptResults{Results: []pgxspecial.ScriptResult{{
+		Statement: pgxspecial.Statement{Text: "SELECT 'x' AS s", Line: 3},
+		Result: pgxspecial.QueryResult{
+			Columns:    []string{"s"},
+			Rows:       [][]any{{"x"}},
+			CommandTag: "SELECT 1",
+		},
+	}}}))
+	require.NoError(t, w.Write(pgxspecial.StatusResult{Message: "done"}))
+
+	assert.Equal(t, lines(
+		`{"version":1,"kind":"rows","columns":[{"name":"n","type":"int4","type_oid":23}]}`,
+		`{"kind":"row","row":{"n":1}}`,
+		`{"kind":"row","row":{"n":2}}`,
+		`{"kind":"end","row_count":2}`,
+		`{"version":1,"kind":"statement","statement":{"text":"SELECT 'x' AS s","line":3}}`,
+		`{"version":1,"kind":"query","columns":[{"name":"s"}]}`,
+		`{"kind":"row","row":{"s":"x"}}`,
+		`{"kind":"end","row_count":1,"command_tag":"SELECT 1"}`,
+		`{"version":1,"kind":"status","message":"done"}`,
+	), sb.String())
+	assert.True(t, rows.closed)
+}
@@ -0,0 +1,97 @@
+package format
+
+import "github.com/balaji01-4d/pgxspecial"
+
+// rule holds the characters of a horizontal rule: the line itself, and
+// where it meets the left border, a column divider and the right border.
+// The data rule holds the vertical lines of the rows.
+type rule struct {
+	hrule, left, mid, right string
+}
+
+// lineStyle holds the characters a table is drawn with, as psql's
+// printTextFormat does.
+type lineStyle struct {
+	top, middle, bottom, data rule
+
+	midvruleNL    string // divider before a cell continuing on a new line
+	midvruleBlank string // divider before a cell with no more lines
+	headerNLLeft  string // before a header continuing on a new line
+	headerNLRight string // after a header continuing on the next line
+	nlLeft        string // before a cell continuing on a new line
+	nlRight       string // after a cell continuing on the next line
+
+	// wrapRightBorder reserves a column after the last one for the marks
+	// of cells continuing on the next line, even without a border.
+	wrapRightBorder bool
+	oldASCII        bool
+}
+
+var asciiStyle = lineStyle{
+	top:             rule{"-", "+", "+", "+"},
+	middle:          rule{"-", "+", "+", "+"},
+	bottom:          rule{"-", "+", "+", "+"},
+	data:            rule{"", "|", "|", "|"},
+	midvruleNL:      "|",
+	midvruleBlank:   "|",
+	headerNLLeft:    "+",
+	headerNLRight:   "+",
+	nlLeft:          " ",
+	nlRight:         "+",
+	wrapRightBorder: true,
+}
+
+var oldASCIIStyle = lineStyle{
+	top:           rule{"-", "+", "+", "+"},
+	middle:        rule{"-", "+", "+", "+"},
+	bottom:        rule{"-", "+", "+", "+"},
+	data:          rule{"", "|", "|", "|"},
+	midvruleNL:    ":",
+	midvruleBlank: " ",
+	headerNLLeft:  "+",
+	headerNLRight: " ",
+	nlLeft:        " ",
+	nlRight:       " ",
+	oldASCII:      true,
+}
+
+// unicodeStyle returns the box-drawing style psql's unicode line style
+// uses with single column and header lines, and a single or double border.
+func unicodeStyle(double bool) lineStyle {
+	s := lineStyle{
+		top:             rule{"─", "┌", "┬", "┐"},
+		middle:          rule{"─", "├", "┼", "┤"},
+		bottom:          rule{"─", "└", "┴", "┘"},
+		data:            rule{"", "│", "│", "│"},
+		midvruleNL:      "│",
+		midvruleBlank:   "│",
+		headerNLLeft:    " ",
+		headerNLRight:   "↵",
+		nlLeft:          " ",
+		nlRight:         "↵",
+		wrapRightBorder: true,
+	}
+	if double {
+		s.top = rule{"═", "╔", "╤", "╗"}
+		s.middle = rule{"─", "╟", "┼", "╢"}
+		s.bottom = rule{"═", "╚", "╧", "╝"}
+		s.data = rule{"", "║", "│", "║"}
+	}
+	return s
+}
+
+// lineStyle returns the style selected by the linestyle option.
+func (o Options) lineStyle() lineStyle {
+	switch o.LineStyle {
+	case pgxspecial.LineStyleOldASCII:
+		return oldASCIIStyle
+	case pgxspecial.LineStyleUnicode:
+		return unicodeStyle(o.UnicodeBorderLineStyle == pgxspecial.UnicodeDouble)
+	}
+	return asciiStyle
+}
+
+// border returns the border option within the styles psql knows of.
+func (o Options) border() int {
+	return min(max(o.Border, 0), 2)
+}
@@ -0,0 +1,212 @@
+package format_test
+
+import (
+	"testing"
+
+	"github.com/balaji01-4d/pgxspecial"
+	"github.com/stretchr/testify/assert"
+)
+
+// wrapped is a result with a value spanning two lines.
+var wrapped = pgxspecial.QueryResult{
+	Columns: []string{"a", "b"},
+	Rows:    [][]any{{int32(1), "x"}, {int32(22), "yy\nz"}},
+}
+
+func TestPrintBorders(t *testing.T) {
+	o := options()
+
+	o.Border = 0
+	assert.Equal(t, lines(
+		"a  b  ",
+		"-- --",
+		" 1 x",
+		"22 yy+",
+		"   z",
+		"(2 rows)",
+		"",
+	), printWith(t, o, wrapped))
+
+	o.Border = 2
+	assert.Equal(t, lines(
+		"+----+----+",
+		"| a  | b  |",
+		"+----+----+",
+		"|  1 | x  |",
+		"| 22 | yy+|",
+		"|    | z  |",
+		"+----+----+",
+		"(2 rows)",
+		"",
+	), printWith(t, o, wrapped))
+}
+
+func TestPrintLineStyles(t *testing.T) {
+	o := options()
+
+	o.LineStyle = pgxspecial.LineStyleUnicode
+	assert.Equal(t, lines(
+		" a  │ b  ",
+		"────┼────",
+		"  1 │ x",
+		" 22 │ yy↵",
+		"    │ z",
+		"(2 rows)",
+		"",
+	), printWith(t, o, wrapped))
+
+	o.Border = 2
+	o.UnicodeBorderLineStyle = pgxspecial.UnicodeDouble
+	assert.Equal(t, lines(
+		"╔════╤════╗",
+		"║ a  │ b  ║",
+		"╟────┼────╢",
+		"║  1 │ x  ║",
+		"║ 22 │ yy↵║",
+		"║    │ z  ║",
+		"╚════╧════╝",
+		"(2 rows)",
+		"",
+	), printWith(t, o, wrapped))
+
+	o = options()
+	o.LineStyle = pgxspecial.LineStyleOldASCII
+	assert.Equal(t, lines(
+		" a  | b  ",
+		"----+----",
+		"  1 | x",
+		" 22 | yy ",
+		"    : z",
+		"(2 rows)",
+		"",
+	), printWith(t, o, wrapped))
+}
+
+func TestPrintExpandedBorders(t *testing.T) {
+	res := pgxspecial.QueryResult{
+		Columns: []string{"a", "b"},
+		Rows:    [][]any{{int32(1), "x"}, {int32(2), "y"}},
+	}
+	o := options()
+	o.Expanded = pgxspecial.ExpandedOn
+
+	o.Border = 0
+	assert.Equal(t, lines(
+		"* Record 1",
+		"a 1",
+		"b x",
+		"* Record 2",
+		"a 2",
+		"b y",
+		"",
+	), printWith(t, o, res))
+
+	o.Border = 2
+	assert.Equal(t, lines(
+		"+-[ RECORD 1 ]-+",
+		"| a | 1 |",
+		"| b | x |",
+		"+-[ RECORD 2 ]-+",
+		"| a | 2 |",
+		"| b | y |",
+		"+---+---+",
+		"",
+	), printWith(t, o, res))
+
+	o.Border = 1
+	o.TuplesOnly = true
+	assert.Equal(t, lines(
+		"a | 1",
+		"b | x",
+		"--+--",
+		"a | 2",
+		"b | y",
+		"",
+	), printWith(t, o, res))
+}
+
+func TestPrintTuplesOnlyAndFooter(t *testing.T) {
+	o := options()
+	o.TuplesOnly = true
+	assert.Equal(t, lines(
+		"  1 | x",
+		" 22 | yy+",
+		"    | z",
+		"",
+	), printWith(t, o, wrapped))
+
+	o = options()
+	o.Footer = false
+	assert.Equal(t, lines(
+		" a  | b  ",
+		"----+----",
+		"  1 | x",
+		" 22 | yy+",
+		"    | z",
+		"",
+	), printWith(t, o, wrapped))
+
+	// the footers of descriptions are not the default footer
+	res := pgxspecial.DescribeTableListResult{Results: []pgxspecial.DescribeTableResult{{
+		Columns: []string{"Column"},
+		Data:    [][]string{{"id"}},
+		TableMetaData: pgxspecial.TableFooterMeta{
+			Indexes: []string{`"users_pkey" PRIMARY KEY, btree (id)`},
+		},
+	}}}
+	assert.Contains(t, printWith(t, o, res), "Indexes:")
+}
+
+func TestPrintNullAndTitle(t *testing.T) {
+	o := options()
+	o.Null = "(null)"
+	o.Title = "Results"
+
+	res := pgxspecial.QueryResult{
+		Columns: []string{"a"},
+		Rows:    [][]any{{nil}},
+	}
+	assert.Equal(t, lines(
+		"Results",
+		"   a    ",
+		"--------",
+		" (null)",
+		"(1 row)",
+		"",
+	), printWith(t, o, res))
+
+	// the results of commands keep their title and empty NULL cells
+	rows := &fakeRows{
+		columns: []string{"a", "b"},
+		oids:    []uint32{25, 25},
+		values:  [][]*string{{text("x"), nil}},
+	}
+	assert.Equal(t, lines(
+		" List",
+		" a | b ",
+		"---+---",
+		" x | ",
+		"(1 row)",
+		"",
+	), printWith(t, o, pgxspecial.RowResult{Rows: rows, Title: "List"}))
+}
+
+func TestPrintNumericLocale(t *testing.T) {
+	o := options()
+	o.NumericLocale = true
+	res := pgxspecial.QueryResult{
+		Columns: []string{"n", "f", "s"},
+		Rows: [][]any{
+			{int64(1234567), -1234.5, "12345"},
+			{int64(12), 0.25, "x"},
+		},
+	}
+	assert.Equal(t, lines(
+		"     n     |    f     |   s   ",
+		"-----------+----------+-------",
+		" 1,234,567 | -1,234.5 | 12345",
+		"        12 |     0.25 | x",
+		"(2 rows)",
+		"",
+	), printWith(t, o, res))
+}
@@ -0,0 +1,338 @@
+package format
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/balaji01-4d/pgxspecial"
+	"github.com/jackc/pgx/v5"
+)
+
+// Alignments of the cells of a column.
+const (
+	alignLeft  = 'l'
+	alignRight = 'r'
+)
+
+// table is a result laid out as text cells, the form every printing style
+// starts from.
+type table struct {
+	title   string
+	headers []string
+	aligns  []byte // alignLeft or alignRight, per column
+	cells   [][]string
+	nulls   [][]bool // whether each cell is NULL, if known
+	footers []string
+	counted bool // followed by the number of rows when printed as a table
+	query   bool // a query result, printed under the title option
+
+	// sections are the footers of a table description, which the csv
+	// format prints as records
+	sections []footerSection
+}
+
+// rowsTable reads and closes rows, right-aligning the columns of numeric
+// types as psql does. Rows without a title are the result of a query,
+// where NULL is printed as null rather than as an empty cell.
+func rowsTable(rows pgx.Rows, title, null string) (table, error) {
+	defer rows.Close()
+
+	t := table{title: title, query: title == ""}
+	if !t.query {
+		null = ""
+	}
+	fields := rows.FieldDescriptions()
+	for _, fd := range fields {
+		t.headers = append(t.headers, fd.Name)
+		t.aligns = append(t.aligns, alignOf(fd.DataTypeOID))
+	}
+
+	m := typeMap(rows)
+	for rows.Next() {
+		raw := rows.RawValues()
+		var values []any
+		row := make([]string, len(fields))
+		nulls := make([]bool, len(fields))
+		for i, fd := range fields {
+			switch {
+			case raw[i] == nil:
+				row[i] = null
+				nulls[i] = true
+			case fd.Format == pgx.TextFormatCode:
+				row[i] = string(raw[i])
+			default:
+				if values == nil {
+					var err error
+					if values, err = rows.Values(); err != nil {
+						return table{}, err
+					}
+				}
+				row[i] = formatValue(m, fd.DataTypeOID, values[i])
+			}
+		}
+		t.cells = append(t.cells, row)
+		t.nulls = append(t.nulls, nulls)
+	}
+	if err := rows.Err(); err != nil {
+		return table{}, err
+	}
+	t.counted = true
+	return t, nil
+}
+
+// queryTable lays out the rows of a script statement, printing NULL as
+// null. Lacking the column types, it right-aligns the columns holding only
+// numbers.
+func queryTable(r pgxspecial.QueryResult, null string) table {
+	m := typeMap(nil)
+	t := table{headers: r.Columns, query: true}
+	for i := range r.Columns {
+		numeric := false
+		for _, row := range r.Rows {
+			if i < len(row) && row[i] != nil {
+				if numeric = isNumeric(row[i]); !numeric {
+					break
+				}
+			}
+		}
+		t.aligns = append(t.aligns, alignLeft)
+		if numeric {
+			t.aligns[i] = alignRight
+		}
+	}
+	for _, row := range r.Rows {
+		cells := make([]string, len(r.Columns))
+		nulls := make([]bool, len(r.Columns))
+		for i := range cells {
+			switch {
+			case i >= len(row):
+			case row[i] == nil:
+				cells[i] = null
+				nulls[i] = true
+			default:
+				cells[i] = formatValue(m, 0, row[i])
+			}
+		}
+		t.cells = append(t.cells, cells)
+		t.nulls = append(t.nulls, nulls)
+	}
+	t.counted = true
+	return t
+}
+
+// describeTable lays out a table description with its footers.
+func describeTable(d pgxspecial.DescribeTableResult) table {
+	t := table{
+		title:   d.Title,
+		headers: d.Columns,
+		aligns:  make([]byte, len(d.Columns)),
+		cells:   d.Data,
+		footers: describeFooters(d),
+
+		sections: describeSections(d),
+	}
+	for i := range t.aligns {
+		t.aligns[i] = alignLeft
+	}
+	return t
+}
+
+// extensionTable lists the objects of an extension as \dx+ does.
+func extensionTable(e pgxspecial.ExtensionVerboseResult) table {
+	t := table{
+		title:   fmt.Sprintf("Objects in extension %q", e.Name),
+		headers: []string{"Object description"},
+		aligns:  []byte{alignLeft},
+	}
+	for _, desc := range e.Description {
+		t.cells = append(t.cells, []string{desc})
+	}
+	t.counted = true
+	return t
+}
+
+// helpTable lists the commands of a HelpResult, a row per command.
+func helpTable(r pgxspecial.HelpResult) table {
+	t := table{headers: []string{"Group", "Command", "Syntax", "Description"}}
+	for _, g := range r.Groups {
+		for _, c := range g.Commands {
+			t.cells = append(t.cells, []string{g.Name, c.Cmd, c.Syntax, c.Description})
+		}
+	}
+	return leftAligned(t)
+}
+
+// sqlHelpTable lists the topics of a SQLHelpResult, a row per topic.
+func sqlHelpTable(r pgxspecial.SQLHelpResult) table {
+	t := table{headers: []string{"Command", "Description", "Syntax", "URL"}}
+	for _, topic := range r.Topics {
+		t.cells = append(t.cells, []string{topic.Name, topic.Description, strings.TrimRight(topic.Synopsis, "\n"), topic.URL})
+	}
+	return leftAligned(t)
+}
+
+// variablesTable lists the variables of a VariablesResult.
+func variablesTable(r pgxspecial.VariablesResult) table {
+	t := table{headers: []string{"Name", "Value"}}
+	for _, v := range r.Variables {
+		t.cells = append(t.cells, []string{v.Name, v.Value})
+	}
+	return leftAligned(t)
+}
+
+// textTable lists the lines of a TextResult, with their number if they
+// are numbered.
+func textTable(r pgxspecial.TextResult) table {
+	numbered := false
+	for _, l := range r.Lines {
+		numbered = numbered || l.Number > 0
+	}
+	t := table{headers: []string{"Text"}}
+	if numbered {
+		t.headers = []string{"Number", "Text"}
+	}
+	for _, l := range r.Lines {
+		if numbered {
+			t.cells = append(t.cells, []string{strconv.Itoa(l.Number), l.Text})
+		} else {
+			t.cells = append(t.cells, []string{l.Text})
+		}
+	}
+	return leftAligned(t)
+}
+
+func leftAligned(t table) table {
+	t.aligns = make([]byte, len(t.headers))
+	for i := range t.aligns {
+		t.aligns[i] = alignLeft
+	}
+	return t
+}
+
+// rowCount returns the footer psql prints below a query result.
+func rowCount(n int) string {
+	if n == 1 {
+		return "(1 row)"
+	}
+	return fmt.Sprintf("(%d rows)", n)
+}
+
+// footerKind selects how the entries of a footer section are printed.
+type footerKind int
+
+const (
+	footerLine    footerKind = iota // "Label: entry", a line per entry
+	footerBare                      // the entries alone
+	footerHeading                   // "Label:" over the entries, indented
+	footerBlock                     // "Label:" over the lines of the entries
+	footerList                      // "Label: " and the entries, a line each
+)
+
+// footerSection is a section of the footers of a table description, such
+// as its indexes, labelled as psql labels it without the colon.
+type footerSection struct {
+	label   string
+	kind    footerKind
+	entries []string
+}
+
+// describeSections returns the footer sections of a table description in
+// the order psql prints them, leaving out the empty ones.
+func describeSections(d pgxspecial.DescribeTableResult) []footerSection {
+	m := d.TableMetaData
+	var sections []footerSection
+	add := func(label string, kind footerKind, entries ...string) {
+		if len(entries) > 0 {
+			sections = append(sections, footerSection{label, kind, entries})
+		}
+	}
+	opt := func(label string, kind footerKind, value *string) {
+		if value != nil {
+			add(label, kind, *value)
+		}
+	}
+
+	index := d.RelKind == "i" || d.RelKind == "I"
+	if index {
+		opt("Options", footerBare, m.Options)
+	}
+	opt("Owned by", footerLine, m.OwnedBy)
+	add("Partition of", footerLine, m.PartitionOf...)
+	add("Partition constraint", footerLine, m.PartitionConstraints...)
+	opt("Partition key", footerLine, m.PartitionKey)
+	add("Indexes", footerHeading, m.Indexes...)
+	add("Check constraints", footerHeading, m.CheckConstraints...)
+	add("Not-null constraints", footerHeading, m.NotNullConstraints...)
+	add("Foreign-key constraints", footerHeading, m.ForeignKeys...)
+	add("Referenced by", footerHeading, m.ReferencedBy...)
+	if m.ViewDefinition != nil {
+		add("View definition", footerBlock, strings.TrimRight(*m.ViewDefinition, "\n"))
+	}
+	add("Rules", footerHeading, m.RulesEnabled...)
+	add("Disabled rules", footerHeading, m.RulesDisabled...)
+	add("Rules firing always", footerHeading, m.RulesAlways...)
+	add("Rules firing on replica only", footerHeading, m.RulesReplica...)
+	add("Triggers", footerHeading, m.TriggersEnabled...)
+	add("Disabled user triggers", footerHeading, m.TriggersDisabled...)
+	add("Triggers firing always", footerHeading, m.TriggersAlways...)
+	add("Triggers firing on replica only", footerHeading, m.TriggersReplica...)
+	opt("Server", footerLine, m.Server)
+	opt("FDW options", footerLine, m.FDWOptions)
+	add("Inherits", footerList, m.Inherits...)
+	add("Child tables", footerList, m.ChildTables...)
+	opt("Child tables", footerBare, m.ChildTablesSummary)
+	add("Partitions", footerList, m.Partitions...)
+	opt("Partitions", footerBare, m.PartitionsSummary)
+	opt("Typed table of type", footerLine, m.TypedTableOf)
+	if m.HasOIDs != nil && *m.HasOIDs {
+		add("Has OIDs", footerLine, "yes")
+	}
+	opt("Access method", footerLine, m.AccessMethod)
+	if !index {
+		opt("Options", footerLine, m.Options)
+	}
+	return sections
+}
+
+// describeFooters returns the footer lines of a table description as psql
+// prints them.
+func describeFooters(d pgxspecial.DescribeTableResult) []string {
+	var f []string
+	for _, s := range describeSections(d) {
+		switch s.kind {
+		case footerLine:
+			for _, e := range s.entries {
+				f = append(f, s.label+": "+e)
+			}
+		case footerBare:
+			f = append(f, s.entries...)
+		case footerHeading:
+			f = append(f, s.label+":")
+			for _, e := range s.entries {
+				f = append(f, "    "+e)
+			}
+		case footerBlock:
+			f = append(f, s.label+":")
+			for _, e := range s.entries {
+				f = append(f, strings.Split(e, "\n")...)
+			}
+		case footerList:
+			// the entries are separated by commas, and aligned with the
+			// first
+			label := s.label + ": "
+			indent := strings.Repeat(" ", displayWidth(label))
+			for i, e := range s.entries {
+				prefix := indent
+				if i == 0 {
+					prefix = label
+				}
+				if i < len(s.entries)-1 {
+					e += ","
+				}
+				f = append(f, prefix+e)
+			}
+		}
+	}
+	return f
+}
@@ -0,0 +1,72 @@
+package format
+
+import "strings"
+
+// writeUnaligned prints t as psql's unaligned format does: the title, the
+// headers and each row on a record of their own, with the fields separated
+// by the fieldsep option and the records by the recordsep option.
+func (o Options) writeUnaligned(sb *strings.Builder, t table) {
+	needSep := false
+	if !o.TuplesOnly {
+		if t.title != "" {
+			sb.WriteString(t.title + o.RecordSep)
+		}
+		sb.WriteString(strings.Join(t.headers, o.FieldSep))
+		needSep = true
+	}
+	for _, row := range t.cells {
+		if needSep {
+			sb.WriteString(o.RecordSep)
+		}
+		sb.WriteString(strings.Join(row, o.FieldSep))
+		needSep = true
+	}
+	if !o.TuplesOnly {
+		for _, f := range o.footers(t) {
+			if needSep {
+				sb.WriteString(o.RecordSep)
+			}
+			sb.WriteString(f)
+			needSep = true
+		}
+	}
+	// the last record ends with a newline, whatever the separator
+	if needSep {
+		sb.WriteString("\n")
+	}
+}
+
+// writeUnalignedExpanded prints t as psql's unaligned format does in
+// expanded mode: a record per column, holding its name and value separated
+// by the fieldsep option, with the rows separated by an empty record.
+func (o Options) writeUnalignedExpanded(sb *strings.Builder, t table) {
+	needSep := false
+	if t.title != "" && !o.TuplesOnly {
+		sb.WriteString(t.title)
+		needSep = true
+	}
+	for _, row := range t.cells {
+		if needSep {
+			sb.WriteString(o.RecordSep + o.RecordSep)
+		}
+		for i, h := range t.headers {
+			if i > 0 {
+				sb.WriteString(o.RecordSep)
+			}
+			sb.WriteString(h + o.FieldSep)
+			if i < len(row) {
+				sb.WriteString(row[i])
+			}
+		}
+		needSep = true
+	}
+	if len(t.footers) > 0 && !o.TuplesOnly {
+		sb.WriteString(o.RecordSep)
+		for _, f := range t.footers {
+			sb.WriteString(o.RecordSep + f)
+		}
+	}
+	if needSep {
+		sb.WriteString("\n")
+	}
+}
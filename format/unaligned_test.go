@@ -0,0 +1,53 @@
+package format_test
+
+import (
+	"testing"
+
+	"github.com/balaji01-4d/pgxspecial"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrintUnaligned(t *testing.T) {
+	res := pgxspecial.QueryResult{
+		Columns: []string{"a", "b"},
+		Rows:    [][]any{{int32(1), "x"}, {int32(2), nil}},
+	}
+	o := options()
+	o.Format = pgxspecial.FormatUnaligned
+	assert.Equal(t, lines("a|b", "1|x", "2|", "(2 rows)"), printWith(t, o, res))
+
+	o.Title = "T"
+	o.FieldSep = ","
+	o.RecordSep = ";"
+	assert.Equal(t, "T;a,b;1,x;2,;(2 rows)\n", printWith(t, o, res))
+
+	o = options()
+	o.Format = pgxspecial.FormatUnaligned
+	o.TuplesOnly = true
+	assert.Equal(t, lines("1|x", "2|"), printWith(t, o, res))
+
+	o.TuplesOnly = false
+	o.Expanded = pgxspecial.ExpandedOn
+	o.Title = "T"
+	assert.Equal(t, lines("T", "", "a|1", "b|x", "", "a|2", "b|"), printWith(t, o, res))
+}
+
+func TestPrintUnalignedDescribe(t *testing.T) {
+	res := pgxspecial.DescribeTableListResult{Results: []pgxspecial.DescribeTableResult{{
+		Title:   `Table "public.users"`,
+		Columns: []string{"Column", "Type"},
+		Data:    [][]string{{"id", "integer"}},
+		TableMetaData: pgxspecial.TableFooterMeta{
+			Indexes: []string{`"users_pkey" PRIMARY KEY, btree (id)`},
+		},
+	}}}
+	o := options()
+	o.Format = pgxspecial.FormatUnaligned
+	assert.Equal(t, lines(
+		`Table "public.users"`,
+		"Column|Type",
+		"id|integer",
+		"Indexes:",
+		`    "users_pkey" PRIMARY KEY, btree (id)`,
+	), printWith(t, o, res))
+}
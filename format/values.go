@@ -0,0 +1,119 @@
+package format
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// numericOIDs are the types whose columns psql right-aligns.
+var numericOIDs = map[uint32]bool{
+	pgtype.Int8OID:    true,
+	pgtype.Int2OID:    true,
+	pgtype.Int4OID:    true,
+	pgtype.OIDOID:     true,
+	pgtype.XIDOID:     true,
+	pgtype.CIDOID:     true,
+	pgtype.Float4OID:  true,
+	pgtype.Float8OID:  true,
+	790:               true, // money
+	pgtype.NumericOID: true,
+	pgtype.XID8OID:    true,
+}
+
+func alignOf(oid uint32) byte {
+	if numericOIDs[oid] {
+		return alignRight
+	}
+	return alignLeft
+}
+
+// isNumeric reports whether v holds a number, for results whose column
+// types are unknown.
+func isNumeric(v any) bool {
+	switch v.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64,
+		float32, float64, pgtype.Numeric:
+		return true
+	}
+	return false
+}
+
+// typeMap returns the type map of the connection rows were read from, or
+// the default one.
+func typeMap(rows pgx.Rows) *pgtype.Map {
+	if rows != nil {
+		if conn := rows.Conn(); conn != nil {
+			return conn.TypeMap()
+		}
+	}
+	return pgtype.NewMap()
+}
+
+// formatValue returns the text form PostgreSQL gives v, a value of the type
+// oid, or of the type its Go type maps to if oid is 0. NULL is empty.
+func formatValue(m *pgtype.Map, oid uint32, v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	}
+	if oid == 0 {
+		if t, ok := m.TypeForValue(v); ok {
+			oid = t.OID
+		}
+	}
+	if oid != 0 {
+		if buf, err := m.Encode(oid, pgtype.TextFormatCode, v, nil); err == nil && buf != nil {
+			return string(buf)
+		}
+	}
+	return fmt.Sprint(v)
+}
+
+// localize groups the digits of the numbers in the right-aligned columns
+// of t, as the numericlocale option does, returning a copy of t.
+func localize(t table) table {
+	cells := make([][]string, len(t.cells))
+	for r, row := range t.cells {
+		cells[r] = make([]string, len(row))
+		for i, cell := range row {
+			if i < len(t.aligns) && t.aligns[i] == alignRight {
+				cell = groupDigits(cell)
+			}
+			cells[r][i] = cell
+		}
+	}
+	t.cells = cells
+	return t
+}
+
+// groupDigits separates the thousands of the integer part of the number s
+// with commas. Text other than a number is returned unchanged.
+func groupDigits(s string) string {
+	if s == "" || strings.Trim(s, "0123456789+-.eE") != "" {
+		return s
+	}
+	start := 0
+	if s[0] == '+' || s[0] == '-' {
+		start = 1
+	}
+	end := start
+	for end < len(s) && s[end] >= '0' && s[end] <= '9' {
+		end++
+	}
+
+	var sb strings.Builder
+	sb.WriteString(s[:start])
+	for i := start; i < end; i++ {
+		if i > start && (end-i)%3 == 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteByte(s[i])
+	}
+	sb.WriteString(s[end:])
+	return sb.String()
+}
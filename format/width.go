@@ -0,0 +1,36 @@
+package format
+
+import "unicode"
+
+// displayWidth returns the number of terminal columns s occupies: combining
+// and format characters take none and East Asian wide characters two.
+func displayWidth(s string) int {
+	w := 0
+	for _, r := range s {
+		switch {
+		case unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf):
+		case isWide(r):
+			w += 2
+		default:
+			w++
+		}
+	}
+	return w
+}
+
+// isWide reports whether r is an East Asian wide or fullwidth character.
+func isWide(r rune) bool {
+	return r >= 0x1100 && (r <= 0x115f || // Hangul Jamo
+		r == 0x2329 || r == 0x232a ||
+		(r >= 0x2e80 && r <= 0xa4cf && r != 0x303f) || // CJK ... Yi
+		(r >= 0xac00 && r <= 0xd7a3) || // Hangul syllables
+		(r >= 0xf900 && r <= 0xfaff) || // CJK compatibility ideographs
+		(r >= 0xfe10 && r <= 0xfe19) || // vertical forms
+		(r >= 0xfe30 && r <= 0xfe6f) || // CJK compatibility forms
+		(r >= 0xff00 && r <= 0xff60) || // fullwidth forms
+		(r >= 0xffe0 && r <= 0xffe6) ||
+		(r >= 0x1f300 && r <= 0x1f64f) || // pictographs and emoticons
+		(r >= 0x1f900 && r <= 0x1f9ff) ||
+		(r >= 0x20000 && r <= 0x2fffd) ||
+		(r >= 0x30000 && r <= 0x3fffd))
+}
@@ -0,0 +1,101 @@
+package pgxspecial
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"github.com/balaji01-4d/pgxspecial/database"
+	"github.com/jackc/pgx/v5"
+)
+
+// Middleware wraps the handler of every command executed by a Registry, for
+// behavior such as logging, auditing or timing. The command being executed
+// is available to the wrapped handler through CommandFromContext. See
+// Registry.Use.
+type Middleware func(next SpecialHandler) SpecialHandler
+
+type commandContextKey struct{}
+
+// CommandFromContext returns the command whose handler is being executed,
+// and false outside of Registry.Execute.
+func CommandFromContext(ctx context.Context) (SpecialCommand, bool) {
+	cmd, ok := ctx.Value(commandContextKey{}).(SpecialCommand)
+	return cmd, ok
+}
+
+// chain wraps handler in middleware, the first of which runs first.
+func chain(handler SpecialHandler, middleware []Middleware) SpecialHandler {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler = middleware[i](handler)
+	}
+	return handler
+}
+
+// ErrHandlerPanic is returned (wrapped in a PanicError) by the Recover
+// middleware when a handler panics.
+var ErrHandlerPanic = errors.New("special command panicked")
+
+// PanicError reports the command whose handler panicked, the value it
+// panicked with and the stack trace of the panic.
+type PanicError struct {
+	Command string
+	Value   any
+	Stack   []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("%s: %s: %v", ErrHandlerPanic, e.Command, e.Value)
+}
+
+func (e *PanicError) Unwrap() error {
+	return ErrHandlerPanic
+}
+
+// Recover returns a middleware that turns a panic in a handler into a
+// *PanicError, so that one faulty command cannot bring down a server.
+func Recover() Middleware {
+	return func(next SpecialHandler) SpecialHandler {
+		return func(ctx context.Context, db database.Queryer, args string, opts CommandOptions) (res SpecialCommandResult, err error) {
+			defer func() {
+				if v := recover(); v != nil {
+					cmd, _ := CommandFromContext(ctx)
+					res, err = nil, &PanicError{Command: cmd.Cmd, Value: v, Stack: debug.Stack()}
+				}
+			}()
+			return next(ctx, db, args, opts)
+		}
+	}
+}
+
+// Timeout returns a middleware that cancels the context of a handler after
+// d. The rows of a RowResult are read under the same deadline, which ends
+// when they are closed.
+func Timeout(d time.Duration) Middleware {
+	return func(next SpecialHandler) SpecialHandler {
+		return func(ctx context.Context, db database.Queryer, args string, opts CommandOptions) (SpecialCommandResult, error) {
+			ctx, cancel := context.WithTimeout(ctx, d)
+			res, err := next(ctx, db, args, opts)
+			if rows, ok := res.(RowResult); ok && err == nil {
+				rows.Rows = &cancelRows{Rows: rows.Rows, cancel: cancel}
+				return rows, nil
+			}
+			cancel()
+			return res, err
+		}
+	}
+}
+
+// cancelRows cancels the context the rows were queried with when they are
+// closed.
+type cancelRows struct {
+	pgx.Rows
+	cancel context.CancelFunc
+}
+
+func (r *cancelRows) Close() {
+	r.Rows.Close()
+	r.cancel()
+}
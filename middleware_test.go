@@ -0,0 +1,131 @@
+package pgxspecial_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/balaji01-4d/pgxspecial"
+	"github.com/balaji01-4d/pgxspecial/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tracing returns a middleware appending name and the command it wraps to
+// trace before and after the handler runs.
+func tracing(name string, trace *[]string) pgxspecial.Middleware {
+	return func(next pgxspecial.SpecialHandler) pgxspecial.SpecialHandler {
+		return func(ctx context.Context, db database.Queryer, args string, opts pgxspecial.CommandOptions) (pgxspecial.SpecialCommandResult, error) {
+			cmd, _ := pgxspecial.CommandFromContext(ctx)
+			*trace = append(*trace, name+" "+cmd.Cmd+" "+args)
+			res, err := next(ctx, db, args, opts)
+			*trace = append(*trace, name+" done")
+			return res, err
+		}
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	var trace []string
+	reg := pgxspecial.NewRegistry()
+	require.NoError(t, reg.Register(pgxspecial.SpecialCommandRegistry{
+		Cmd: "\\echo",
+		Handler: func(ctx context.Context, db database.Queryer, args string, opts pgxspecial.CommandOptions) (pgxspecial.SpecialCommandResult, error) {
+			trace = append(trace, "handler")
+			return nil, nil
+		},
+		CaseSensitive: true,
+		Categories:    []string{pgxspecial.CategoryShell},
+	}))
+	reg.Use(tracing("outer", &trace))
+	reg.Use(tracing("inner", &trace))
+
+	session := pgxspecial.NewSession()
+	require.NoError(t, session.Set("x", "hi"))
+	ctx := pgxspecial.WithSession(context.Background(), session)
+
+	_, _, err := reg.Execute(ctx, nil, "\\echo :x")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"outer \\echo hi", "inner \\echo hi", "handler", "inner done", "outer done"}, trace)
+
+	// commands refused by a policy pass through the chain, for auditing
+	trace = nil
+	_, _, err = reg.Execute(pgxspecial.WithPolicy(ctx, pgxspecial.SafePolicy()), nil, "\\echo")
+	assert.ErrorIs(t, err, pgxspecial.ErrPermissionDenied)
+	assert.Equal(t, []string{"outer \\echo ", "inner \\echo ", "inner done", "outer done"}, trace)
+
+	// so do the commands of scripts
+	trace = nil
+	_, err = reg.ExecuteScript(ctx, nil, strings.NewReader("\\echo a\n\\echo b\n"))
+	require.NoError(t, err)
+	assert.Len(t, trace, 10)
+
+	// clones keep the chain, but not later additions
+	clone := reg.Clone()
+	reg.Use(tracing("late", &trace))
+	trace = nil
+	_, _, err = clone.Execute(ctx, nil, "\\echo")
+	require.NoError(t, err)
+	assert.Len(t, trace, 5)
+
+	_, ok := pgxspecial.CommandFromContext(context.Background())
+	assert.False(t, ok)
+}
+
+func TestRecoverMiddleware(t *testing.T) {
+	reg := pgxspecial.NewRegistry()
+	require.NoError(t, reg.Register(pgxspecial.SpecialCommandRegistry{
+		Cmd: "\\boom",
+		Handler: func(ctx context.Context, db database.Queryer, args string, opts pgxspecial.CommandOptions) (pgxspecial.SpecialCommandResult, error) {
+			var parts []string
+			return pgxspecial.TextResult{Lines: []pgxspecial.TextLine{{Text: parts[0]}}}, nil
+		},
+		CaseSensitive: true,
+	}))
+	reg.Use(pgxspecial.Recover())
+
+	res, ok, err := reg.Execute(context.Background(), nil, "\\boom")
+	assert.True(t, ok)
+	assert.Nil(t, res)
+	assert.ErrorIs(t, err, pgxspecial.ErrHandlerPanic)
+	var panicErr *pgxspecial.PanicError
+	require.True(t, errors.As(err, &panicErr))
+	assert.Equal(t, "\\boom", panicErr.Command)
+	assert.Contains(t, err.Error(), "index out of range")
+	assert.NotEmpty(t, panicErr.Stack)
+}
+
+func TestTimeoutMiddleware(t *testing.T) {
+	reg := pgxspecial.NewRegistry()
+	var handlerCtx context.Context
+	require.NoError(t, reg.Register(pgxspecial.SpecialCommandRegistry{
+		Cmd: "\\sleep",
+		Handler: func(ctx context.Context, db database.Queryer, args string, opts pgxspecial.CommandOptions) (pgxspecial.SpecialCommandResult, error) {
+			handlerCtx = ctx
+			if args == "rows" {
+				rows, err := db.Query(ctx, "select 1")
+				return pgxspecial.RowResult{Rows: rows}, err
+			}
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+		CaseSensitive: true,
+	}))
+	reg.Use(pgxspecial.Timeout(10 * time.Millisecond))
+
+	_, _, err := reg.Execute(context.Background(), nil, "\\sleep")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// the deadline of a row result ends when its rows are closed
+	res, _, err := reg.Execute(context.Background(), &fakeQueryer{}, "\\sleep rows")
+	require.NoError(t, err)
+	_, hasDeadline := handlerCtx.Deadline()
+	assert.True(t, hasDeadline)
+	rows := res.(pgxspecial.RowResult).Rows
+	assert.True(t, rows.Next())
+	assert.NoError(t, handlerCtx.Err(), "the rows are still being read")
+	rows.Close()
+	assert.ErrorIs(t, handlerCtx.Err(), context.Canceled)
+}
@@ -0,0 +1,45 @@
+package pgxspecial
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// OpenOutput opens the destination of a command's output given in psql
+// syntax, such as the argument of \g: a file name, or "|command" to pipe
+// the output to a shell command. Closing the returned writer waits for the
+// command to exit.
+func OpenOutput(target string) (io.WriteCloser, error) {
+	command, ok := strings.CutPrefix(target, "|")
+	if !ok {
+		return os.Create(target)
+	}
+
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	return &pipeWriter{WriteCloser: stdin, cmd: cmd}, nil
+}
+
+// pipeWriter writes to the standard input of a running command.
+type pipeWriter struct {
+	io.WriteCloser
+	cmd *exec.Cmd
+}
+
+func (p *pipeWriter) Close() error {
+	err := p.WriteCloser.Close()
+	if werr := p.cmd.Wait(); err == nil {
+		err = werr
+	}
+	return err
+}
@@ -0,0 +1,42 @@
+package pgxspecial_test
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/balaji01-4d/pgxspecial"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenOutput(t *testing.T) {
+	dir := t.TempDir()
+
+	file := filepath.Join(dir, "out.txt")
+	w, err := pgxspecial.OpenOutput(file)
+	require.NoError(t, err)
+	_, err = io.WriteString(w, "to a file\n")
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	data, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, "to a file\n", string(data))
+
+	piped := filepath.Join(dir, "piped.txt")
+	w, err = pgxspecial.OpenOutput("|tr a-z A-Z > " + piped)
+	require.NoError(t, err)
+	_, err = io.WriteString(w, "to a pipe\n")
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	data, err = os.ReadFile(piped)
+	require.NoError(t, err)
+	assert.Equal(t, "TO A PIPE\n", string(data))
+
+	w, err = pgxspecial.OpenOutput("|exit 3")
+	require.NoError(t, err)
+	assert.Error(t, w.Close())
+}
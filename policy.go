@@ -0,0 +1,119 @@
+package pgxspecial
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// Command categories a Policy can deny as a whole. A command belongs to the
+// categories listed in its registration, see SpecialCommandRegistry, and a
+// handler may check further categories depending on its arguments, such as
+// CategoryShell for "\w |command".
+const (
+	CategoryShell      = "shell"      // runs shell commands or external programs
+	CategoryFilesystem = "filesystem" // reads or writes local files
+	CategoryMutating   = "mutating"   // sends SQL that may modify the database
+)
+
+// ErrPermissionDenied is returned (wrapped in a PermissionError) when a
+// Policy does not allow a command.
+var ErrPermissionDenied = errors.New("permission denied")
+
+// PermissionError reports a command refused by a Policy, and the category
+// that caused it, if the command was not refused by name.
+type PermissionError struct {
+	Command  string
+	Category string
+}
+
+func (e *PermissionError) Error() string {
+	if e.Category != "" {
+		return fmt.Sprintf("%s: %s (%s commands are not allowed)", ErrPermissionDenied, e.Command, e.Category)
+	}
+	return fmt.Sprintf("%s: %s", ErrPermissionDenied, e.Command)
+}
+
+func (e *PermissionError) Unwrap() error {
+	return ErrPermissionDenied
+}
+
+// Policy decides which special commands may run. It is evaluated before the
+// handler of a command runs, including commands read from scripts, see
+// WithPolicy.
+//
+// Commands are named as registered, by name or alias, without modifiers. If
+// Allow is not empty, only the commands it lists may run. Deny and
+// DenyCategories refuse commands even if they are allowed. A nil *Policy
+// allows everything.
+type Policy struct {
+	Allow          []string
+	Deny           []string
+	DenyCategories []string
+}
+
+// SafePolicy returns a policy for commands from untrusted users, such as the
+// users of a web console: it denies every command that runs shell commands
+// or programs, or reads or writes local files.
+func SafePolicy() *Policy {
+	return &Policy{DenyCategories: []string{CategoryShell, CategoryFilesystem}}
+}
+
+// Check returns a *PermissionError if the policy does not allow cmd.
+func (p *Policy) Check(cmd SpecialCommand) error {
+	if p == nil {
+		return nil
+	}
+	if (len(p.Allow) > 0 && !matchesCommand(p.Allow, cmd)) || matchesCommand(p.Deny, cmd) {
+		return &PermissionError{Command: cmd.Cmd}
+	}
+	for _, category := range cmd.Categories {
+		if err := p.CheckCategory(cmd.Cmd, category); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckCategory returns a *PermissionError if the policy denies category.
+// Handlers call it for categories that depend on their arguments.
+func (p *Policy) CheckCategory(command, category string) error {
+	if p != nil && slices.Contains(p.DenyCategories, category) {
+		return &PermissionError{Command: command, Category: category}
+	}
+	return nil
+}
+
+// matchesCommand reports whether names contains the name or an alias of
+// cmd.
+func matchesCommand(names []string, cmd SpecialCommand) bool {
+	for _, name := range names {
+		key := commandKey(name, cmd.CaseSensitive)
+		if key == commandKey(cmd.Cmd, cmd.CaseSensitive) {
+			return true
+		}
+		for _, alias := range cmd.Alias {
+			if key == commandKey(alias, cmd.CaseSensitive) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type policyContextKey struct{}
+
+// WithPolicy returns a copy of ctx carrying policy. Commands executed with
+// the returned context, and the commands of scripts they run, are checked
+// against it.
+func WithPolicy(ctx context.Context, policy *Policy) context.Context {
+	return context.WithValue(ctx, policyContextKey{}, policy)
+}
+
+// PolicyFromContext returns the Policy carried by ctx, or nil if there is
+// none.
+func PolicyFromContext(ctx context.Context) *Policy {
+	policy, _ := ctx.Value(policyContextKey{}).(*Policy)
+	return policy
+}
@@ -0,0 +1,82 @@
+package pgxspecial_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/balaji01-4d/pgxspecial"
+	"github.com/balaji01-4d/pgxspecial/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicyCheck(t *testing.T) {
+	shell := pgxspecial.SpecialCommand{Cmd: "\\!", Categories: []string{pgxspecial.CategoryShell}}
+	include := pgxspecial.SpecialCommand{Cmd: "\\i", Alias: []string{"\\include"}, CaseSensitive: true,
+		Categories: []string{pgxspecial.CategoryFilesystem}}
+	list := pgxspecial.SpecialCommand{Cmd: "\\dt"}
+	describe := pgxspecial.SpecialCommand{Cmd: "describe", Alias: []string{"desc"}}
+
+	var nilPolicy *pgxspecial.Policy
+	assert.NoError(t, nilPolicy.Check(shell))
+
+	safe := pgxspecial.SafePolicy()
+	assert.NoError(t, safe.Check(list))
+	err := safe.Check(shell)
+	assert.ErrorIs(t, err, pgxspecial.ErrPermissionDenied)
+	var permErr *pgxspecial.PermissionError
+	require.True(t, errors.As(err, &permErr))
+	assert.Equal(t, pgxspecial.PermissionError{Command: "\\!", Category: pgxspecial.CategoryShell}, *permErr)
+	assert.EqualError(t, safe.Check(include), "permission denied: \\i (filesystem commands are not allowed)")
+
+	// allowed commands are named by name or alias, case-insensitively for
+	// case-insensitive commands
+	allow := &pgxspecial.Policy{Allow: []string{"\\include", "DESC", "\\!"}, DenyCategories: []string{pgxspecial.CategoryShell}}
+	assert.NoError(t, allow.Check(include))
+	assert.NoError(t, allow.Check(describe))
+	assert.EqualError(t, allow.Check(list), "permission denied: \\dt")
+	assert.ErrorIs(t, allow.Check(shell), pgxspecial.ErrPermissionDenied, "a denied category wins over Allow")
+
+	deny := &pgxspecial.Policy{Deny: []string{"\\INCLUDE", "Describe"}}
+	assert.NoError(t, deny.Check(include), "case-sensitive aliases must match exactly")
+	assert.EqualError(t, deny.Check(describe), "permission denied: describe")
+	assert.NoError(t, deny.Check(shell))
+}
+
+func TestExecuteWithPolicy(t *testing.T) {
+	reg := pgxspecial.NewRegistry()
+	var calls []string
+	handler := func(ctx context.Context, db database.Queryer, args string, opts pgxspecial.CommandOptions) (pgxspecial.SpecialCommandResult, error) {
+		calls = append(calls, args)
+		return nil, nil
+	}
+	require.NoError(t, reg.Register(pgxspecial.SpecialCommandRegistry{
+		Cmd: "\\run", Handler: handler, CaseSensitive: true, Categories: []string{pgxspecial.CategoryShell},
+	}))
+	require.NoError(t, reg.Register(pgxspecial.SpecialCommandRegistry{
+		Cmd: "\\echo", Handler: handler, CaseSensitive: true,
+	}))
+
+	ctx := pgxspecial.WithPolicy(context.Background(), pgxspecial.SafePolicy())
+	_, ok, err := reg.Execute(ctx, nil, "\\run+ rm -rf /")
+	assert.True(t, ok)
+	assert.ErrorIs(t, err, pgxspecial.ErrPermissionDenied)
+	assert.Empty(t, calls, "the handler of a denied command must not run")
+
+	_, _, err = reg.Execute(ctx, nil, "\\echo hi")
+	assert.NoError(t, err)
+
+	// commands read from scripts are checked too
+	results, err := reg.ExecuteScript(ctx, nil, strings.NewReader("\\run x\n\\echo y\n"))
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.ErrorIs(t, results[0].Err, pgxspecial.ErrPermissionDenied)
+	assert.NoError(t, results[1].Err)
+	assert.Equal(t, []string{"hi", "y"}, calls)
+
+	_, _, err = reg.Execute(context.Background(), nil, "\\run x")
+	assert.NoError(t, err)
+	assert.Nil(t, pgxspecial.PolicyFromContext(context.Background()))
+}
@@ -0,0 +1,127 @@
+package pgxspecial
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/balaji01-4d/pgxspecial/database"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ErrEmptyQueryBuffer is returned by the commands sending the query buffer,
+// such as \g, when neither the buffer nor the previous query hold any text.
+var ErrEmptyQueryBuffer = errors.New("query buffer is empty")
+
+// QueryBuffer holds the SQL text entered but not yet sent, like psql's query
+// buffer, together with the most recently sent query. Clients append input
+// lines to it and commands such as \g send it; ExecuteScript keeps it in
+// step with the script being executed.
+//
+// A QueryBuffer is safe for concurrent use by multiple goroutines.
+type QueryBuffer struct {
+	mu       sync.Mutex
+	text     string
+	previous string
+}
+
+// String returns the text of the buffer.
+func (b *QueryBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.text
+}
+
+// Set replaces the text of the buffer.
+func (b *QueryBuffer) Set(text string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.text = text
+}
+
+// Append adds a line of input to the buffer.
+func (b *QueryBuffer) Append(line string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.text != "" {
+		b.text += "\n"
+	}
+	b.text += line
+}
+
+// Reset empties the buffer. The previous query is kept.
+func (b *QueryBuffer) Reset() {
+	b.Set("")
+}
+
+// Previous returns the most recently sent query.
+func (b *QueryBuffer) Previous() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.previous
+}
+
+// Take returns the query to send and resets the buffer. As in psql, an
+// empty buffer sends the previous query again. The returned query becomes
+// the previous one; ok is false if there is nothing to send.
+func (b *QueryBuffer) Take() (query string, ok bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	query = b.text
+	if strings.TrimSpace(query) == "" {
+		query = b.previous
+	}
+	b.text = ""
+	b.previous = query
+	return query, strings.TrimSpace(query) != ""
+}
+
+// ExecuteQuery sends sql to queryer and reads the result to completion.
+// The simple protocol is used, as psql does, so sql may hold several
+// statements and does not take parameters. If ctx carries a Session, the
+// outcome is recorded in its variables (see Session.RecordResult).
+func ExecuteQuery(ctx context.Context, queryer database.Queryer, sql string) (QueryResult, error) {
+	rows, err := UnwrapQueryer(queryer).Query(ctx, sql, pgx.QueryExecModeSimpleProtocol)
+	res, err := collectRows(rows, err)
+
+	if session := SessionFromContext(ctx); session != nil {
+		var rowCount int64
+		if err == nil {
+			rowCount = pgconn.NewCommandTag(res.CommandTag).RowsAffected()
+		}
+		session.RecordResult(rowCount, err)
+	}
+	return res, err
+}
+
+// collectRows reads rows to completion into a QueryResult. err is the error
+// returned along with rows, if any.
+func collectRows(rows pgx.Rows, err error) (QueryResult, error) {
+	if err != nil {
+		if rows != nil {
+			rows.Close()
+		}
+		return QueryResult{}, err
+	}
+	defer rows.Close()
+
+	var res QueryResult
+	for _, fd := range rows.FieldDescriptions() {
+		res.Columns = append(res.Columns, fd.Name)
+	}
+	for rows.Next() {
+		values, err := rows.Values()
+		if err != nil {
+			return QueryResult{}, err
+		}
+		res.Rows = append(res.Rows, values)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return QueryResult{}, err
+	}
+	res.CommandTag = rows.CommandTag().String()
+	return res, nil
+}
@@ -0,0 +1,54 @@
+package pgxspecial_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/balaji01-4d/pgxspecial"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryBuffer(t *testing.T) {
+	var buf pgxspecial.QueryBuffer
+
+	_, ok := buf.Take()
+	assert.False(t, ok)
+
+	buf.Append("select *")
+	buf.Append("from users")
+	assert.Equal(t, "select *\nfrom users", buf.String())
+
+	query, ok := buf.Take()
+	assert.True(t, ok)
+	assert.Equal(t, "select *\nfrom users", query)
+	assert.Empty(t, buf.String())
+	assert.Equal(t, query, buf.Previous())
+
+	// an empty buffer takes the previous query
+	query, ok = buf.Take()
+	assert.True(t, ok)
+	assert.Equal(t, "select *\nfrom users", query)
+
+	buf.Set("select 2")
+	buf.Reset()
+	assert.Empty(t, buf.String())
+	assert.Equal(t, "select *\nfrom users", buf.Previous())
+}
+
+func TestExecuteQuery(t *testing.T) {
+	db := &fakeQueryer{}
+	session := pgxspecial.NewSession()
+	ctx := pgxspecial.WithSession(context.Background(), session)
+
+	res, err := pgxspecial.ExecuteQuery(ctx, db, "delete from t")
+	require.NoError(t, err)
+	assert.Equal(t, "DELETE 0 3", res.CommandTag)
+	rowCount, _ := session.Get(pgxspecial.VarRowCount)
+	assert.Equal(t, "3", rowCount)
+
+	_, err = pgxspecial.ExecuteQuery(ctx, db, "fail")
+	assert.Error(t, err)
+	sqlState, _ := session.Get(pgxspecial.VarSQLState)
+	assert.Equal(t, "42601", sqlState)
+}
@@ -2,76 +2,325 @@ package pgxspecial
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/balaji01-4d/pgxspecial/database"
 )
 
 // SpecialHandler defines the signature for a special command handler.
 //
-// A SpecialHandler is invoked with the parsed command arguments and an optional
-// verbose flag. The provided db is used to execute queries as needed.
+// A SpecialHandler is invoked with the parsed command arguments and the
+// modifiers given on the command name. The provided db is used to execute
+// queries as needed.
 //
 // The returned pgx.Rows, if non-nil, is passed back to the caller for consumption.
 // Any error returned indicates command execution failure.
-type SpecialHandler func(ctx context.Context, db database.Queryer, args string, verbose bool) (SpecialCommandResult, error)
+type SpecialHandler func(ctx context.Context, db database.Queryer, args string, opts CommandOptions) (SpecialCommandResult, error)
 
-// commandRegistry stores all registered special commands, indexed by command
-// name and aliases. Command lookup is performed against this registry during
-// special command execution.
-var commandRegistry = map[string]SpecialCommand{}
+// CommandOptions holds the psql modifier letters appended to a command name,
+// e.g. \dtS+ sets System and Verbose. Handlers ignore modifiers that do not
+// apply to them.
+type CommandOptions struct {
+	Verbose  bool // "+": show additional detail
+	System   bool // "S": include system objects
+	Expanded bool // "x": display the result in expanded mode
+}
 
-// RegisterCommand registers a special command and its aliases in the command registry.
-//
-// The command name and aliases are normalized based on the CaseSensitive flag.
-// If CaseSensitive is false, command keys are stored in lowercase, making lookup
-// case-insensitive.
+// commandModifiers are the characters accepted after a command name.
+const commandModifiers = "S+x"
+
+// ErrDuplicateCommand is returned (wrapped in a DuplicateCommandError) when a
+// command name or alias is registered twice without setting Override.
+var ErrDuplicateCommand = errors.New("duplicate special command")
+
+// DuplicateCommandError reports the command name or alias that is already
+// present in a Registry.
+type DuplicateCommandError struct {
+	Name string
+}
+
+func (e *DuplicateCommandError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrDuplicateCommand, e.Name)
+}
+
+func (e *DuplicateCommandError) Unwrap() error {
+	return ErrDuplicateCommand
+}
+
+// Registry stores special commands indexed by command name and aliases.
 //
-// If multiple aliases are provided, each alias is registered to reference the
-// same command definition.
+// A Registry is safe for concurrent use by multiple goroutines. Handlers are
+// invoked without holding the registry lock, so a handler may itself register
+// or look up commands.
 //
-// RegisterCommand does not perform validation for duplicate command names or
-// aliases; later registrations will overwrite existing entries with the same key.
-func RegisterCommand(cmdRegistry SpecialCommandRegistry) {
+// The zero value is not usable; create registries with NewRegistry or Clone.
+type Registry struct {
+	mu         sync.RWMutex
+	commands   map[string]SpecialCommand
+	middleware []Middleware
+}
 
-	normalize := func(s string) string {
-		if cmdRegistry.CaseSensitive {
-			return s
-		}
-		return strings.ToLower(s)
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{commands: map[string]SpecialCommand{}}
+}
+
+// defaultRegistry is the registry used by the package-level functions. The
+// commands in the dbcommands package register themselves here from init.
+var defaultRegistry = NewRegistry()
+
+// DefaultRegistry returns the process-wide registry used by RegisterCommand
+// and ExecuteSpecialCommand. Use Clone to derive an independent registry from
+// it.
+func DefaultRegistry() *Registry {
+	return defaultRegistry
+}
+
+func commandKey(name string, caseSensitive bool) string {
+	if caseSensitive {
+		return name
 	}
+	return strings.ToLower(name)
+}
 
+// Register adds a special command and its aliases to the registry.
+//
+// The command name and aliases are normalized based on the CaseSensitive flag.
+// If CaseSensitive is false, command keys are stored in lowercase, making lookup
+// case-insensitive.
+//
+// If the command name or any alias is already registered, Register returns a
+// *DuplicateCommandError and leaves the registry unchanged, unless Override is
+// set, in which case the commands holding them are removed, together with all
+// of their other names and aliases.
+func (r *Registry) Register(cmdRegistry SpecialCommandRegistry) error {
 	cmd := SpecialCommand{
 		Cmd:           cmdRegistry.Cmd,
+		Alias:         cmdRegistry.Alias,
 		Description:   cmdRegistry.Description,
 		Syntax:        cmdRegistry.Syntax,
+		Group:         cmdRegistry.Group,
 		CaseSensitive: cmdRegistry.CaseSensitive,
+		RawArgs:       cmdRegistry.RawArgs,
+		Categories:    cmdRegistry.Categories,
 		Handler:       cmdRegistry.Handler,
 	}
 
-	commandRegistry[normalize(cmdRegistry.Cmd)] = cmd
-
+	keys := make([]string, 0, len(cmdRegistry.Alias)+1)
+	keys = append(keys, commandKey(cmdRegistry.Cmd, cmdRegistry.CaseSensitive))
 	for _, alias := range cmdRegistry.Alias {
-		commandRegistry[normalize(alias)] = cmd
+		keys = append(keys, commandKey(alias, cmdRegistry.CaseSensitive))
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, key := range keys {
+		old, ok := r.commands[key]
+		if !ok {
+			continue
+		}
+		if !cmdRegistry.Override {
+			return &DuplicateCommandError{Name: key}
+		}
+		// a command losing some of its keys loses all of them, so that
+		// none is left behind with an alias list naming keys it no
+		// longer owns
+		r.remove(old)
+	}
+
+	for _, key := range keys {
+		r.commands[key] = cmd
+	}
+	return nil
+}
+
+// Unregister removes the command registered under name, together with all of
+// its aliases. name may be the command name or any of its aliases. It reports
+// whether a command was removed.
+func (r *Registry) Unregister(name string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cmd, ok := r.lookup(name)
+	if !ok {
+		return false
+	}
+	r.remove(cmd)
+	return true
+}
+
+// remove deletes the keys of cmd that still hold it, leaving alone any key
+// another command has since taken over.
+func (r *Registry) remove(cmd SpecialCommand) {
+	primary := commandKey(cmd.Cmd, cmd.CaseSensitive)
+	names := append([]string{cmd.Cmd}, cmd.Alias...)
+	for _, name := range names {
+		key := commandKey(name, cmd.CaseSensitive)
+		if held, ok := r.commands[key]; ok && commandKey(held.Cmd, held.CaseSensitive) == primary {
+			delete(r.commands, key)
+		}
 	}
 }
 
-// ExecuteSpecialCommand parses and executes a special command using the command registry.
-// Syntax: \command[+] [args]
+// Lookup returns the command registered under name or one of its aliases.
+//
+// Case-sensitive commands only match their exact spelling; case-insensitive
+// commands match regardless of case.
+func (r *Registry) Lookup(name string) (SpecialCommand, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.lookup(name)
+}
+
+func (r *Registry) lookup(name string) (SpecialCommand, bool) {
+	if cmd, ok := r.commands[name]; ok && commandKey(name, cmd.CaseSensitive) == name {
+		return cmd, true
+	}
+	if cmd, ok := r.commands[strings.ToLower(name)]; ok && !cmd.CaseSensitive {
+		return cmd, true
+	}
+	return SpecialCommand{}, false
+}
+
+// Clone returns an independent copy of the registry. Commands registered in or
+// removed from the clone do not affect the original, and vice versa.
+func (r *Registry) Clone() *Registry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	clone := NewRegistry()
+	for key, cmd := range r.commands {
+		clone.commands[key] = cmd
+	}
+	clone.middleware = slices.Clone(r.middleware)
+	return clone
+}
+
+// Use appends middleware to the chain wrapped around the handler of every
+// command the registry executes, including the commands of scripts. The
+// middleware added first runs first. The chain also wraps the check of the
+// Policy carried by the context, so refused commands pass through it too.
+func (r *Registry) Use(middleware ...Middleware) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.middleware = append(r.middleware, middleware...)
+}
+
+// groupOrder is the order in which the built-in help groups are listed.
+var groupOrder = []string{
+	GroupGeneral,
+	GroupHelp,
+	GroupQueryBuffer,
+	GroupInputOutput,
+	GroupConditional,
+	GroupInformational,
+	GroupFormatting,
+	GroupConnection,
+	GroupOperatingSystem,
+	GroupVariables,
+}
+
+// ListCommands returns the registered commands grouped by their help group.
+//
+// Every command appears once, with its aliases listed in SpecialCommand.Alias.
+// Commands within a group are sorted by name; empty groups are omitted.
+func (r *Registry) ListCommands() []CommandGroup {
+	r.mu.RLock()
+	byGroup := map[string][]SpecialCommand{}
+	for key, cmd := range r.commands {
+		// aliases share the command value; keep only the primary entry
+		if key != commandKey(cmd.Cmd, cmd.CaseSensitive) {
+			continue
+		}
+		group := cmd.Group
+		if group == "" {
+			group = GroupOther
+		}
+		byGroup[group] = append(byGroup[group], cmd)
+	}
+	r.mu.RUnlock()
+
+	rank := func(group string) int {
+		for i, g := range groupOrder {
+			if g == group {
+				return i
+			}
+		}
+		if group == GroupOther {
+			return len(groupOrder) + 1
+		}
+		return len(groupOrder)
+	}
+
+	groups := make([]CommandGroup, 0, len(byGroup))
+	for name, cmds := range byGroup {
+		sort.Slice(cmds, func(i, j int) bool {
+			a, b := strings.ToLower(cmds[i].Cmd), strings.ToLower(cmds[j].Cmd)
+			if a != b {
+				return a < b
+			}
+			return cmds[i].Cmd < cmds[j].Cmd
+		})
+		groups = append(groups, CommandGroup{Name: name, Commands: cmds})
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		ri, rj := rank(groups[i].Name), rank(groups[j].Name)
+		if ri != rj {
+			return ri < rj
+		}
+		return groups[i].Name < groups[j].Name
+	})
+	return groups
+}
+
+type registryContextKey struct{}
+
+// RegistryFromContext returns the Registry executing the current command. It is
+// intended for handlers that inspect the command set, such as \?. Outside of
+// Registry.Execute it returns the default registry.
+func RegistryFromContext(ctx context.Context) *Registry {
+	if r, ok := ctx.Value(registryContextKey{}).(*Registry); ok {
+		return r
+	}
+	return defaultRegistry
+}
+
+// Execute parses and executes a special command using the registry.
+// Syntax: \command[S][+][x] [args]
 //
 // \command - actual special command
 //
-// \command[+] - actual special command with verbose mode
+// \command[S][+][x] - actual special command with modifiers, in any order:
+// S includes system objects, + enables verbose mode and x requests expanded
+// output. See CommandOptions.
 //
 // A special command is identified by a leading backslash (`\`). If the input does not
-// start with a backslash, ExecuteSpecialCommand returns (nil, false, nil) to indicate
+// start with a backslash, Execute returns (nil, false, nil) to indicate
 // that the input should be treated as a normal query.
 //
-// The first whitespace-delimited token is treated as the command name. If the command
-// name ends with a plus sign (`+`), verbose mode is enabled and the suffix is removed
-// before command lookup. The remaining input is passed to the command handler as
-// arguments.
+// The first whitespace-delimited token is treated as the command name. A
+// registered name always wins over modifiers, so \dx is the extensions
+// command rather than \d with expanded output; otherwise trailing modifier
+// characters are removed until a registered command is found. The remaining
+// input is passed to the command handler as arguments.
+//
+// If ctx carries a Session (see WithSession), variable references in the
+// arguments are interpolated first: :name, :'name' and :"name" outside
+// single, double and back quotes are replaced by the variable's value, its
+// value as a literal or as an identifier, unless the command sets RawArgs.
+//
+// The handler runs inside the middleware chain of the registry, see Use.
+//
+// If ECHO_HIDDEN is on or noexec, for the session carried by ctx or as set
+// by WithEchoHidden, the queries the handler issues are returned with its
+// result in an EchoHiddenResult. In noexec mode they are not executed: the
+// handler stops at its first query and the result holds only that query.
 //
 // The provided Queryer is used by the command handler to execute any required queries.
 // Return values:
@@ -81,29 +330,121 @@ func RegisterCommand(cmdRegistry SpecialCommandRegistry) {
 //
 // An error is returned if the command is not found in the registry or if the command
 // handler returns an error.
-func ExecuteSpecialCommand(ctx context.Context, queryer database.Queryer, specialCommand string) (SpecialCommandResult, bool, error) {
+func (r *Registry) Execute(ctx context.Context, queryer database.Queryer, specialCommand string) (SpecialCommandResult, bool, error) {
 	if !strings.HasPrefix(specialCommand, "\\") {
 		return nil, false, nil
 	}
 
-	checkVerbose := func(cmd string) (string, bool) {
-		suff := "+"
-		return strings.TrimSuffix(cmd, suff), strings.HasSuffix(cmd, suff)
+	res, err := r.execute(ctx, queryer, specialCommand)
+	if err != nil {
+		return nil, true, err
 	}
+	return res, true, nil
+}
 
+// execute runs the special command specialCommand, which starts with a
+// backslash. Unlike Execute it returns the handler's result even if the
+// handler also returns an error.
+func (r *Registry) execute(ctx context.Context, queryer database.Queryer, specialCommand string) (SpecialCommandResult, error) {
 	fields := strings.Fields(specialCommand)
 	cmd := fields[0]
 	args := strings.TrimSpace(strings.TrimPrefix(specialCommand, cmd))
 
-	cmd, verbose := checkVerbose(cmd)
-
-	command, ok := commandRegistry[cmd]
+	command, opts, ok := r.resolve(cmd)
 	if !ok {
-		return nil, true, fmt.Errorf("Unknown Command: %s", cmd)
+		return nil, fmt.Errorf("Unknown Command: %s", strings.TrimRight(cmd, commandModifiers))
 	}
-	res, err := command.Handler(ctx, queryer, args, verbose)
-	if err != nil {
-		return nil, true, err
+	if session := SessionFromContext(ctx); session != nil && !command.RawArgs {
+		args = session.interpolate(args, false)
 	}
-	return res, true, nil
+	ctx = context.WithValue(ctx, registryContextKey{}, r)
+	ctx = context.WithValue(ctx, commandContextKey{}, command)
+
+	handler := func(ctx context.Context, queryer database.Queryer, args string, opts CommandOptions) (SpecialCommandResult, error) {
+		if err := PolicyFromContext(ctx).Check(command); err != nil {
+			return nil, err
+		}
+		return command.Handler(ctx, queryer, args, opts)
+	}
+	r.mu.RLock()
+	middleware := r.middleware
+	r.mu.RUnlock()
+
+	mode := echoHiddenMode(ctx)
+	if mode != EchoHiddenOn && mode != EchoHiddenNoExec {
+		return chain(handler, middleware)(ctx, queryer, args, opts)
+	}
+	echo := &echoQueryer{db: queryer, noExec: mode == EchoHiddenNoExec}
+	res, err := chain(handler, middleware)(ctx, echo, args, opts)
+	if errors.Is(err, errNoExec) {
+		res, err = nil, nil
+	}
+	if err != nil || len(echo.queries) == 0 {
+		return res, err
+	}
+	return EchoHiddenResult{Queries: echo.queries, Result: res}, nil
+}
+
+// resolve finds the command named by the first token of a special command,
+// peeling modifier characters off the end of the token until the remaining
+// name is registered.
+func (r *Registry) resolve(name string) (SpecialCommand, CommandOptions, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for i := len(name); i > 0; i-- {
+		if i < len(name) && !strings.ContainsRune(commandModifiers, rune(name[i])) {
+			break
+		}
+		cmd, ok := r.lookup(name[:i])
+		if !ok {
+			continue
+		}
+		var opts CommandOptions
+		for _, m := range name[i:] {
+			switch m {
+			case 'S':
+				opts.System = true
+			case '+':
+				opts.Verbose = true
+			case 'x':
+				opts.Expanded = true
+			}
+		}
+		return cmd, opts, true
+	}
+	return SpecialCommand{}, CommandOptions{}, false
+}
+
+// RegisterCommand registers a special command and its aliases in the default
+// registry. See Registry.Register for the normalization and duplicate rules.
+func RegisterCommand(cmdRegistry SpecialCommandRegistry) error {
+	return defaultRegistry.Register(cmdRegistry)
+}
+
+// MustRegisterCommand is like RegisterCommand but panics if the command cannot
+// be registered. It is intended for registrations performed from init.
+func MustRegisterCommand(cmdRegistry SpecialCommandRegistry) {
+	if err := RegisterCommand(cmdRegistry); err != nil {
+		panic("pgxspecial: " + err.Error())
+	}
+}
+
+// ListCommands returns the commands of the default registry grouped by their
+// help group. See Registry.ListCommands.
+func ListCommands() []CommandGroup {
+	return defaultRegistry.ListCommands()
+}
+
+// ExecuteSpecialCommand parses and executes a special command using the
+// default registry. See Registry.Execute for the accepted syntax and return
+// values.
+func ExecuteSpecialCommand(ctx context.Context, queryer database.Queryer, specialCommand string) (SpecialCommandResult, bool, error) {
+	return defaultRegistry.Execute(ctx, queryer, specialCommand)
+}
+
+// Use appends middleware to the chain of the default registry. See
+// Registry.Use.
+func Use(middleware ...Middleware) {
+	defaultRegistry.Use(middleware...)
 }
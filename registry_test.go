@@ -2,7 +2,9 @@ package pgxspecial_test
 
 import (
 	"context"
+	"fmt"
 	"os"
+	"sync"
 	"testing"
 
 	"github.com/balaji01-4d/pgxspecial"
@@ -126,7 +128,7 @@ func TestExecuteSpecialCommandWithKnownCommand(t *testing.T) {
 		Cmd:         "\\testcmd",
 		Description: "A test command",
 		Syntax:      "\\testcmd [args]",
-		Handler: func(ctx context.Context, db database.Queryer, args string, verbose bool) (pgxspecial.SpecialCommandResult, error) {
+		Handler: func(ctx context.Context, db database.Queryer, args string, opts pgxspecial.CommandOptions) (pgxspecial.SpecialCommandResult, error) {
 			return nil, nil
 		},
 	})
@@ -202,3 +204,299 @@ func TestRegisterCommandAlias(t *testing.T) {
 
 	isValidListDatabasesResult(t, rows)
 }
+
+func noopHandler(ctx context.Context, db database.Queryer, args string, opts pgxspecial.CommandOptions) (pgxspecial.SpecialCommandResult, error) {
+	return nil, nil
+}
+
+func TestRegistryRegisterDuplicate(t *testing.T) {
+	r := pgxspecial.NewRegistry()
+
+	err := r.Register(pgxspecial.SpecialCommandRegistry{
+		Cmd:           "\\foo",
+		Alias:         []string{"\\f"},
+		Handler:       noopHandler,
+		CaseSensitive: true,
+	})
+	assert.NoError(t, err)
+
+	err = r.Register(pgxspecial.SpecialCommandRegistry{
+		Cmd:           "\\bar",
+		Alias:         []string{"\\f"},
+		Handler:       noopHandler,
+		CaseSensitive: true,
+	})
+	var dupErr *pgxspecial.DuplicateCommandError
+	assert.ErrorIs(t, err, pgxspecial.ErrDuplicateCommand)
+	assert.ErrorAs(t, err, &dupErr)
+	assert.Equal(t, "\\f", dupErr.Name)
+
+	// a failed registration must not leave partial entries behind
+	_, ok := r.Lookup("\\bar")
+	assert.False(t, ok)
+
+	err = r.Register(pgxspecial.SpecialCommandRegistry{
+		Cmd:           "\\bar",
+		Alias:         []string{"\\f"},
+		Handler:       noopHandler,
+		CaseSensitive: true,
+		Override:      true,
+	})
+	assert.NoError(t, err)
+
+	cmd, ok := r.Lookup("\\f")
+	assert.True(t, ok)
+	assert.Equal(t, "\\bar", cmd.Cmd)
+}
+
+func TestRegistryLookupCaseSensitivity(t *testing.T) {
+	r := pgxspecial.NewRegistry()
+	assert.NoError(t, r.Register(pgxspecial.SpecialCommandRegistry{
+		Cmd:           "\\dT",
+		Handler:       noopHandler,
+		CaseSensitive: true,
+	}))
+	assert.NoError(t, r.Register(pgxspecial.SpecialCommandRegistry{
+		Cmd:     "DESCRIBE",
+		Handler: noopHandler,
+	}))
+
+	_, ok := r.Lookup("\\dT")
+	assert.True(t, ok)
+	_, ok = r.Lookup("\\dt")
+	assert.False(t, ok)
+
+	for _, name := range []string{"DESCRIBE", "describe", "Describe"} {
+		_, ok = r.Lookup(name)
+		assert.True(t, ok, name)
+	}
+}
+
+func TestRegistryUnregister(t *testing.T) {
+	r := pgxspecial.NewRegistry()
+	assert.NoError(t, r.Register(pgxspecial.SpecialCommandRegistry{
+		Cmd:           "\\l",
+		Alias:         []string{"\\list"},
+		Handler:       noopHandler,
+		CaseSensitive: true,
+	}))
+
+	assert.True(t, r.Unregister("\\list"))
+	_, ok := r.Lookup("\\l")
+	assert.False(t, ok)
+	_, ok = r.Lookup("\\list")
+	assert.False(t, ok)
+	assert.False(t, r.Unregister("\\l"))
+}
+
+func TestRegistryOverrideDisplacesCommand(t *testing.T) {
+	r := pgxspecial.NewRegistry()
+	assert.NoError(t, r.Register(pgxspecial.SpecialCommandRegistry{
+		Cmd:           "\\foo",
+		Alias:         []string{"\\f", "\\fo"},
+		Handler:       noopHandler,
+		CaseSensitive: true,
+	}))
+	assert.NoError(t, r.Register(pgxspecial.SpecialCommandRegistry{
+		Cmd:           "\\bar",
+		Alias:         []string{"\\f"},
+		Handler:       noopHandler,
+		CaseSensitive: true,
+		Override:      true,
+	}))
+
+	// the displaced command loses the keys the override did not take too
+	for _, name := range []string{"\\foo", "\\fo"} {
+		_, ok := r.Lookup(name)
+		assert.False(t, ok, name)
+	}
+	assert.False(t, r.Unregister("\\foo"))
+
+	cmd, ok := r.Lookup("\\f")
+	assert.True(t, ok)
+	assert.Equal(t, "\\bar", cmd.Cmd)
+
+	groups := r.ListCommands()
+	assert.Len(t, groups, 1)
+	assert.Len(t, groups[0].Commands, 1)
+	assert.Equal(t, "\\bar", groups[0].Commands[0].Cmd)
+	assert.Equal(t, []string{"\\f"}, groups[0].Commands[0].Alias)
+}
+
+func TestRegistryUnregisterKeepsKeysOfOtherCommands(t *testing.T) {
+	r := pgxspecial.NewRegistry()
+	assert.NoError(t, r.Register(pgxspecial.SpecialCommandRegistry{
+		Cmd:           "\\foo",
+		Alias:         []string{"\\f"},
+		Handler:       noopHandler,
+		CaseSensitive: true,
+	}))
+	// re-registering \foo without its alias frees \f for another command
+	assert.NoError(t, r.Register(pgxspecial.SpecialCommandRegistry{
+		Cmd:           "\\foo",
+		Handler:       noopHandler,
+		CaseSensitive: true,
+		Override:      true,
+	}))
+	assert.NoError(t, r.Register(pgxspecial.SpecialCommandRegistry{
+		Cmd:           "\\bar",
+		Alias:         []string{"\\f"},
+		Handler:       noopHandler,
+		CaseSensitive: true,
+	}))
+
+	assert.True(t, r.Unregister("\\foo"))
+	cmd, ok := r.Lookup("\\f")
+	assert.True(t, ok)
+	assert.Equal(t, "\\bar", cmd.Cmd)
+}
+
+func TestRegistryCloneIsIndependent(t *testing.T) {
+	r := pgxspecial.NewRegistry()
+	assert.NoError(t, r.Register(pgxspecial.SpecialCommandRegistry{
+		Cmd:           "\\a",
+		Handler:       noopHandler,
+		CaseSensitive: true,
+	}))
+
+	clone := r.Clone()
+	assert.NoError(t, clone.Register(pgxspecial.SpecialCommandRegistry{
+		Cmd:           "\\b",
+		Handler:       noopHandler,
+		CaseSensitive: true,
+	}))
+	assert.True(t, clone.Unregister("\\a"))
+
+	_, ok := r.Lookup("\\a")
+	assert.True(t, ok)
+	_, ok = r.Lookup("\\b")
+	assert.False(t, ok)
+}
+
+func TestRegistryExecute(t *testing.T) {
+	r := pgxspecial.NewRegistry()
+
+	var gotArgs string
+	var gotVerbose bool
+	assert.NoError(t, r.Register(pgxspecial.SpecialCommandRegistry{
+		Cmd: "\\echo",
+		Handler: func(ctx context.Context, db database.Queryer, args string, opts pgxspecial.CommandOptions) (pgxspecial.SpecialCommandResult, error) {
+			gotArgs, gotVerbose = args, opts.Verbose
+			return nil, nil
+		},
+		CaseSensitive: true,
+	}))
+
+	_, isSpecial, err := r.Execute(context.Background(), nil, "\\echo+  hello world ")
+	assert.NoError(t, err)
+	assert.True(t, isSpecial)
+	assert.Equal(t, "hello world", gotArgs)
+	assert.True(t, gotVerbose)
+
+	// commands from the default registry are not visible in a new registry
+	_, isSpecial, err = r.Execute(context.Background(), nil, "\\l")
+	assert.True(t, isSpecial)
+	assert.Error(t, err)
+}
+
+func TestRegistryExecuteModifiers(t *testing.T) {
+	r := pgxspecial.NewRegistry()
+
+	var got []string
+	record := func(name string) pgxspecial.SpecialHandler {
+		return func(ctx context.Context, db database.Queryer, args string, opts pgxspecial.CommandOptions) (pgxspecial.SpecialCommandResult, error) {
+			got = append(got, fmt.Sprintf("%s %+v", name, opts))
+			return nil, nil
+		}
+	}
+	for _, name := range []string{"\\d", "\\dt", "\\dx", "\\l"} {
+		assert.NoError(t, r.Register(pgxspecial.SpecialCommandRegistry{
+			Cmd:           name,
+			Handler:       record(name),
+			CaseSensitive: true,
+		}))
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"\\dt", "\\dt {Verbose:false System:false Expanded:false}"},
+		{"\\dtS", "\\dt {Verbose:false System:true Expanded:false}"},
+		{"\\dtS+ users", "\\dt {Verbose:true System:true Expanded:false}"},
+		{"\\dt+S", "\\dt {Verbose:true System:true Expanded:false}"},
+		{"\\dS+", "\\d {Verbose:true System:true Expanded:false}"},
+		{"\\lx", "\\l {Verbose:false System:false Expanded:true}"},
+		{"\\dx", "\\dx {Verbose:false System:false Expanded:false}"},
+		{"\\dxx+", "\\dx {Verbose:true System:false Expanded:true}"},
+	}
+	for _, tt := range tests {
+		got = nil
+		_, isSpecial, err := r.Execute(context.Background(), nil, tt.input)
+		assert.NoError(t, err, tt.input)
+		assert.True(t, isSpecial)
+		assert.Equal(t, []string{tt.expected}, got, tt.input)
+	}
+
+	for _, input := range []string{"\\dtq", "\\dtSq", "\\lS-"} {
+		_, isSpecial, err := r.Execute(context.Background(), nil, input)
+		assert.Error(t, err, input)
+		assert.True(t, isSpecial)
+	}
+}
+
+func TestRegistryConcurrentAccess(t *testing.T) {
+	r := pgxspecial.DefaultRegistry().Clone()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			name := fmt.Sprintf("\\concurrent%d", i)
+			assert.NoError(t, r.Register(pgxspecial.SpecialCommandRegistry{
+				Cmd:           name,
+				Handler:       noopHandler,
+				CaseSensitive: true,
+			}))
+			_, _, err := r.Execute(context.Background(), nil, name)
+			assert.NoError(t, err)
+			r.Lookup("\\l")
+			r.Clone()
+			assert.True(t, r.Unregister(name))
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestRegistryListCommands(t *testing.T) {
+	r := pgxspecial.NewRegistry()
+	register := func(cmd, group string, alias ...string) {
+		assert.NoError(t, r.Register(pgxspecial.SpecialCommandRegistry{
+			Cmd:           cmd,
+			Alias:         alias,
+			Group:         group,
+			Handler:       noopHandler,
+			CaseSensitive: true,
+		}))
+	}
+	register("\\dt", pgxspecial.GroupInformational)
+	register("\\l", pgxspecial.GroupInformational, "\\list")
+	register("\\custom", "")
+	register("\\mine", "Extras")
+	register("\\?", pgxspecial.GroupHelp)
+
+	groups := r.ListCommands()
+
+	var names []string
+	for _, g := range groups {
+		names = append(names, g.Name)
+	}
+	assert.Equal(t, []string{pgxspecial.GroupHelp, pgxspecial.GroupInformational, "Extras", pgxspecial.GroupOther}, names)
+
+	info := groups[1].Commands
+	assert.Len(t, info, 2)
+	assert.Equal(t, "\\dt", info[0].Cmd)
+	assert.Equal(t, "\\l", info[1].Cmd)
+	assert.Equal(t, []string{"\\list"}, info[1].Alias)
+}
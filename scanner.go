@@ -0,0 +1,330 @@
+package pgxspecial
+
+import "strings"
+
+// StatementKind tells SQL statements and meta-commands apart in the output
+// of a Scanner.
+type StatementKind int
+
+const (
+	StatementSQL StatementKind = iota
+	StatementMeta
+)
+
+// Statement is a unit of input produced by a Scanner.
+type Statement struct {
+	Kind StatementKind
+
+	// Text is the SQL statement without its terminating semicolon, or the
+	// meta-command with its arguments, e.g. `\dt+ users`. Surrounding
+	// whitespace and comments preceding a statement are removed.
+	Text string
+
+	// Line is the 1-based line of the input Text starts on.
+	Line int
+
+	// Complete reports whether an SQL statement was terminated by a
+	// semicolon. SQL left over at the end of the input is returned with
+	// Complete unset.
+	Complete bool
+
+	// Query is the SQL collected so far when a meta-command interrupts a
+	// statement, as in `select 1 \gx`. Commands that execute or discard it
+	// call Scanner.ResetQuery; otherwise scanning continues the statement.
+	Query string
+}
+
+// wholeLineCommands take the rest of the line as their argument, so it may
+// contain backslashes and unbalanced quotes.
+var wholeLineCommands = map[string]bool{
+	`\!`:    true,
+	`\copy`: true,
+	`\ef`:   true,
+	`\ev`:   true,
+	`\h`:    true,
+	`\help`: true,
+	`\sf`:   true,
+	`\sv`:   true,
+}
+
+// Scanner splits input mixing SQL and meta-commands, such as a script file or
+// a pasted buffer, into statements the way psql's input scanner does.
+//
+// SQL statements end at a semicolon outside string literals, quoted
+// identifiers, dollar-quoted strings, comments, parentheses and the BEGIN ...
+// END body of CREATE FUNCTION and CREATE PROCEDURE. \; adds a semicolon
+// without ending the statement.
+//
+// A backslash outside those starts a meta-command, which extends to the end
+// of the line, to \\ or to the next backslash outside quotes, so a line may
+// hold several commands (\dt \dv) or follow SQL (select 1 \gx).
+//
+// A Scanner is not safe for concurrent use.
+type Scanner struct {
+	input string
+	pos   int
+	line  int
+	scs   bool
+
+	query      strings.Builder
+	queryLine  int
+	parenDepth int
+	beginDepth int
+	idents     [4]byte
+	identCount int
+
+	stmt Statement
+}
+
+// NewScanner returns a Scanner reading from input. It assumes
+// standard_conforming_strings is on, see SetStandardConformingStrings.
+func NewScanner(input string) *Scanner {
+	return &Scanner{input: input, line: 1, scs: true}
+}
+
+// SetStandardConformingStrings tells the scanner whether backslashes in
+// ordinary string literals are escape characters (off), as they always are
+// in E'...' strings, or taken literally (on, the default since PostgreSQL 9.1).
+func (s *Scanner) SetStandardConformingStrings(on bool) {
+	s.scs = on
+}
+
+// Statement returns the statement found by the last call to Scan.
+func (s *Scanner) Statement() Statement {
+	return s.stmt
+}
+
+// ResetQuery discards the SQL collected for the current statement, as psql
+// does after a command such as \g or \r has consumed the query buffer.
+func (s *Scanner) ResetQuery() {
+	s.query.Reset()
+	s.queryLine = 0
+	s.parenDepth = 0
+	s.beginDepth = 0
+	s.identCount = 0
+}
+
+// replaceQuery discards the SQL collected for the current statement and
+// scans text in its place, as psql does with a query buffer loaded by \e.
+// Line numbers are kept for the input following text.
+func (s *Scanner) replaceQuery(text string) {
+	s.ResetQuery()
+	if text == "" {
+		return
+	}
+	text += "\n"
+	s.input = s.input[:s.pos] + text + s.input[s.pos:]
+	s.line -= strings.Count(text, "\n")
+}
+
+// Scan advances to the next statement, which is then available through
+// Statement. It returns false at the end of the input.
+func (s *Scanner) Scan() bool {
+	for {
+		if s.query.Len() == 0 {
+			s.skipSpaceAndComments()
+		}
+		if s.pos >= len(s.input) {
+			if text := strings.TrimSpace(s.query.String()); text != "" {
+				s.stmt = Statement{Kind: StatementSQL, Text: text, Line: s.queryLine}
+				s.ResetQuery()
+				return true
+			}
+			return false
+		}
+		if s.query.Len() == 0 {
+			s.queryLine = s.line
+		}
+
+		c := s.input[s.pos]
+		switch {
+		case c == '\\' && strings.HasPrefix(s.input[s.pos:], `\;`):
+			s.query.WriteByte(';')
+			s.advance(s.pos + 2)
+
+		case c == '\\' && strings.HasPrefix(s.input[s.pos:], `\\`):
+			s.advance(s.pos + 2)
+
+		case c == '\\':
+			s.stmt = s.scanMeta()
+			if s.query.Len() == 0 || strings.TrimSpace(s.query.String()) == "" {
+				s.ResetQuery()
+			}
+			return true
+
+		case c == ';' && s.parenDepth == 0 && s.beginDepth == 0:
+			text := strings.TrimSpace(s.query.String())
+			line := s.queryLine
+			s.advance(s.pos + 1)
+			s.ResetQuery()
+			if text != "" {
+				s.stmt = Statement{Kind: StatementSQL, Text: text, Line: line, Complete: true}
+				return true
+			}
+
+		case c == '\'':
+			s.keep(skipQuoted(s.input, s.pos, '\'', !s.scs))
+
+		case c == '"':
+			s.keep(skipQuoted(s.input, s.pos, '"', false))
+
+		case c == '-' && strings.HasPrefix(s.input[s.pos:], "--"):
+			s.keep(s.lineEnd())
+
+		case c == '/' && strings.HasPrefix(s.input[s.pos:], "/*"):
+			s.keep(skipBlockComment(s.input, s.pos))
+
+		case c == '$':
+			s.keep(skipDollarQuoted(s.input, s.pos))
+
+		case c == '(':
+			s.parenDepth++
+			s.keep(s.pos + 1)
+
+		case c == ')':
+			s.parenDepth = max(s.parenDepth-1, 0)
+			s.keep(s.pos + 1)
+
+		case isIdentStart(c):
+			s.scanIdentifier()
+
+		default:
+			s.keep(s.pos + 1)
+		}
+	}
+}
+
+// advance moves past input[s.pos:end], keeping track of the line number.
+func (s *Scanner) advance(end int) {
+	s.line += strings.Count(s.input[s.pos:end], "\n")
+	s.pos = end
+}
+
+// keep adds input[s.pos:end] to the current statement.
+func (s *Scanner) keep(end int) {
+	s.query.WriteString(s.input[s.pos:end])
+	s.advance(end)
+}
+
+// lineEnd returns the index of the newline ending the current line, or the
+// length of the input.
+func (s *Scanner) lineEnd() int {
+	if i := strings.IndexByte(s.input[s.pos:], '\n'); i >= 0 {
+		return s.pos + i
+	}
+	return len(s.input)
+}
+
+func (s *Scanner) skipSpaceAndComments() {
+	for s.pos < len(s.input) {
+		switch rest := s.input[s.pos:]; {
+		case strings.IndexByte(" \t\n\r\f", rest[0]) >= 0:
+			s.advance(s.pos + 1)
+		case strings.HasPrefix(rest, "--"):
+			s.advance(s.lineEnd())
+		case strings.HasPrefix(rest, "/*"):
+			s.advance(skipBlockComment(s.input, s.pos))
+		default:
+			return
+		}
+	}
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || c >= 0x80 || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// scanIdentifier adds the identifier or keyword at the current position to
+// the statement. An E or e directly followed by a quote starts an escape
+// string literal instead.
+//
+// Like psql, it tracks BEGIN ... END blocks in the body of CREATE [OR
+// REPLACE] FUNCTION and PROCEDURE statements, so that the semicolons they
+// contain do not end the statement.
+func (s *Scanner) scanIdentifier() {
+	end := s.pos + 1
+	for end < len(s.input) && isIdentChar(s.input[end]) {
+		end++
+	}
+	word := strings.ToLower(s.input[s.pos:end])
+
+	if word == "e" && end < len(s.input) && s.input[end] == '\'' {
+		s.keep(skipQuoted(s.input, end, '\'', true))
+		return
+	}
+	s.keep(end)
+
+	if s.identCount == 0 {
+		s.idents = [4]byte{}
+	}
+	switch word {
+	case "create", "function", "procedure", "or", "replace":
+		if s.identCount < len(s.idents) {
+			s.idents[s.identCount] = word[0]
+		}
+	}
+	s.identCount++
+
+	id := s.idents
+	createsRoutine := id[0] == 'c' && (id[1] == 'f' || id[1] == 'p' ||
+		(id[1] == 'o' && id[2] == 'r' && (id[3] == 'f' || id[3] == 'p')))
+	if !createsRoutine || s.parenDepth != 0 {
+		return
+	}
+	switch word {
+	case "begin":
+		s.beginDepth++
+	case "case":
+		// CASE also ends with END, but only matters inside a BEGIN block
+		if s.beginDepth > 0 {
+			s.beginDepth++
+		}
+	case "end":
+		s.beginDepth = max(s.beginDepth-1, 0)
+	}
+}
+
+// scanMeta reads the meta-command at the current position. Its arguments end
+// at the end of the line, at \\ (which is consumed) or at a backslash
+// starting another command outside single, double or back quotes.
+func (s *Scanner) scanMeta() Statement {
+	start, line := s.pos, s.line
+	lineEnd := s.lineEnd()
+
+	nameEnd := s.pos + 1
+	for nameEnd < lineEnd && strings.IndexByte(" \t\r\f\\", s.input[nameEnd]) < 0 {
+		nameEnd++
+	}
+	name := s.input[s.pos:nameEnd]
+
+	end, next := lineEnd, lineEnd
+	if !wholeLineCommands[strings.TrimRight(name, "+")] {
+	args:
+		for i := nameEnd; i < lineEnd; i++ {
+			switch s.input[i] {
+			case '\'':
+				i = skipQuoted(s.input[:lineEnd], i, '\'', true) - 1
+			case '"', '`':
+				i = skipQuoted(s.input[:lineEnd], i, s.input[i], false) - 1
+			case '\\':
+				end, next = i, i
+				if strings.HasPrefix(s.input[i:], `\\`) {
+					next = i + 2
+				}
+				break args
+			}
+		}
+	}
+	if next == lineEnd && next < len(s.input) {
+		next++
+	}
+
+	stmt := Statement{
+		Kind:  StatementMeta,
+		Text:  strings.TrimSpace(s.input[start:end]),
+		Line:  line,
+		Query: strings.TrimSpace(s.query.String()),
+	}
+	s.advance(next)
+	return stmt
+}
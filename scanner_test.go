@@ -0,0 +1,203 @@
+package pgxspecial_test
+
+import (
+	"testing"
+
+	"github.com/balaji01-4d/pgxspecial"
+	"github.com/stretchr/testify/assert"
+)
+
+func scanAll(input string, scs bool) []pgxspecial.Statement {
+	sc := pgxspecial.NewScanner(input)
+	sc.SetStandardConformingStrings(scs)
+
+	var stmts []pgxspecial.Statement
+	for sc.Scan() {
+		stmts = append(stmts, sc.Statement())
+	}
+	return stmts
+}
+
+func sqlStmt(text string, line int) pgxspecial.Statement {
+	return pgxspecial.Statement{Kind: pgxspecial.StatementSQL, Text: text, Line: line, Complete: true}
+}
+
+func metaStmt(text string, line int, query string) pgxspecial.Statement {
+	return pgxspecial.Statement{Kind: pgxspecial.StatementMeta, Text: text, Line: line, Query: query}
+}
+
+func TestScanner(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []pgxspecial.Statement
+	}{
+		{
+			name:     "empty",
+			input:    "  \n -- nothing here\n /* or here */ ",
+			expected: nil,
+		},
+		{
+			name:  "statements",
+			input: "select 1;\n\nselect\n  2 ;;select 3;",
+			expected: []pgxspecial.Statement{
+				sqlStmt("select 1", 1),
+				sqlStmt("select\n  2", 3),
+				sqlStmt("select 3", 4),
+			},
+		},
+		{
+			name:  "leading comments are dropped",
+			input: "-- first\n/* block */ select 1; -- trailing\n",
+			expected: []pgxspecial.Statement{
+				sqlStmt("select 1", 2),
+			},
+		},
+		{
+			name:  "semicolons inside quotes and comments",
+			input: `select ';', "a;b", 1 -- ;` + "\n" + `/* ; /* ; */ ; */ from t;`,
+			expected: []pgxspecial.Statement{
+				sqlStmt(`select ';', "a;b", 1 -- ;`+"\n"+`/* ; /* ; */ ; */ from t`, 1),
+			},
+		},
+		{
+			name:  "dollar quoting",
+			input: "create function f() returns int as $body$ begin; return 1; end $body$ language plpgsql;\nselect $1, $$;$$;",
+			expected: []pgxspecial.Statement{
+				sqlStmt("create function f() returns int as $body$ begin; return 1; end $body$ language plpgsql", 1),
+				sqlStmt("select $1, $$;$$", 2),
+			},
+		},
+		{
+			name:  "escape strings",
+			input: `select E'\';', 'a\';` + "\nselect 2;",
+			expected: []pgxspecial.Statement{
+				sqlStmt(`select E'\';', 'a\'`, 1),
+				sqlStmt("select 2", 2),
+			},
+		},
+		{
+			name:  "parentheses",
+			input: "create rule r as on insert to t do also (insert into a values (1); insert into b values (2));",
+			expected: []pgxspecial.Statement{
+				sqlStmt("create rule r as on insert to t do also (insert into a values (1); insert into b values (2))", 1),
+			},
+		},
+		{
+			name:  "begin atomic",
+			input: "create or replace procedure p() begin atomic insert into t values (1); select case when true then 1 end; end;\nbegin; commit;",
+			expected: []pgxspecial.Statement{
+				sqlStmt("create or replace procedure p() begin atomic insert into t values (1); select case when true then 1 end; end", 1),
+				sqlStmt("begin", 2),
+				sqlStmt("commit", 2),
+			},
+		},
+		{
+			name:  "escaped semicolon",
+			input: `select 1\; select 2;`,
+			expected: []pgxspecial.Statement{
+				sqlStmt("select 1; select 2", 1),
+			},
+		},
+		{
+			name:  "unterminated statement",
+			input: "select 1;\nselect 'x;\n",
+			expected: []pgxspecial.Statement{
+				sqlStmt("select 1", 1),
+				{Kind: pgxspecial.StatementSQL, Text: "select 'x;", Line: 2},
+			},
+		},
+		{
+			name:  "meta-commands",
+			input: "\\dt+ users\n\\x\nselect 1;\n\\dt \\dv foo\n",
+			expected: []pgxspecial.Statement{
+				metaStmt(`\dt+ users`, 1, ""),
+				metaStmt(`\x`, 2, ""),
+				sqlStmt("select 1", 3),
+				metaStmt(`\dt`, 4, ""),
+				metaStmt(`\dv foo`, 4, ""),
+			},
+		},
+		{
+			name:  "meta-command after SQL",
+			input: "select 1 \\gx\n",
+			expected: []pgxspecial.Statement{
+				metaStmt(`\gx`, 1, "select 1"),
+				{Kind: pgxspecial.StatementSQL, Text: "select 1", Line: 1},
+			},
+		},
+		{
+			name:  "meta-command inside a statement",
+			input: "select 1,\n\\echo hi\n2;",
+			expected: []pgxspecial.Statement{
+				metaStmt(`\echo hi`, 2, "select 1,"),
+				sqlStmt("select 1,\n2", 1),
+			},
+		},
+		{
+			name:  "quoted meta-command arguments",
+			input: `\echo 'a \dt' "b \dv" ` + "`c \\x`" + ` \x` + "\n",
+			expected: []pgxspecial.Statement{
+				metaStmt(`\echo 'a \dt' "b \dv" `+"`c \\x`", 1, ""),
+				metaStmt(`\x`, 1, ""),
+			},
+		},
+		{
+			name:  "separator",
+			input: `\set x 1 \\ select :x;`,
+			expected: []pgxspecial.Statement{
+				metaStmt(`\set x 1`, 1, ""),
+				sqlStmt("select :x", 1),
+			},
+		},
+		{
+			name:  "whole line commands",
+			input: "\\! echo a \\b 'c\n\\sf+ f(int)\n\\h select \\x\n",
+			expected: []pgxspecial.Statement{
+				metaStmt(`\! echo a \b 'c`, 1, ""),
+				metaStmt(`\sf+ f(int)`, 2, ""),
+				metaStmt(`\h select \x`, 3, ""),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, scanAll(tt.input, true))
+		})
+	}
+}
+
+func TestScannerStandardConformingStrings(t *testing.T) {
+	input := `select 'a\'; select 2;` + "\n" + `select 3;`
+
+	assert.Equal(t, []pgxspecial.Statement{
+		sqlStmt(`select 'a\'`, 1),
+		sqlStmt("select 2", 1),
+		sqlStmt("select 3", 2),
+	}, scanAll(input, true))
+
+	// without standard_conforming_strings \' does not end the literal
+	assert.Equal(t, []pgxspecial.Statement{
+		sqlStmt(`select 'a\'; select 2;`+"\n"+`select 3;'`, 1),
+	}, scanAll(input+"';", false))
+}
+
+func TestScannerResetQuery(t *testing.T) {
+	sc := pgxspecial.NewScanner("select 1 \\g\nselect 2 \\r\nselect 3;")
+
+	var stmts []pgxspecial.Statement
+	for sc.Scan() {
+		stmt := sc.Statement()
+		stmts = append(stmts, stmt)
+		if stmt.Kind == pgxspecial.StatementMeta {
+			sc.ResetQuery()
+		}
+	}
+
+	assert.Equal(t, []pgxspecial.Statement{
+		metaStmt(`\g`, 1, "select 1"),
+		metaStmt(`\r`, 2, "select 2"),
+		sqlStmt("select 3", 3),
+	}, stmts)
+}
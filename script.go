@@ -0,0 +1,236 @@
+package pgxspecial
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/balaji01-4d/pgxspecial/database"
+)
+
+// ErrIncludeCycle is returned (wrapped) by ExecuteFile when a script includes
+// itself, directly or through other scripts.
+var ErrIncludeCycle = errors.New("include cycle")
+
+// ScriptResult is the outcome of one statement of a script.
+type ScriptResult struct {
+	File      string // script the statement was read from, "" for ExecuteScript's input
+	Statement Statement
+	Result    SpecialCommandResult // QueryResult for SQL statements
+	Err       error                // a *ScriptError if a statement read from a script failed
+}
+
+// ScriptError reports the script position of a failed statement.
+type ScriptError struct {
+	File string
+	Line int
+	Err  error
+}
+
+func (e *ScriptError) Error() string {
+	file := e.File
+	if file == "" {
+		file = "<input>"
+	}
+	return fmt.Sprintf("%s:%d: %v", file, e.Line, e.Err)
+}
+
+func (e *ScriptError) Unwrap() error {
+	return e.Err
+}
+
+// scriptFrame is an entry of the stack of scripts being executed, kept in the
+// context to resolve \ir paths and detect include cycles.
+type scriptFrame struct {
+	file   string
+	abs    string
+	parent *scriptFrame
+}
+
+type scriptContextKey struct{}
+
+// ScriptDir returns the directory of the script file being executed, for
+// resolving paths relative to it as \ir does. It returns "" outside of
+// script files, including scripts run from an io.Reader.
+func ScriptDir(ctx context.Context) string {
+	if f, ok := ctx.Value(scriptContextKey{}).(*scriptFrame); ok && f.file != "" {
+		return filepath.Dir(f.file)
+	}
+	return ""
+}
+
+// ExecuteScript reads input to the end and executes it statement by
+// statement, as split by a Scanner: SQL statements are sent to queryer and
+// meta-commands are executed with the registry.
+//
+// SQL is interpolated from the session carried by ctx, which also receives
+// the result variables (see Session.RecordResult); a new session is used if
+// ctx has none. Rows are read to completion, SQL statements yielding a
+// QueryResult and commands returning a RowResult having it converted to one,
+// so queryer may be a single connection.
+//
+// The conditional commands \if, \elif, \else and \endif are handled by the
+// script itself: statements in branches not taken are skipped without being
+// reported, and a block left open at the end of the script is an error
+// (ErrUnterminatedIf) reported for its \if. Blocks cannot span included
+// scripts.
+//
+// A failed statement is reported in the Err of its ScriptResult and, like in
+// psql, execution continues with the next statement unless ON_ERROR_STOP is
+// set. In that case ExecuteScript stops and returns the results so far
+// together with the statement's *ScriptError.
+func (r *Registry) ExecuteScript(ctx context.Context, queryer database.Queryer, input io.Reader) ([]ScriptResult, error) {
+	data, err := io.ReadAll(input)
+	if err != nil {
+		return nil, err
+	}
+	parent, _ := ctx.Value(scriptContextKey{}).(*scriptFrame)
+	return r.runScript(ctx, queryer, &scriptFrame{parent: parent}, string(data))
+}
+
+// ExecuteFile executes the script in the file at path like ExecuteScript. A
+// script that is already being executed by the calling command cannot be
+// included again; that is reported as an error wrapping ErrIncludeCycle.
+func (r *Registry) ExecuteFile(ctx context.Context, queryer database.Queryer, path string) ([]ScriptResult, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	parent, _ := ctx.Value(scriptContextKey{}).(*scriptFrame)
+	for f := parent; f != nil; f = f.parent {
+		if f.abs != abs {
+			continue
+		}
+		chain := []string{path}
+		for g := parent; g != nil; g = g.parent {
+			if g.file != "" {
+				chain = append([]string{g.file}, chain...)
+			}
+		}
+		return nil, fmt.Errorf("%w: %s", ErrIncludeCycle, strings.Join(chain, " -> "))
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return r.runScript(ctx, queryer, &scriptFrame{file: path, abs: abs, parent: parent}, string(data))
+}
+
+func (r *Registry) runScript(ctx context.Context, queryer database.Queryer, frame *scriptFrame, input string) ([]ScriptResult, error) {
+	session := SessionFromContext(ctx)
+	if session == nil {
+		session = NewSession()
+		ctx = WithSession(ctx, session)
+	}
+	ctx = context.WithValue(ctx, scriptContextKey{}, frame)
+	// the statements of a script are not hidden queries of the command
+	// running it
+	queryer = UnwrapQueryer(queryer)
+
+	// the script has its own query buffer; the caller's is restored after it
+	buf := session.QueryBuffer()
+	defer buf.Set(buf.String())
+	buf.Reset()
+
+	var results []ScriptResult
+	var cond conditionalStack
+	sc := NewScanner(input)
+	for sc.Scan() {
+		stmt := sc.Statement()
+
+		var res SpecialCommandResult
+		var err error
+		if name, args, ok := splitConditional(stmt.Text); ok && stmt.Kind == StatementMeta {
+			// SQL left unterminated in a skipped branch is discarded
+			if !cond.active() {
+				sc.ResetQuery()
+			}
+			err = cond.apply(name, stmt, func() (bool, error) {
+				return evalCondition(session, name, args)
+			})
+		} else if !cond.active() {
+			continue
+		} else if stmt.Kind == StatementMeta {
+			buf.Set(stmt.Query)
+			res, err = r.execute(ctx, queryer, stmt.Text)
+			// a command that sent the buffer, like \g, consumed the SQL
+			// entered before it; one that loaded the buffer, like \e,
+			// replaced it
+			if text := buf.String(); text != stmt.Query {
+				sc.replaceQuery(text)
+			}
+			res, err = collectResult(res, err)
+
+			// statements of an included script are reported as they
+			// are, including the position of their errors
+			if included, ok := res.(ScriptResults); ok {
+				results = append(results, included.Results...)
+				var scriptErr *ScriptError
+				if err == nil || errors.As(err, &scriptErr) {
+					if err != nil && session.OnErrorStop() {
+						return results, err
+					}
+					continue
+				}
+				res = nil
+			}
+		} else {
+			buf.Set(session.Interpolate(stmt.Text))
+			query, _ := buf.Take()
+			res, err = ExecuteQuery(ctx, queryer, query)
+		}
+
+		if err != nil {
+			res = nil
+			var scriptErr *ScriptError
+			if !errors.As(err, &scriptErr) {
+				err = &ScriptError{File: frame.file, Line: stmt.Line, Err: err}
+			}
+		}
+		results = append(results, ScriptResult{File: frame.file, Statement: stmt, Result: res, Err: err})
+		if err != nil && session.OnErrorStop() {
+			return results, err
+		}
+	}
+
+	if len(cond.ifs) > 0 {
+		stmt := cond.ifs[0]
+		err := &ScriptError{File: frame.file, Line: stmt.Line, Err: ErrUnterminatedIf}
+		results = append(results, ScriptResult{File: frame.file, Statement: stmt, Err: err})
+		if session.OnErrorStop() {
+			return results, err
+		}
+	}
+	return results, nil
+}
+
+// collectResult reads the rows of a RowResult, also when wrapped in an
+// EchoHiddenResult, into a QueryResult, freeing the connection for the next
+// statement of a script.
+func collectResult(res SpecialCommandResult, err error) (SpecialCommandResult, error) {
+	switch r := res.(type) {
+	case RowResult:
+		if r.Rows == nil {
+			return res, err
+		}
+		collected, err := collectRows(r.Rows, err)
+		collected.Expanded, collected.Output = r.Expanded, r.Output
+		return collected, err
+	case EchoHiddenResult:
+		r.Result, err = collectResult(r.Result, err)
+		return r, err
+	}
+	return res, err
+}
+
+// ExecuteScript executes a script using the default registry. See
+// Registry.ExecuteScript.
+func ExecuteScript(ctx context.Context, queryer database.Queryer, input io.Reader) ([]ScriptResult, error) {
+	return defaultRegistry.ExecuteScript(ctx, queryer, input)
+}
@@ -0,0 +1,152 @@
+package pgxspecial_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/balaji01-4d/pgxspecial"
+	"github.com/balaji01-4d/pgxspecial/database"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeQueryer answers every query with a single row holding the query text,
+// and fails queries starting with "fail".
+type fakeQueryer struct {
+	queries []string
+}
+
+func (q *fakeQueryer) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	q.queries = append(q.queries, sql)
+	if strings.HasPrefix(sql, "fail") {
+		return nil, &pgconn.PgError{Severity: "ERROR", Code: "42601", Message: "syntax error"}
+	}
+	word := strings.ToUpper(strings.Fields(sql)[0])
+	if word == "SELECT" {
+		return &fakeRows{columns: []string{"query"}, rows: [][]any{{sql}}, tag: "SELECT 1"}, nil
+	}
+	return &fakeRows{tag: word + " 0 3"}, nil
+}
+
+func (q *fakeQueryer) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return nil
+}
+
+type fakeRows struct {
+	columns []string
+	rows    [][]any
+	next    int
+	tag     string
+}
+
+func (r *fakeRows) Close()                        {}
+func (r *fakeRows) Err() error                    { return nil }
+func (r *fakeRows) CommandTag() pgconn.CommandTag { return pgconn.NewCommandTag(r.tag) }
+func (r *fakeRows) Scan(dest ...any) error        { return errors.New("not implemented") }
+func (r *fakeRows) RawValues() [][]byte           { return nil }
+func (r *fakeRows) Conn() *pgx.Conn               { return nil }
+
+func (r *fakeRows) FieldDescriptions() []pgconn.FieldDescription {
+	fds := make([]pgconn.FieldDescription, len(r.columns))
+	for i, name := range r.columns {
+		fds[i].Name = name
+	}
+	return fds
+}
+
+func (r *fakeRows) Next() bool {
+	r.next++
+	return r.next <= len(r.rows)
+}
+
+func (r *fakeRows) Values() ([]any, error) {
+	return r.rows[r.next-1], nil
+}
+
+func TestExecuteScript(t *testing.T) {
+	db := &fakeQueryer{}
+	session := pgxspecial.NewSession()
+	ctx := pgxspecial.WithSession(context.Background(), session)
+
+	script := "\\set tbl users\nselect * from :tbl;\n-- comment\nupdate :tbl set x = 1;\n"
+	results, err := pgxspecial.ExecuteScript(ctx, db, strings.NewReader(script))
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+
+	assert.Equal(t, `\set tbl users`, results[0].Statement.Text)
+	assert.Nil(t, results[0].Result)
+	assert.NoError(t, results[0].Err)
+
+	assert.Equal(t, pgxspecial.QueryResult{
+		Columns:    []string{"query"},
+		Rows:       [][]any{{"select * from users"}},
+		CommandTag: "SELECT 1",
+	}, results[1].Result)
+	assert.Equal(t, 2, results[1].Statement.Line)
+
+	assert.Equal(t, "UPDATE 0 3", results[2].Result.(pgxspecial.QueryResult).CommandTag)
+	assert.Equal(t, 4, results[2].Statement.Line)
+
+	assert.Equal(t, []string{"select * from users", "update users set x = 1"}, db.queries)
+	rowCount, _ := session.Get(pgxspecial.VarRowCount)
+	assert.Equal(t, "3", rowCount)
+}
+
+func TestExecuteScriptContinuesAfterError(t *testing.T) {
+	db := &fakeQueryer{}
+	results, err := pgxspecial.ExecuteScript(context.Background(), db, strings.NewReader("fail 1;\n\\nope\nselect 2;"))
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+
+	var scriptErr *pgxspecial.ScriptError
+	require.ErrorAs(t, results[0].Err, &scriptErr)
+	assert.Equal(t, 1, scriptErr.Line)
+	assert.Equal(t, "<input>:1: ERROR: syntax error (SQLSTATE 42601)", results[0].Err.Error())
+	assert.Nil(t, results[0].Result)
+
+	assert.EqualError(t, results[1].Err, `<input>:2: Unknown Command: \nope`)
+	assert.NoError(t, results[2].Err)
+}
+
+func TestExecuteScriptOnErrorStop(t *testing.T) {
+	db := &fakeQueryer{}
+	session := pgxspecial.NewSession()
+	ctx := pgxspecial.WithSession(context.Background(), session)
+
+	script := "\\set ON_ERROR_STOP on\nselect 1;\nfail 2;\nselect 3;\n"
+	results, err := pgxspecial.ExecuteScript(ctx, db, strings.NewReader(script))
+	require.Error(t, err)
+	assert.Len(t, results, 3)
+	assert.Equal(t, results[2].Err, err)
+	assert.Equal(t, []string{"select 1", "fail 2"}, db.queries)
+
+	var pgErr *pgconn.PgError
+	require.ErrorAs(t, err, &pgErr)
+	lastError, _ := session.Get(pgxspecial.VarLastErrorSQLState)
+	assert.Equal(t, "42601", lastError)
+}
+
+func TestExecuteScriptCollectsRowResults(t *testing.T) {
+	db := &fakeQueryer{}
+	reg := pgxspecial.NewRegistry()
+	require.NoError(t, reg.Register(pgxspecial.SpecialCommandRegistry{
+		Cmd: "\\rows",
+		Handler: func(ctx context.Context, db database.Queryer, args string, opts pgxspecial.CommandOptions) (pgxspecial.SpecialCommandResult, error) {
+			rows, err := db.Query(ctx, "select "+args)
+			return pgxspecial.RowResult{Rows: rows}, err
+		},
+	}))
+
+	results, err := reg.ExecuteScript(context.Background(), db, strings.NewReader(`\rows 42`))
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, pgxspecial.QueryResult{
+		Columns:    []string{"query"},
+		Rows:       [][]any{{"select 42"}},
+		CommandTag: "SELECT 1",
+	}, results[0].Result)
+}
@@ -0,0 +1,578 @@
+package pgxspecial
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Names of the psql variables with a special meaning. Setting one of the
+// first three is validated, and \unset restores its default instead of
+// removing it. The others are maintained by RecordResult.
+const (
+	VarOnErrorStop       = "ON_ERROR_STOP"
+	VarEchoHidden        = "ECHO_HIDDEN"
+	VarFetchCount        = "FETCH_COUNT"
+	VarError             = "ERROR"
+	VarSQLState          = "SQLSTATE"
+	VarRowCount          = "ROW_COUNT"
+	VarLastErrorMessage  = "LAST_ERROR_MESSAGE"
+	VarLastErrorSQLState = "LAST_ERROR_SQLSTATE"
+)
+
+const (
+	successfulSQLState = "00000"
+)
+
+// ErrNoSession is returned by commands that need session state, such as
+// \set, when the context carries no Session. See WithSession.
+var ErrNoSession = errors.New("no session: use pgxspecial.WithSession")
+
+// specialVariable describes the psql hooks of a special variable: the value
+// it takes when unset or set to an empty string, and the check a new value
+// must pass.
+type specialVariable struct {
+	unset    string
+	empty    string
+	validate func(name, value string) error
+}
+
+var specialVariables = map[string]specialVariable{
+	VarOnErrorStop: {unset: "off", empty: "on", validate: validateBool},
+	VarEchoHidden: {unset: "off", empty: "on", validate: func(name, value string) error {
+		if strings.EqualFold(value, EchoHiddenNoExec) {
+			return nil
+		}
+		if _, ok := ParseBool(value); !ok {
+			return fmt.Errorf("unrecognized value %q for %q: expected on, off or noexec", value, name)
+		}
+		return nil
+	}},
+	VarFetchCount: {unset: "0", empty: "0", validate: func(name, value string) error {
+		if _, err := strconv.Atoi(strings.TrimSpace(value)); err != nil {
+			return fmt.Errorf("invalid value %q for %q: integer expected", value, name)
+		}
+		return nil
+	}},
+}
+
+func validateBool(name, value string) error {
+	if _, ok := ParseBool(value); !ok {
+		return fmt.Errorf("unrecognized value %q for %q: Boolean expected", value, name)
+	}
+	return nil
+}
+
+// ParseBool parses a psql boolean variable value. Like psql it accepts any
+// case-insensitive prefix of "true", "false", "yes" and "no", "on" and "off"
+// (at least two letters), "1" and "0".
+func ParseBool(value string) (b, ok bool) {
+	v := strings.ToLower(value)
+	switch {
+	case v == "":
+		return false, false
+	case strings.HasPrefix("true", v), strings.HasPrefix("yes", v), v == "1":
+		return true, true
+	case strings.HasPrefix("false", v), strings.HasPrefix("no", v), v == "0":
+		return false, true
+	case len(v) >= 2 && strings.HasPrefix("on", v):
+		return true, true
+	case len(v) >= 2 && strings.HasPrefix("off", v):
+		return false, true
+	}
+	return false, false
+}
+
+// Variable is a psql variable and its value.
+type Variable struct {
+	Name  string
+	Value string
+}
+
+// Session holds the state of one client session that outlives a single
+// command, such as the psql variables managed by \set and \unset, the query
+// buffer, the display settings, and the environment and working directory
+// of shell commands.
+// Commands reach it through the context, see WithSession.
+//
+// A Session is safe for concurrent use by multiple goroutines.
+type Session struct {
+	mu     sync.RWMutex
+	vars   map[string]string
+	buffer QueryBuffer
+	print  PrintOptions
+	env    map[string]*string // nil values are unset
+	dir    string
+}
+
+// NewSession returns a Session with the special variables and the display
+// settings set to their psql defaults.
+func NewSession() *Session {
+	s := &Session{
+		vars: map[string]string{
+			VarLastErrorMessage:  "",
+			VarLastErrorSQLState: successfulSQLState,
+		},
+		print: DefaultPrintOptions(),
+	}
+	for name, sv := range specialVariables {
+		s.vars[name] = sv.unset
+	}
+	return s
+}
+
+// QueryBuffer returns the query buffer of the session.
+func (s *Session) QueryBuffer() *QueryBuffer {
+	return &s.buffer
+}
+
+// Setenv sets the environment variable name for the shell commands run in
+// the session, leaving the environment of the process alone.
+func (s *Session) Setenv(name, value string) error {
+	return s.setenv(name, &value)
+}
+
+// Unsetenv removes the environment variable name for the shell commands run
+// in the session.
+func (s *Session) Unsetenv(name string) error {
+	return s.setenv(name, nil)
+}
+
+func (s *Session) setenv(name string, value *string) error {
+	if name == "" || strings.Contains(name, "=") {
+		return fmt.Errorf("invalid environment variable name: %q", name)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.env == nil {
+		s.env = make(map[string]*string)
+	}
+	s.env[name] = value
+	return nil
+}
+
+// Environ returns the environment of the shell commands run in the session:
+// the environment of the process with the changes made by Setenv and
+// Unsetenv, in the form "NAME=VALUE".
+func (s *Session) Environ() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var env []string
+	for _, kv := range os.Environ() {
+		name, _, _ := strings.Cut(kv, "=")
+		if _, ok := s.env[name]; !ok {
+			env = append(env, kv)
+		}
+	}
+	names := make([]string, 0, len(s.env))
+	for name, value := range s.env {
+		if value != nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		env = append(env, name+"="+*s.env[name])
+	}
+	return env
+}
+
+// Chdir changes the working directory of the shell commands run in the
+// session to dir, relative to the current one. The working directory of
+// the process is left alone.
+func (s *Session) Chdir(dir string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !filepath.IsAbs(dir) && s.dir != "" {
+		dir = filepath.Join(s.dir, dir)
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s: not a directory", dir)
+	}
+	s.dir = dir
+	return nil
+}
+
+// Dir returns the working directory of the shell commands run in the
+// session, or "" for the working directory of the process.
+func (s *Session) Dir() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.dir
+}
+
+// validVariableName reports whether name may be used as a variable name:
+// ASCII letters, digits and underscores, plus any non-ASCII character.
+func validVariableName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		if !isVariableChar(name[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func isVariableChar(c byte) bool {
+	return c == '_' || c >= 0x80 ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// Set assigns value to the variable name. Values of the special variables
+// ON_ERROR_STOP, ECHO_HIDDEN and FETCH_COUNT are validated, and an empty
+// value turns ON_ERROR_STOP and ECHO_HIDDEN on.
+func (s *Session) Set(name, value string) error {
+	if !validVariableName(name) {
+		return fmt.Errorf("invalid variable name: %q", name)
+	}
+	if sv, ok := specialVariables[name]; ok {
+		if value == "" {
+			value = sv.empty
+		}
+		if err := sv.validate(name, value); err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.vars[name] = value
+	return nil
+}
+
+// Unset removes the variable name. Special variables with a default are
+// reset to it instead.
+func (s *Session) Unset(name string) error {
+	if !validVariableName(name) {
+		return fmt.Errorf("invalid variable name: %q", name)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if sv, ok := specialVariables[name]; ok {
+		s.vars[name] = sv.unset
+	} else {
+		delete(s.vars, name)
+	}
+	return nil
+}
+
+// Get returns the value of the variable name and whether it is set.
+func (s *Session) Get(name string) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	value, ok := s.vars[name]
+	return value, ok
+}
+
+// Variables returns every variable that is set, sorted by name.
+func (s *Session) Variables() []Variable {
+	s.mu.RLock()
+	vars := make([]Variable, 0, len(s.vars))
+	for name, value := range s.vars {
+		vars = append(vars, Variable{Name: name, Value: value})
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(vars, func(i, j int) bool {
+		return vars[i].Name < vars[j].Name
+	})
+	return vars
+}
+
+// OnErrorStop reports whether ON_ERROR_STOP is on.
+func (s *Session) OnErrorStop() bool {
+	value, _ := s.Get(VarOnErrorStop)
+	b, _ := ParseBool(value)
+	return b
+}
+
+// EchoHidden returns the ECHO_HIDDEN setting normalized to "on", "off" or
+// "noexec".
+func (s *Session) EchoHidden() string {
+	value, _ := s.Get(VarEchoHidden)
+	if strings.EqualFold(value, EchoHiddenNoExec) {
+		return EchoHiddenNoExec
+	}
+	if b, _ := ParseBool(value); b {
+		return EchoHiddenOn
+	}
+	return EchoHiddenOff
+}
+
+// FetchCount returns FETCH_COUNT, the number of rows to fetch at a time, or 0
+// to fetch whole results.
+func (s *Session) FetchCount() int {
+	value, _ := s.Get(VarFetchCount)
+	n, _ := strconv.Atoi(strings.TrimSpace(value))
+	return max(n, 0)
+}
+
+// RecordResult updates the variables psql maintains after each SQL command:
+// ERROR, SQLSTATE and ROW_COUNT, and on failure LAST_ERROR_MESSAGE and
+// LAST_ERROR_SQLSTATE. rowCount is the number of rows returned or affected
+// by a successful command.
+func (s *Session) RecordResult(rowCount int64, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err == nil {
+		s.vars[VarError] = "false"
+		s.vars[VarSQLState] = successfulSQLState
+		s.vars[VarRowCount] = strconv.FormatInt(rowCount, 10)
+		return
+	}
+
+	// errors raised by the client rather than the server use psql's
+	// "internal error" code
+	state := "XX000"
+	message := err.Error()
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		state = pgErr.Code
+		message = pgErr.Message
+	}
+	s.vars[VarError] = "true"
+	s.vars[VarSQLState] = state
+	s.vars[VarRowCount] = "0"
+	s.vars[VarLastErrorMessage] = message
+	s.vars[VarLastErrorSQLState] = state
+}
+
+// Interpolate substitutes variable references in the SQL text sql the way
+// psql does before sending a query: :name is replaced by the value, :'name'
+// by the value quoted as a literal and :"name" by the value quoted as an
+// identifier. References inside string literals, quoted identifiers,
+// dollar-quoted strings and comments are left alone, as are references to
+// variables that are not set and the :: cast operator.
+func (s *Session) Interpolate(sql string) string {
+	return s.interpolate(sql, true)
+}
+
+// interpolate implements Interpolate. With sqlText unset it follows the
+// rules for meta-command arguments instead, where only single-quoted,
+// double-quoted and backquoted text is protected.
+func (s *Session) interpolate(text string, sqlText bool) string {
+	if !strings.Contains(text, ":") {
+		return text
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var sb strings.Builder
+	for i := 0; i < len(text); {
+		c := text[i]
+		end := i + 1
+
+		switch {
+		case c == '\'':
+			// E'...' strings and meta-command arguments treat backslash
+			// as an escape character
+			escapes := !sqlText || (i > 0 && (text[i-1] == 'e' || text[i-1] == 'E') &&
+				(i == 1 || !isIdentChar(text[i-2])))
+			end = skipQuoted(text, i, '\'', escapes)
+
+		case c == '"' || (!sqlText && c == '`'):
+			end = skipQuoted(text, i, c, false)
+
+		case sqlText && c == '-' && strings.HasPrefix(text[i:], "--"):
+			end = strings.IndexByte(text[i:], '\n')
+			if end < 0 {
+				end = len(text)
+			} else {
+				end += i + 1
+			}
+
+		case sqlText && c == '/' && strings.HasPrefix(text[i:], "/*"):
+			end = skipBlockComment(text, i)
+
+		case sqlText && c == '$' && (i == 0 || !isIdentChar(text[i-1])):
+			end = skipDollarQuoted(text, i)
+
+		case c == ':' && strings.HasPrefix(text[i:], "::"):
+			end = i + 2
+
+		case c == ':':
+			if value, n, ok := s.expandVariable(text[i+1:]); ok {
+				sb.WriteString(value)
+				i += 1 + n
+				continue
+			}
+		}
+
+		sb.WriteString(text[i:end])
+		i = end
+	}
+	return sb.String()
+}
+
+// ExpandVariable resolves the variable reference at the start of text, which
+// follows a colon: name, 'name' or "name". It returns the value, quoted as a
+// literal or identifier for the quoted forms, and the number of bytes of
+// text consumed. ok is false if text does not start with a reference to a
+// variable that is set.
+func (s *Session) ExpandVariable(text string) (value string, n int, ok bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.expandVariable(text)
+}
+
+// expandVariable implements ExpandVariable. The caller holds s.mu.
+func (s *Session) expandVariable(text string) (string, int, bool) {
+	quote := byte(0)
+	if text != "" && (text[0] == '\'' || text[0] == '"') {
+		quote = text[0]
+	}
+
+	start := 0
+	if quote != 0 {
+		start = 1
+	}
+	n := start
+	for n < len(text) && isVariableChar(text[n]) {
+		n++
+	}
+	if n == start {
+		return "", 0, false
+	}
+
+	name := text[start:n]
+	if quote != 0 {
+		if n >= len(text) || text[n] != quote {
+			return "", 0, false
+		}
+		n++
+	}
+
+	value, ok := s.vars[name]
+	if !ok {
+		return "", 0, false
+	}
+	switch quote {
+	case '\'':
+		value = quoteLiteral(value)
+	case '"':
+		value = quoteIdentifier(value)
+	}
+	return value, n, true
+}
+
+func isIdentChar(c byte) bool {
+	return isVariableChar(c) || c == '$'
+}
+
+// skipQuoted returns the index just past the quoted text starting at
+// text[start], where a doubled quote stands for itself. Unterminated quotes
+// extend to the end of text.
+func skipQuoted(text string, start int, quote byte, escapes bool) int {
+	for i := start + 1; i < len(text); i++ {
+		switch {
+		case escapes && text[i] == '\\':
+			i++
+		case text[i] == quote:
+			if i+1 < len(text) && text[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return len(text)
+}
+
+// skipBlockComment returns the index just past the, possibly nested,
+// comment starting at text[start].
+func skipBlockComment(text string, start int) int {
+	depth := 0
+	for i := start; i < len(text)-1; i++ {
+		switch text[i : i+2] {
+		case "/*":
+			depth++
+			i++
+		case "*/":
+			depth--
+			i++
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return len(text)
+}
+
+// skipDollarQuoted returns the index just past the dollar-quoted string
+// starting at text[start], or start+1 if the dollar sign does not open one,
+// as in the parameter $1.
+func skipDollarQuoted(text string, start int) int {
+	i := start + 1
+	for i < len(text) && text[i] != '$' {
+		c := text[i]
+		if !isVariableChar(c) || (i == start+1 && c >= '0' && c <= '9') {
+			return start + 1
+		}
+		i++
+	}
+	if i >= len(text) {
+		return start + 1
+	}
+
+	tag := text[start : i+1]
+	if end := strings.Index(text[i+1:], tag); end >= 0 {
+		return i + 1 + end + len(tag)
+	}
+	return len(text)
+}
+
+// quoteLiteral quotes value as an SQL string literal like libpq's
+// PQescapeLiteral, switching to an E'...' string when value contains
+// backslashes.
+func quoteLiteral(value string) string {
+	quoted := "'" + strings.ReplaceAll(value, "'", "''") + "'"
+	if strings.Contains(value, "\\") {
+		return " E" + strings.ReplaceAll(quoted, "\\", "\\\\")
+	}
+	return quoted
+}
+
+// quoteIdentifier quotes value as an SQL identifier.
+func quoteIdentifier(value string) string {
+	return `"` + strings.ReplaceAll(value, `"`, `""`) + `"`
+}
+
+type sessionContextKey struct{}
+
+// WithSession returns a copy of ctx carrying s. Commands executed with the
+// returned context read and update the variables of s, and their arguments
+// are interpolated from them.
+func WithSession(ctx context.Context, s *Session) context.Context {
+	return context.WithValue(ctx, sessionContextKey{}, s)
+}
+
+// SessionFromContext returns the Session carried by ctx, or nil if there is
+// none.
+func SessionFromContext(ctx context.Context) *Session {
+	s, _ := ctx.Value(sessionContextKey{}).(*Session)
+	return s
+}
@@ -0,0 +1,218 @@
+package pgxspecial_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/balaji01-4d/pgxspecial"
+	"github.com/balaji01-4d/pgxspecial/database"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBool(t *testing.T) {
+	for _, v := range []string{"on", "ON", "t", "true", "y", "yes", "1"} {
+		b, ok := pgxspecial.ParseBool(v)
+		assert.True(t, ok, v)
+		assert.True(t, b, v)
+	}
+	for _, v := range []string{"off", "of", "f", "FALSE", "n", "no", "0"} {
+		b, ok := pgxspecial.ParseBool(v)
+		assert.True(t, ok, v)
+		assert.False(t, b, v)
+	}
+	for _, v := range []string{"", "o", "2", "onn", "maybe"} {
+		_, ok := pgxspecial.ParseBool(v)
+		assert.False(t, ok, v)
+	}
+}
+
+func TestSessionVariables(t *testing.T) {
+	s := pgxspecial.NewSession()
+
+	require.NoError(t, s.Set("foo", "bar"))
+	v, ok := s.Get("foo")
+	assert.True(t, ok)
+	assert.Equal(t, "bar", v)
+
+	require.NoError(t, s.Unset("foo"))
+	_, ok = s.Get("foo")
+	assert.False(t, ok)
+
+	assert.EqualError(t, s.Set("foo bar", "x"), `invalid variable name: "foo bar"`)
+	assert.Error(t, s.Set("", "x"))
+
+	vars := s.Variables()
+	require.NotEmpty(t, vars)
+	for i := 1; i < len(vars); i++ {
+		assert.Less(t, vars[i-1].Name, vars[i].Name)
+	}
+}
+
+func TestSessionEnvironment(t *testing.T) {
+	t.Setenv("PGXSPECIAL_A", "process")
+	t.Setenv("PGXSPECIAL_B", "process")
+	s := pgxspecial.NewSession()
+
+	require.NoError(t, s.Setenv("PGXSPECIAL_A", "session"))
+	require.NoError(t, s.Setenv("PGXSPECIAL_C", ""))
+	require.NoError(t, s.Unsetenv("PGXSPECIAL_B"))
+	env := s.Environ()
+	assert.Contains(t, env, "PGXSPECIAL_A=session")
+	assert.Contains(t, env, "PGXSPECIAL_C=")
+	assert.NotContains(t, env, "PGXSPECIAL_A=process")
+	assert.NotContains(t, env, "PGXSPECIAL_B=process")
+	assert.Equal(t, "process", os.Getenv("PGXSPECIAL_A"), "the process environment is left alone")
+
+	assert.EqualError(t, s.Setenv("A=B", "x"), `invalid environment variable name: "A=B"`)
+	assert.Error(t, s.Unsetenv(""))
+}
+
+func TestSessionChdir(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "sub"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "file"), nil, 0o644))
+	s := pgxspecial.NewSession()
+	assert.Empty(t, s.Dir())
+
+	require.NoError(t, s.Chdir(dir))
+	assert.Equal(t, dir, s.Dir())
+	require.NoError(t, s.Chdir("sub"))
+	assert.Equal(t, filepath.Join(dir, "sub"), s.Dir())
+	require.NoError(t, s.Chdir(".."))
+	assert.Equal(t, dir, s.Dir())
+
+	assert.Error(t, s.Chdir("missing"))
+	assert.Error(t, s.Chdir("file"))
+	assert.Equal(t, dir, s.Dir())
+}
+
+func TestSessionSpecialVariables(t *testing.T) {
+	s := pgxspecial.NewSession()
+	assert.False(t, s.OnErrorStop())
+	assert.Equal(t, "off", s.EchoHidden())
+	assert.Equal(t, 0, s.FetchCount())
+
+	// an empty value turns a boolean on, \unset restores the default
+	require.NoError(t, s.Set(pgxspecial.VarOnErrorStop, ""))
+	assert.True(t, s.OnErrorStop())
+	require.NoError(t, s.Unset(pgxspecial.VarOnErrorStop))
+	assert.False(t, s.OnErrorStop())
+	v, ok := s.Get(pgxspecial.VarOnErrorStop)
+	assert.True(t, ok)
+	assert.Equal(t, "off", v)
+
+	assert.EqualError(t, s.Set(pgxspecial.VarOnErrorStop, "sometimes"),
+		`unrecognized value "sometimes" for "ON_ERROR_STOP": Boolean expected`)
+
+	require.NoError(t, s.Set(pgxspecial.VarEchoHidden, "NoExec"))
+	assert.Equal(t, "noexec", s.EchoHidden())
+	require.NoError(t, s.Set(pgxspecial.VarEchoHidden, "yes"))
+	assert.Equal(t, "on", s.EchoHidden())
+	assert.Error(t, s.Set(pgxspecial.VarEchoHidden, "loud"))
+
+	require.NoError(t, s.Set(pgxspecial.VarFetchCount, "100"))
+	assert.Equal(t, 100, s.FetchCount())
+	assert.EqualError(t, s.Set(pgxspecial.VarFetchCount, "many"),
+		`invalid value "many" for "FETCH_COUNT": integer expected`)
+	assert.Equal(t, 100, s.FetchCount())
+}
+
+func TestSessionRecordResult(t *testing.T) {
+	s := pgxspecial.NewSession()
+	get := func(name string) string {
+		v, _ := s.Get(name)
+		return v
+	}
+
+	assert.Equal(t, "", get(pgxspecial.VarLastErrorMessage))
+	assert.Equal(t, "00000", get(pgxspecial.VarLastErrorSQLState))
+
+	s.RecordResult(3, nil)
+	assert.Equal(t, "false", get(pgxspecial.VarError))
+	assert.Equal(t, "3", get(pgxspecial.VarRowCount))
+
+	s.RecordResult(0, &pgconn.PgError{Code: "42P01", Message: `relation "nope" does not exist`})
+	assert.Equal(t, "true", get(pgxspecial.VarError))
+	assert.Equal(t, "42P01", get(pgxspecial.VarSQLState))
+	assert.Equal(t, "0", get(pgxspecial.VarRowCount))
+	assert.Equal(t, `relation "nope" does not exist`, get(pgxspecial.VarLastErrorMessage))
+	assert.Equal(t, "42P01", get(pgxspecial.VarLastErrorSQLState))
+
+	// the last error is kept across successful commands
+	s.RecordResult(1, nil)
+	assert.Equal(t, "00000", get(pgxspecial.VarSQLState))
+	assert.Equal(t, "42P01", get(pgxspecial.VarLastErrorSQLState))
+
+	s.RecordResult(0, errors.New("connection lost"))
+	assert.Equal(t, "XX000", get(pgxspecial.VarSQLState))
+	assert.Equal(t, "connection lost", get(pgxspecial.VarLastErrorMessage))
+}
+
+func TestSessionInterpolate(t *testing.T) {
+	s := pgxspecial.NewSession()
+	require.NoError(t, s.Set("tbl", "users"))
+	require.NoError(t, s.Set("name", "O'Brien"))
+	require.NoError(t, s.Set("path", `C:\tmp`))
+	require.NoError(t, s.Set("ident", `My "Table"`))
+
+	tests := []struct {
+		name     string
+		sql      string
+		expected string
+	}{
+		{"plain", "SELECT * FROM :tbl", "SELECT * FROM users"},
+		{"literal", "SELECT :'name'", "SELECT 'O''Brien'"},
+		{"literal with backslash", "SELECT :'path'", `SELECT  E'C:\\tmp'`},
+		{"identifier", `SELECT * FROM :"ident"`, `SELECT * FROM "My ""Table"""`},
+		{"undefined", "SELECT :nope, :'nope', :\"nope\"", "SELECT :nope, :'nope', :\"nope\""},
+		{"cast", "SELECT 1::text, :tbl::regclass", "SELECT 1::text, users::regclass"},
+		{"string literal", "SELECT ':tbl', 'it''s :tbl'", "SELECT ':tbl', 'it''s :tbl'"},
+		{"escape string", `SELECT E'\':tbl', :tbl`, `SELECT E'\':tbl', users`},
+		{"quoted identifier", `SELECT ":tbl" FROM :tbl`, `SELECT ":tbl" FROM users`},
+		{"line comment", "SELECT 1 -- :tbl\nFROM :tbl", "SELECT 1 -- :tbl\nFROM users"},
+		{"block comment", "/* a /* :tbl */ :tbl */ :tbl", "/* a /* :tbl */ :tbl */ users"},
+		{"dollar quote", "SELECT $f$ :tbl $f$, $$:tbl$$, :tbl", "SELECT $f$ :tbl $f$, $$:tbl$$, users"},
+		{"parameter", "SELECT $1, :tbl", "SELECT $1, users"},
+		{"unterminated quote", "SELECT ':tbl", "SELECT ':tbl"},
+		{"adjacent text", "SELECT :tbl.id, x:tbl", "SELECT users.id, xusers"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, s.Interpolate(tt.sql))
+		})
+	}
+}
+
+func TestExecuteInterpolatesArguments(t *testing.T) {
+	reg := pgxspecial.NewRegistry()
+	var got string
+	require.NoError(t, reg.Register(pgxspecial.SpecialCommandRegistry{
+		Cmd: "\\echo",
+		Handler: func(ctx context.Context, db database.Queryer, args string, opts pgxspecial.CommandOptions) (pgxspecial.SpecialCommandResult, error) {
+			got = args
+			return nil, nil
+		},
+	}))
+
+	s := pgxspecial.NewSession()
+	require.NoError(t, s.Set("who", "world"))
+
+	// without a session arguments are passed through unchanged
+	_, _, err := reg.Execute(context.Background(), nil, "\\echo hello :who")
+	require.NoError(t, err)
+	assert.Equal(t, "hello :who", got)
+
+	ctx := pgxspecial.WithSession(context.Background(), s)
+	_, _, err = reg.Execute(ctx, nil, "\\echo hello :who :'who' ':who' \":who\" `:who`")
+	require.NoError(t, err)
+	assert.Equal(t, "hello world 'world' ':who' \":who\" `:who`", got)
+
+	assert.Same(t, s, pgxspecial.SessionFromContext(ctx))
+	assert.Nil(t, pgxspecial.SessionFromContext(context.Background()))
+}
@@ -0,0 +1,33 @@
+package pgxspecial
+
+import (
+	"context"
+	"io"
+	"os"
+)
+
+// ShellStreams are the standard streams of the commands run by \!. Output
+// written to a nil Stdout or Stderr is captured and returned in the
+// ShellResult instead, and a nil Stdin reads nothing, so a server can run
+// commands with ShellStreams{} and send their output to its client.
+type ShellStreams struct {
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+type shellStreamsContextKey struct{}
+
+// WithShellStreams returns a copy of ctx carrying streams.
+func WithShellStreams(ctx context.Context, streams ShellStreams) context.Context {
+	return context.WithValue(ctx, shellStreamsContextKey{}, streams)
+}
+
+// ShellStreamsFromContext returns the ShellStreams carried by ctx, or the
+// standard streams of the process if there are none, as psql uses them.
+func ShellStreamsFromContext(ctx context.Context) ShellStreams {
+	if streams, ok := ctx.Value(shellStreamsContextKey{}).(ShellStreams); ok {
+		return streams
+	}
+	return ShellStreams{Stdin: os.Stdin, Stdout: os.Stdout, Stderr: os.Stderr}
+}
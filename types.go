@@ -1,7 +1,12 @@
 // this package contains special command types
 package pgxspecial
 
-import "github.com/jackc/pgx/v5"
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
 
 type SpecialResultKind int
 
@@ -9,31 +14,108 @@ const (
 	ResultKindRows SpecialResultKind = iota
 	ResultKindDescribeTable
 	ResultKindExtensionVerbose
+	ResultKindHelp
+	ResultKindSQLHelp
+	ResultKindVariables
+	ResultKindQuery
+	ResultKindScript
+	ResultKindQueryBuffer
+	ResultKindText
+	ResultKindStatus
+	ResultKindShell
+	ResultKindEchoHidden
+)
+
+var resultKindNames = [...]string{
+	ResultKindRows:             "rows",
+	ResultKindDescribeTable:    "describe_table",
+	ResultKindExtensionVerbose: "extension_verbose",
+	ResultKindHelp:             "help",
+	ResultKindSQLHelp:          "sql_help",
+	ResultKindVariables:        "variables",
+	ResultKindQuery:            "query",
+	ResultKindScript:           "script",
+	ResultKindQueryBuffer:      "query_buffer",
+	ResultKindText:             "text",
+	ResultKindStatus:           "status",
+	ResultKindShell:            "shell",
+	ResultKindEchoHidden:       "echo_hidden",
+}
+
+// String returns the name of the kind, such as "describe_table", as the
+// JSON documents of the format package give it.
+func (k SpecialResultKind) String() string {
+	if k >= 0 && int(k) < len(resultKindNames) {
+		return resultKindNames[k]
+	}
+	return fmt.Sprintf("SpecialResultKind(%d)", int(k))
+}
+
+// Help groups used to organize commands in the \? listing. They mirror the
+// section headings of psql's help output; ListCommands returns groups in the
+// order they are declared here, with custom groups sorted by name before
+// GroupOther.
+const (
+	GroupGeneral         = "General"
+	GroupHelp            = "Help"
+	GroupQueryBuffer     = "Query Buffer"
+	GroupInputOutput     = "Input/Output"
+	GroupConditional     = "Conditional"
+	GroupInformational   = "Informational"
+	GroupFormatting      = "Formatting"
+	GroupConnection      = "Connection"
+	GroupOperatingSystem = "Operating System"
+	GroupVariables       = "Variables"
+	GroupOther           = "Other"
 )
 
 // SpecialCommand represents a parsed and executable special command.
 //
 // It contains the normalized command name, descriptive metadata, and the handler
 // function invoked during execution. SpecialCommand values are stored internally
+// by a Registry and returned from Registry.Lookup.
 type SpecialCommand struct {
 	Cmd           string
+	Alias         []string
 	Syntax        string
 	Description   string
+	Group         string
 	Handler       SpecialHandler
 	CaseSensitive bool
+	RawArgs       bool
+	Categories    []string
 }
 
 // SpecialCommandRegistry describes a special command registration.
 //
 // It defines the command name, optional aliases, documentation metadata, and
 // execution handler used when registering commands via RegisterCommand.
+//
+// Group selects the \? section the command is listed under; commands without
+// a group are listed under GroupOther.
+//
+// RawArgs passes the arguments to the handler without interpolating session
+// variables, for handlers that expand them while splitting the arguments
+// into words (see Session.ExpandVariable), and for commands that take the
+// rest of the line as it is typed, such as \! and \sf, as psql does.
+//
+// Categories lists the categories of dangerous operations the command
+// performs, such as CategoryShell, for policies to deny; see Policy.
+//
+// Override allows the registration to replace commands already registered
+// under the same name or alias, removing them entirely; without it a
+// duplicate is reported as an error.
 type SpecialCommandRegistry struct {
 	Cmd           string
 	Alias         []string
 	Syntax        string
 	Description   string
+	Group         string
 	Handler       SpecialHandler
 	CaseSensitive bool
+	RawArgs       bool
+	Categories    []string
+	Override      bool
 }
 
 type SpecialCommandResult interface {
@@ -45,8 +127,15 @@ type SpecialCommandResult interface {
 // It is used for commands that return a set of rows.
 // For example, \dt to list tables.
 // The caller is responsible for closing the Rows when done.
+//
+// Title is the caption psql prints above the rows of catalog listings, such
+// as "List of relations". Expanded and Output carry the display requested
+// by commands sending the query buffer, such as \gx and \g file.
 type RowResult struct {
-	Rows pgx.Rows
+	Rows     pgx.Rows
+	Title    string
+	Expanded bool   // display in expanded mode
+	Output   string // file or "|command" to write to instead of the default output, see OpenOutput
 }
 
 func (r RowResult) ResultKind() SpecialResultKind {
@@ -57,37 +146,39 @@ func (r RowResult) ResultKind() SpecialResultKind {
 // this is not used in any return types directly, but is embedded in
 // DescribeTableResult.
 type TableFooterMeta struct {
-	Indexes          []string // lines under "Indexes:"
-	CheckConstraints []string // "Check constraints:"
-	ForeignKeys      []string // "Foreign-key constraints:"
-	ReferencedBy     []string // "Referenced by:"
-	ViewDefinition   *string  // "View definition:"
-
-	RulesEnabled  []string // under "Rules:"
-	RulesDisabled []string // "Disabled rules:"
-	RulesAlways   []string // "Rules firing always:"
-	RulesReplica  []string // "Rules firing on replica only:"
-
-	TriggersEnabled  []string // "Triggers:"
-	TriggersDisabled []string // "Disabled triggers:"
-	TriggersAlways   []string // "Triggers firing always:"
-	TriggersReplica  []string // "Triggers firing on replica only:"
-
-	PartitionOf          []string // "Partition of:"
-	PartitionConstraints []string // "Partition constraint:"
-	PartitionKey         *string  // "Partition key:"
-	Partitions           []string // "Partitions:" (or leave empty)
-	PartitionsSummary    *string  // "Number of partitions ..." (non-verbose form)
-
-	Inherits           []string // "Inherits"
-	ChildTables        []string // "Child tables" (verbose)
-	ChildTablesSummary *string  // "Number of child tables..."
-	TypedTableOf       *string  // "Typed table of type:"
-	HasOIDs            *bool    // "Has OIDs: yes|no"
-	Options            *string  // "Options: ..."
-	Server             *string  // "Server: ..."  (foreign tables)
-	FDWOptions         *string  // "FDW Options: (...)" (foreign tables)
-	OwnedBy            *string  // "Owned by:" (sequences)
+	Indexes            []string `json:"indexes,omitempty"`              // lines under "Indexes:"
+	CheckConstraints   []string `json:"check_constraints,omitempty"`    // "Check constraints:"
+	NotNullConstraints []string `json:"not_null_constraints,omitempty"` // "Not-null constraints:" (verbose, PG 18)
+	ForeignKeys        []string `json:"foreign_keys,omitempty"`         // "Foreign-key constraints:"
+	ReferencedBy       []string `json:"referenced_by,omitempty"`        // "Referenced by:"
+	ViewDefinition     *string  `json:"view_definition,omitempty"`      // "View definition:"
+
+	RulesEnabled  []string `json:"rules_enabled,omitempty"`  // under "Rules:"
+	RulesDisabled []string `json:"rules_disabled,omitempty"` // "Disabled rules:"
+	RulesAlways   []string `json:"rules_always,omitempty"`   // "Rules firing always:"
+	RulesReplica  []string `json:"rules_replica,omitempty"`  // "Rules firing on replica only:"
+
+	TriggersEnabled  []string `json:"triggers_enabled,omitempty"`  // "Triggers:"
+	TriggersDisabled []string `json:"triggers_disabled,omitempty"` // "Disabled triggers:"
+	TriggersAlways   []string `json:"triggers_always,omitempty"`   // "Triggers firing always:"
+	TriggersReplica  []string `json:"triggers_replica,omitempty"`  // "Triggers firing on replica only:"
+
+	PartitionOf          []string `json:"partition_of,omitempty"`          // "Partition of:"
+	PartitionConstraints []string `json:"partition_constraints,omitempty"` // "Partition constraint:" (verbose only)
+	PartitionKey         *string  `json:"partition_key,omitempty"`         // "Partition key:"
+	Partitions           []string `json:"partitions,omitempty"`            // "Partitions:" (or leave empty)
+	PartitionsSummary    *string  `json:"partitions_summary,omitempty"`    // "Number of partitions ..." (non-verbose form)
+
+	Inherits           []string `json:"inherits,omitempty"`             // "Inherits"
+	ChildTables        []string `json:"child_tables,omitempty"`         // "Child tables" (verbose)
+	ChildTablesSummary *string  `json:"child_tables_summary,omitempty"` // "Number of child tables..."
+	TypedTableOf       *string  `json:"typed_table_of,omitempty"`       // "Typed table of type:"
+	HasOIDs            *bool    `json:"has_oids,omitempty"`             // "Has OIDs: yes|no"
+	AccessMethod       *string  `json:"access_method,omitempty"`        // "Access method: ..." (verbose, PG 12)
+	Options            *string  `json:"options,omitempty"`              // "Options: ..."
+	Server             *string  `json:"server,omitempty"`               // "Server: ..."  (foreign tables)
+	FDWOptions         *string  `json:"fdw_options,omitempty"`          // "FDW Options: (...)" (foreign tables)
+	OwnedBy            *string  `json:"owned_by,omitempty"`             // "Owned by:" (sequences)
 }
 
 
@@ -95,11 +186,16 @@ type TableFooterMeta struct {
 // this is not used in any return types directly, but is embedded in
 // DescribeTableListResult.
 //
+// Title is the caption psql prints above the columns, such as
+// Table "public.users", and RelKind the pg_class.relkind of the relation.
+//
 // syntax: \d table_name
 type DescribeTableResult struct {
-	Columns       []string
-	Data          [][]string
-	TableMetaData TableFooterMeta
+	Title         string          `json:"title"`
+	RelKind       string          `json:"relkind"`
+	Columns       []string        `json:"columns"`
+	Data          [][]string      `json:"data"`
+	TableMetaData TableFooterMeta `json:"footers"`
 }
 
 
@@ -113,12 +209,199 @@ func (DescribeTableListResult) ResultKind() SpecialResultKind {
 	return ResultKindDescribeTable
 }
 
+// CommandGroup is a named section of the command listing returned by
+// ListCommands, holding each registered command once regardless of aliases.
+type CommandGroup struct {
+	Name     string
+	Commands []SpecialCommand
+}
+
+// HelpResult holds the grouped command listing produced by \?.
+//
+// syntax: \?
+type HelpResult struct {
+	Groups []CommandGroup
+}
+
+func (HelpResult) ResultKind() SpecialResultKind {
+	return ResultKindHelp
+}
+
+// SQLHelpTopic describes the syntax of a single SQL command.
+// This is not used in any return types directly, but is embedded in
+// SQLHelpResult.
+type SQLHelpTopic struct {
+	Name        string // command name, e.g. "CREATE TABLE"
+	Description string // one-line summary
+	Synopsis    string // syntax synopsis as printed by psql's \h
+	URL         string // reference page in the PostgreSQL documentation
+}
+
+// SQLHelpResult holds the SQL commands matching a \h topic. Version is the
+// PostgreSQL major version the synopses were taken from.
+//
+// syntax: \h [command]
+type SQLHelpResult struct {
+	Version string
+	Topics  []SQLHelpTopic
+}
+
+func (SQLHelpResult) ResultKind() SpecialResultKind {
+	return ResultKindSQLHelp
+}
+
+// VariablesResult holds the session variables listed by \set without
+// arguments, sorted by name.
+//
+// syntax: \set
+type VariablesResult struct {
+	Variables []Variable
+}
+
+func (VariablesResult) ResultKind() SpecialResultKind {
+	return ResultKindVariables
+}
+
+// QueryResult holds the complete result of an SQL statement run from a
+// script. Unlike RowResult the rows have already been read, so the
+// connection is free for the next statement.
+type QueryResult struct {
+	Columns    []string
+	Rows       [][]any
+	CommandTag string // e.g. "SELECT 2" or "INSERT 0 1"
+	Expanded   bool   // as in RowResult
+	Output     string // as in RowResult
+}
+
+func (QueryResult) ResultKind() SpecialResultKind {
+	return ResultKindQuery
+}
+
+// ScriptResults holds the outcome of every statement of a script run by \i
+// or \ir. ExecuteScript merges them into its own results.
+//
+// syntax: \i file
+type ScriptResults struct {
+	Results []ScriptResult
+}
+
+func (ScriptResults) ResultKind() SpecialResultKind {
+	return ResultKindScript
+}
+
+// QueryBufferResult holds the text of the query buffer printed by \p or
+// loaded by \e, \ef or \ev. An empty Text means the buffer is empty.
+//
+// syntax: \p
+type QueryBufferResult struct {
+	Text string
+}
+
+func (QueryBufferResult) ResultKind() SpecialResultKind {
+	return ResultKindQueryBuffer
+}
+
+// TextLine is a line of a TextResult. Number is the line number shown
+// before it, or 0 if the line is not numbered.
+type TextLine struct {
+	Number int
+	Text   string
+}
+
+// TextResult holds text produced by a command, such as the function and view
+// definitions shown by \sf and \sv. The lines are numbered by the verbose
+// form of the command.
+//
+// syntax: \sf[+] function
+type TextResult struct {
+	Lines []TextLine
+}
+
+// NewTextResult splits text into the unnumbered lines of a TextResult,
+// ignoring a trailing newline.
+func NewTextResult(text string) TextResult {
+	var r TextResult
+	if text == "" {
+		return r
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(text, "\n"), "\n") {
+		r.Lines = append(r.Lines, TextLine{Text: line})
+	}
+	return r
+}
+
+func (TextResult) ResultKind() SpecialResultKind {
+	return ResultKindText
+}
+
+// String returns the text as psql prints it, each line ending with a
+// newline. If any line is numbered, every line is indented by eight columns
+// holding its number.
+func (r TextResult) String() string {
+	numbered := false
+	for _, line := range r.Lines {
+		numbered = numbered || line.Number > 0
+	}
+
+	var sb strings.Builder
+	for _, line := range r.Lines {
+		switch {
+		case line.Number > 0:
+			fmt.Fprintf(&sb, "%-7d ", line.Number)
+		case numbered:
+			sb.WriteString("        ")
+		}
+		sb.WriteString(line.Text)
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// StatusResult holds the one-line message psql prints to confirm a
+// command, such as "Query buffer reset (cleared)." after \r.
+//
+// syntax: \r
+type StatusResult struct {
+	Message string
+}
+
+func (StatusResult) ResultKind() SpecialResultKind {
+	return ResultKindStatus
+}
+
+// ShellResult holds the outcome of a shell command run by \!. Output is
+// what the command wrote to the streams that were captured rather than
+// attached, see ShellStreams. A non-zero ExitCode is not an error, as in
+// psql.
+//
+// syntax: \! [command]
+type ShellResult struct {
+	Output   string
+	ExitCode int
+}
+
+func (ShellResult) ResultKind() SpecialResultKind {
+	return ResultKindShell
+}
+
+// EchoHiddenResult holds the queries a command issued while ECHO_HIDDEN was
+// on, in order, and the result of the command. In noexec mode Result is nil
+// and Queries holds the first query, which was not executed.
+type EchoHiddenResult struct {
+	Queries []HiddenQuery
+	Result  SpecialCommandResult
+}
+
+func (EchoHiddenResult) ResultKind() SpecialResultKind {
+	return ResultKindEchoHidden
+}
+
 // ExtensionVerboseResult holds the result of a single extension verbose command.
 // This is not used in any return types directly, but is embedded in
 // ExtensionVerboseListResult.
 type ExtensionVerboseResult struct {
-	Name        string
-	Description []string
+	Name        string   `json:"name"`
+	Description []string `json:"description"`
 }
 
 
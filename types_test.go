@@ -0,0 +1,28 @@
+package pgxspecial_test
+
+import (
+	"testing"
+
+	"github.com/balaji01-4d/pgxspecial"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTextResult(t *testing.T) {
+	res := pgxspecial.NewTextResult("CREATE VIEW v AS\n SELECT 1;\n")
+	assert.Equal(t, []pgxspecial.TextLine{{Text: "CREATE VIEW v AS"}, {Text: " SELECT 1;"}}, res.Lines)
+	assert.Equal(t, "CREATE VIEW v AS\n SELECT 1;\n", res.String())
+
+	// unnumbered lines are indented once any line is numbered
+	res.Lines[1].Number = 1
+	assert.Equal(t, "        CREATE VIEW v AS\n1        SELECT 1;\n", res.String())
+
+	assert.Empty(t, pgxspecial.NewTextResult("").Lines)
+	assert.Equal(t, "", pgxspecial.TextResult{}.String())
+}
+
+func TestSpecialResultKindString(t *testing.T) {
+	assert.Equal(t, "rows", pgxspecial.RowResult{}.ResultKind().String())
+	assert.Equal(t, "describe_table", pgxspecial.DescribeTableListResult{}.ResultKind().String())
+	assert.Equal(t, "echo_hidden", pgxspecial.EchoHiddenResult{}.ResultKind().String())
+	assert.Equal(t, "SpecialResultKind(-1)", pgxspecial.SpecialResultKind(-1).String())
+}
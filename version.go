@@ -0,0 +1,77 @@
+package pgxspecial
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"weak"
+
+	"github.com/balaji01-4d/pgxspecial/database"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// serverVersionQuery reads the version of the server as a number, such as
+// 170002 for PostgreSQL 17.2.
+const serverVersionQuery = "SELECT pg_catalog.current_setting('server_version_num')::pg_catalog.int4"
+
+// serverVersions caches the versions read by ServerVersion, keyed by weak
+// pointers to the connections so that closing and dropping a connection
+// also drops its entry.
+var serverVersions sync.Map
+
+// ServerVersion returns the server_version_num of the server db is connected
+// to, such as 170002 for PostgreSQL 17.2. Commands use it to pick the
+// catalog queries the server understands, as psql does.
+//
+// The version is read once per connection and cached for a *pgx.Conn, a
+// *pgxpool.Pool, and the Queryers wrapping a connection that return it from
+// a Conn method, like pgx.Tx and *pgxpool.Conn. Other Queryers are asked
+// every time. The query is never recorded by ECHO_HIDDEN, as psql never
+// shows it either.
+func ServerVersion(ctx context.Context, db database.Queryer) (int, error) {
+	db = UnwrapQueryer(db)
+	key, track := serverVersionKey(db)
+	if key != nil {
+		if version, ok := serverVersions.Load(key); ok {
+			return version.(int), nil
+		}
+	}
+
+	var version int
+	if err := db.QueryRow(ctx, serverVersionQuery).Scan(&version); err != nil {
+		return 0, err
+	}
+	if key != nil {
+		if _, loaded := serverVersions.LoadOrStore(key, version); !loaded {
+			track()
+		}
+	}
+	return version, nil
+}
+
+// serverVersionKey returns the key caching the server version of db, or nil
+// if it is not cached, and a function removing the entry once the
+// connection is garbage collected.
+func serverVersionKey(db database.Queryer) (any, func()) {
+	switch db := db.(type) {
+	case *pgx.Conn:
+		return weakVersionKey(db)
+	case *pgxpool.Pool:
+		return weakVersionKey(db)
+	case interface{ Conn() *pgx.Conn }:
+		if conn := db.Conn(); conn != nil {
+			return weakVersionKey(conn)
+		}
+	}
+	return nil, nil
+}
+
+func weakVersionKey[T any](p *T) (any, func()) {
+	key := weak.Make(p)
+	return key, func() {
+		runtime.AddCleanup(p, func(key weak.Pointer[T]) {
+			serverVersions.Delete(key)
+		}, key)
+	}
+}
@@ -0,0 +1,98 @@
+package pgxspecial_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/balaji01-4d/pgxspecial"
+	"github.com/balaji01-4d/pgxspecial/database"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// versionQueryer answers the server version query with version and counts
+// how often it was asked.
+type versionQueryer struct {
+	version int
+	conn    *pgx.Conn
+	asked   int
+}
+
+func (q *versionQueryer) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	panic("unexpected query: " + sql)
+}
+
+func (q *versionQueryer) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	q.asked++
+	return intRow(q.version)
+}
+
+type intRow int
+
+func (r intRow) Scan(dest ...any) error {
+	*dest[0].(*int) = int(r)
+	return nil
+}
+
+// txQueryer is a versionQueryer wrapping a connection.
+type txQueryer struct {
+	*versionQueryer
+}
+
+func (q txQueryer) Conn() *pgx.Conn {
+	return q.conn
+}
+
+func TestServerVersion(t *testing.T) {
+	ctx := context.Background()
+
+	// Queryers of unknown connections are asked every time
+	db := &versionQueryer{version: 170002}
+	for range 2 {
+		version, err := pgxspecial.ServerVersion(ctx, db)
+		require.NoError(t, err)
+		assert.Equal(t, 170002, version)
+	}
+	assert.Equal(t, 2, db.asked)
+
+	// the version is cached per connection, whatever wraps it
+	conn := &pgx.Conn{}
+	first := txQueryer{&versionQueryer{version: 160004, conn: conn}}
+	second := txQueryer{&versionQueryer{version: 100000, conn: conn}}
+	for _, db := range []txQueryer{first, second, first} {
+		version, err := pgxspecial.ServerVersion(ctx, db)
+		require.NoError(t, err)
+		assert.Equal(t, 160004, version)
+	}
+	assert.Equal(t, 1, first.asked)
+	assert.Equal(t, 0, second.asked)
+
+	other := txQueryer{&versionQueryer{version: 180000, conn: &pgx.Conn{}}}
+	version, err := pgxspecial.ServerVersion(ctx, other)
+	require.NoError(t, err)
+	assert.Equal(t, 180000, version)
+}
+
+func TestServerVersionEchoHidden(t *testing.T) {
+	reg := pgxspecial.NewRegistry()
+	require.NoError(t, reg.Register(pgxspecial.SpecialCommandRegistry{
+		Cmd: "\\version",
+		Handler: func(ctx context.Context, db database.Queryer, args string, opts pgxspecial.CommandOptions) (pgxspecial.SpecialCommandResult, error) {
+			version, err := pgxspecial.ServerVersion(ctx, db)
+			if err != nil {
+				return nil, err
+			}
+			return pgxspecial.StatusResult{Message: strconv.Itoa(version)}, nil
+		},
+	}))
+
+	// psql does not show how it learns the version, nor does noexec
+	// prevent it
+	db := &versionQueryer{version: 150000}
+	ctx := pgxspecial.WithEchoHidden(context.Background(), pgxspecial.EchoHiddenNoExec)
+	res, _, err := reg.Execute(ctx, db, "\\version")
+	require.NoError(t, err)
+	assert.Equal(t, pgxspecial.StatusResult{Message: "150000"}, res)
+}